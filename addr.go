@@ -0,0 +1,95 @@
+package cfg
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// HostPort is a network address of the form `host:port`, such as
+// `localhost:8080`, `:8080` or `[::1]:8080`. The host may be empty.
+type HostPort struct {
+	Host string
+	Port int
+}
+
+// UnmarshalText splits and validates an address of the form `host:port`.
+func (hp *HostPort) UnmarshalText(text []byte) error {
+	host, port, err := net.SplitHostPort(strings.TrimSpace(string(text)))
+	if err != nil {
+		return fmt.Errorf("invalid address %q: %w", text, err)
+	}
+
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return fmt.Errorf("invalid address %q: invalid port %q", text, port)
+	}
+
+	hp.Host, hp.Port = host, int(p)
+	return nil
+}
+
+// MarshalText formats the address in the form `host:port`.
+func (hp HostPort) MarshalText() ([]byte, error) {
+	return []byte(hp.String()), nil
+}
+
+// String formats the address in the form `host:port`, enclosing IPv6 hosts
+// in square brackets.
+func (hp HostPort) String() string {
+	return net.JoinHostPort(hp.Host, strconv.Itoa(hp.Port))
+}
+
+// ListenAddr is an address that a server listens on. It is either a TCP
+// address of the form `host:port` (see HostPort) or the path of a unix
+// socket, written as `unix:/run/app.sock`, `unix:///run/app.sock` or simply as a path containing
+// a slash such as `/run/app.sock`.
+type ListenAddr struct {
+	Network string // "tcp" or "unix".
+	Address string // host:port for tcp, the socket path for unix.
+}
+
+// UnmarshalText parses and validates a listen address.
+func (la *ListenAddr) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+
+	if path, ok := strings.CutPrefix(s, "unix:"); ok || strings.Contains(s, "/") {
+		if ok {
+			path = strings.TrimPrefix(path, "//")
+		} else {
+			path = s
+		}
+		if path == "" {
+			return fmt.Errorf("invalid listen address %q: empty socket path", text)
+		}
+		la.Network, la.Address = "unix", path
+		return nil
+	}
+
+	var hp HostPort
+	if err := hp.UnmarshalText([]byte(s)); err != nil {
+		return fmt.Errorf("invalid listen address %q: %w", text, err)
+	}
+	la.Network, la.Address = "tcp", hp.String()
+	return nil
+}
+
+// MarshalText formats the listen address.
+func (la ListenAddr) MarshalText() ([]byte, error) {
+	return []byte(la.String()), nil
+}
+
+// String formats the listen address, prefixing unix socket paths with
+// `unix:`.
+func (la ListenAddr) String() string {
+	if la.Network == "unix" {
+		return "unix:" + la.Address
+	}
+	return la.Address
+}
+
+// Listen announces on the listen address.
+func (la ListenAddr) Listen() (net.Listener, error) {
+	return net.Listen(la.Network, la.Address)
+}
@@ -0,0 +1,119 @@
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_cfg_Load_Addr(t *testing.T) {
+	type Config struct {
+		Upstream HostPort   `cfg:"upstream"`
+		Peers    []HostPort `cfg:"peers"`
+		Listen   ListenAddr `cfg:"listen" default:":8080"`
+		Admin    ListenAddr `cfg:"admin"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), `
+upstream: "db.internal:5432"
+peers: ["[::1]:7000", "10.0.0.2:7000"]
+`)
+
+	os.Clearenv()
+	setenv(t, "APP_ADMIN", "unix:/run/admin.sock")
+
+	var cfg Config
+	if err := Load(&cfg, Dirs(dir), UseEnv("app")); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := Config{
+		Upstream: HostPort{Host: "db.internal", Port: 5432},
+		Peers:    []HostPort{{Host: "::1", Port: 7000}, {Host: "10.0.0.2", Port: 7000}},
+		Listen:   ListenAddr{Network: "tcp", Address: ":8080"},
+		Admin:    ListenAddr{Network: "unix", Address: "/run/admin.sock"},
+	}
+	if !reflect.DeepEqual(want, cfg) {
+		t.Errorf("\nwant %+v\ngot %+v", want, cfg)
+	}
+}
+
+func TestHostPort(t *testing.T) {
+	for _, tc := range []struct {
+		In   string
+		Want HostPort
+	}{
+		{In: "localhost:80", Want: HostPort{Host: "localhost", Port: 80}},
+		{In: ":8080", Want: HostPort{Port: 8080}},
+		{In: "[fe80::1]:443", Want: HostPort{Host: "fe80::1", Port: 443}},
+	} {
+		t.Run(tc.In, func(t *testing.T) {
+			var hp HostPort
+			if err := hp.UnmarshalText([]byte(tc.In)); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if hp != tc.Want {
+				t.Errorf("want %+v, got %+v", tc.Want, hp)
+			}
+			if hp.String() != tc.In {
+				t.Errorf("want %s, got %s", tc.In, hp.String())
+			}
+		})
+	}
+
+	for _, in := range []string{"", "localhost", "localhost:http", "host:70000", "::1:80"} {
+		t.Run("invalid "+in, func(t *testing.T) {
+			var hp HostPort
+			if err := hp.UnmarshalText([]byte(in)); err == nil {
+				t.Fatal("expected err")
+			}
+		})
+	}
+}
+
+func TestListenAddr(t *testing.T) {
+	for _, tc := range []struct {
+		In     string
+		Want   ListenAddr
+		String string
+	}{
+		{In: ":8080", Want: ListenAddr{Network: "tcp", Address: ":8080"}, String: ":8080"},
+		{In: "[::]:80", Want: ListenAddr{Network: "tcp", Address: "[::]:80"}, String: "[::]:80"},
+		{In: "/run/app.sock", Want: ListenAddr{Network: "unix", Address: "/run/app.sock"}, String: "unix:/run/app.sock"},
+		{In: "unix:app.sock", Want: ListenAddr{Network: "unix", Address: "app.sock"}, String: "unix:app.sock"},
+		{In: "unix:///run/app.sock", Want: ListenAddr{Network: "unix", Address: "/run/app.sock"}, String: "unix:/run/app.sock"},
+	} {
+		t.Run(tc.In, func(t *testing.T) {
+			var la ListenAddr
+			if err := la.UnmarshalText([]byte(tc.In)); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if la != tc.Want {
+				t.Errorf("want %+v, got %+v", tc.Want, la)
+			}
+			if la.String() != tc.String {
+				t.Errorf("want %s, got %s", tc.String, la.String())
+			}
+		})
+	}
+
+	for _, in := range []string{"", "unix:", "localhost"} {
+		t.Run("invalid "+in, func(t *testing.T) {
+			var la ListenAddr
+			if err := la.UnmarshalText([]byte(in)); err == nil {
+				t.Fatal("expected err")
+			}
+		})
+	}
+
+	t.Run("listen", func(t *testing.T) {
+		la := ListenAddr{Network: "tcp", Address: "127.0.0.1:0"}
+		l, err := la.Listen()
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		l.Close()
+	})
+}
@@ -0,0 +1,237 @@
+package cfg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// AdminHandler returns an http.Handler that applies the overrides of PATCH
+// or POST requests to cfg, a *Live holding a loaded config, e.g. to tune a
+// running service without a redeploy. options must be those the config was
+// loaded with.
+//
+// The body of a request is a JSON array of the operations of a patch, see
+// ApplyPatch:
+//
+//	[{"op": "replace", "path": "server.workers", "value": 16}]
+//
+// The paths of operations must name fields of cfg that are not tagged
+// `secret:"true"` and hold no such fields, since setting a struct, slice or
+// map sets its fields too. The body is limited to MaxFileSize, or 1 MiB if
+// unset. The patch is applied with ApplyPatch and, if store is
+// not nil, the fields that changed are then written to it so that the
+// overrides survive restarts. The response is a JSON object listing the
+// changes, e.g. `{"changes":[{"path":"server.workers"}]}`.
+//
+// Requests are served one at a time, along with the reloads of the Live,
+// and patches are committed under its lock so that its readers never see
+// them half applied. cfg may also be a plain pointer to a config struct,
+// which the handler then sets while the service may be reading it: that is
+// a data race unless the service synchronizes its reads with the handler.
+//
+// Invalid patches are rejected with 400 Bad Request, and failed tests and
+// conflicting writes with 409 Conflict. Error responses name the operations
+// and fields at fault but never their values.
+// cfg is left untouched by rejected patches, but if the write to store
+// fails the changes are live but not persisted. The handler does not
+// authenticate requests, so it must be mounted behind the middleware of
+// the service or on an internal listener.
+func AdminHandler(cfg interface{}, store WritableSource, options ...Option) http.Handler {
+	conf := defaultCfg()
+	for _, opt := range options {
+		opt(conf)
+	}
+
+	mu := new(sync.Mutex)
+	if l, ok := cfg.(liveConfig); ok {
+		var commitMu *sync.RWMutex
+		cfg, mu, commitMu = l.live()
+		options = withLiveLocks(options, mu, commitMu)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch && r.Method != http.MethodPost {
+			w.Header().Set("Allow", "PATCH, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		limit := conf.maxFileSize
+		if limit <= 0 {
+			limit = adminMaxBodySize
+		}
+		body := http.MaxBytesReader(w, r.Body, limit)
+		var patch []Op
+		if err := json.NewDecoder(body).Decode(&patch); err != nil {
+			code := http.StatusBadRequest
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				code = http.StatusRequestEntityTooLarge
+			}
+			http.Error(w, fmt.Sprintf("invalid patch: %v", err), code)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		for i, op := range patch {
+			if err := conf.checkAdminPath(cfg, op.Path); err != nil {
+				http.Error(w, fmt.Sprintf("patch op %d (%s %s): %v", i, op.Op, op.Path, err), http.StatusBadRequest)
+				return
+			}
+		}
+
+		changes, err := applyPatch(cfg, patch, options...)
+		if err != nil {
+			http.Error(w, adminMessage(err), adminStatus(err, http.StatusBadRequest))
+			return
+		}
+		if store != nil {
+			if err := conf.persist(r.Context(), cfg, store, changes); err != nil {
+				msg := "changes applied but not persisted"
+				if errors.Is(err, ErrConflict) {
+					msg += ": " + ErrConflict.Error()
+				}
+				http.Error(w, msg, adminStatus(err, http.StatusBadGateway))
+				return
+			}
+		}
+
+		type change struct {
+			Path            string `json:"path"`
+			RestartRequired bool   `json:"restart_required,omitempty"`
+		}
+		resp := struct {
+			Changes []change `json:"changes"`
+		}{Changes: make([]change, 0, len(changes))}
+		for _, c := range changes {
+			resp.Changes = append(resp.Changes, change{Path: c.Path, RestartRequired: c.RestartRequired})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+}
+
+// adminMaxBodySize is the max size of the body of a request of AdminHandler
+// unless MaxFileSize is set.
+const adminMaxBodySize = 1 << 20
+
+// checkAdminPath returns an error unless path, a patch path, is within a
+// field of cfg that is neither secret nor holds secret fields, as setting
+// a parent overwrites its fields.
+func (f *cfg) checkAdminPath(cfg interface{}, path string) error {
+	if i := strings.Index(path, "["); i >= 0 {
+		path = path[:i]
+	}
+	// the keys of maps are not fields: the innermost field that contains
+	// the path is checked.
+	for p := path; p != ""; {
+		if field := lookupField(cfg, p, f.tag); field != nil {
+			if isSecretField(field) || f.hasSecretFields(field.t, make(map[reflect.Type]bool)) {
+				return fmt.Errorf("secret fields cannot be overridden")
+			}
+			return nil
+		}
+		i := strings.LastIndex(p, ".")
+		if i < 0 {
+			break
+		}
+		p = p[:i]
+	}
+	return fmt.Errorf("unknown field")
+}
+
+// hasSecretFields reports whether values of type t hold fields tagged
+// `secret:"true"`, including the fields of the elements of slices and maps,
+// which may not exist yet. seen holds the struct types being visited.
+func (f *cfg) hasSecretFields(t reflect.Type, seen map[reflect.Type]bool) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return f.hasSecretFields(t.Elem(), seen)
+	case reflect.Struct:
+		if seen[t] {
+			return false
+		}
+		seen[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if sf.PkgPath != "" {
+				continue
+			}
+			if parseTag(sf.Tag, f.tag).secret || f.hasSecretFields(sf.Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// adminMessage returns the message of the response to a request of
+// AdminHandler that failed with err. It names the operations and fields at
+// fault but none of their values, which may be secrets.
+func adminMessage(err error) string {
+	var opErr *opError
+	var errs fieldErrors
+	switch {
+	case errors.As(err, &opErr):
+		reason := "invalid operation"
+		if errors.Is(err, ErrTestFailed) {
+			reason = ErrTestFailed.Error()
+		}
+		return fmt.Sprintf("patch op %d (%s %s): %s", opErr.index, opErr.op.Op, opErr.op.Path, reason)
+	case errors.As(err, &errs):
+		paths := make([]string, 0, len(errs))
+		for path := range errs {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		return "invalid config: " + strings.Join(paths, ", ")
+	case errors.Is(err, ErrRestartRequired):
+		// the error lists the paths of the fields that changed.
+		return err.Error()
+	default:
+		return "invalid patch"
+	}
+}
+
+// persist writes the values of the fields of cfg that changed to store.
+// The elements of slices are written along with their slice.
+func (f *cfg) persist(ctx context.Context, cfg interface{}, store WritableSource, changes []Change) error {
+	done := make(map[string]bool)
+	for _, c := range changes {
+		p := c.Path
+		if i := strings.Index(p, "["); i >= 0 {
+			p = p[:i]
+		}
+		if done[p] {
+			continue
+		}
+		done[p] = true
+
+		field := lookupField(cfg, p, f.tag)
+		if field == nil {
+			continue
+		}
+		if err := store.Put(ctx, p, f.plainValue(field.v)); err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// adminStatus returns the status code of the response to a request of
+// AdminHandler that failed with err, or code if err is not a conflict.
+func adminStatus(err error, code int) int {
+	if errors.Is(err, ErrConflict) || errors.Is(err, ErrTestFailed) {
+		return http.StatusConflict
+	}
+	return code
+}
@@ -0,0 +1,197 @@
+package cfg
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// putSource is a WritableSource that records the values put to it.
+type putSource struct {
+	MapSource
+	err error
+}
+
+func (s *putSource) Put(_ context.Context, path string, value interface{}) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.MapSource[path] = value
+	return nil
+}
+
+func Test_AdminHandler(t *testing.T) {
+	type Config struct {
+		Workers  int               `cfg:"workers" validate:"required"`
+		Hosts    []string          `cfg:"hosts"`
+		Labels   map[string]string `cfg:"labels"`
+		Password string            `cfg:"password" secret:"true"`
+		DB       struct {
+			Host     string `cfg:"host"`
+			Password string `cfg:"password" secret:"true"`
+		} `cfg:"db"`
+		Users []struct {
+			Name  string `cfg:"name"`
+			Token string `cfg:"token" secret:"true"`
+		} `cfg:"users"`
+	}
+
+	serve := func(h http.Handler, method, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, "/admin/config", strings.NewReader(body)))
+		return rec
+	}
+
+	t.Run("applies and persists", func(t *testing.T) {
+		cfg := Config{Workers: 4, Hosts: []string{"a"}, Labels: map[string]string{}}
+		store := &putSource{MapSource: MapSource{}}
+		h := AdminHandler(&cfg, store)
+
+		rec := serve(h, "PATCH", `[
+			{"op": "replace", "path": "workers", "value": 8},
+			{"op": "add", "path": "hosts[1]", "value": "b"},
+			{"op": "add", "path": "labels.team", "value": "core"}
+		]`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("want 200, got %d: %s", rec.Code, rec.Body)
+		}
+		if want := `{"changes":[{"path":"hosts"},{"path":"labels"},{"path":"workers"}]}`; strings.TrimSpace(rec.Body.String()) != want {
+			t.Errorf("want %s, got %s", want, rec.Body)
+		}
+
+		want := Config{Workers: 8, Hosts: []string{"a", "b"}, Labels: map[string]string{"team": "core"}}
+		if !reflect.DeepEqual(want, cfg) {
+			t.Errorf("want %+v, got %+v", want, cfg)
+		}
+		wantStored := MapSource{
+			"workers": 8,
+			"hosts":   []interface{}{"a", "b"},
+			"labels":  map[string]interface{}{"team": "core"},
+		}
+		if !reflect.DeepEqual(wantStored, store.MapSource) {
+			t.Errorf("want stored %v, got %v", wantStored, store.MapSource)
+		}
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		for _, tc := range []struct {
+			name, method, body string
+			code               int
+		}{
+			{"method", "GET", "", http.StatusMethodNotAllowed},
+			{"body", "PATCH", `{"op": "replace"}`, http.StatusBadRequest},
+			{"unknown field", "PATCH", `[{"op": "replace", "path": "workrs", "value": 8}]`, http.StatusBadRequest},
+			{"secret", "PATCH", `[{"op": "replace", "path": "password", "value": "x"}]`, http.StatusBadRequest},
+			{"invalid", "PATCH", `[{"op": "replace", "path": "workers", "value": 0}]`, http.StatusBadRequest},
+			{"test", "POST", `[{"op": "test", "path": "workers", "value": 5}]`, http.StatusConflict},
+			{"parent of secret", "PATCH", `[{"op": "replace", "path": "db", "value": {"host": "h"}}]`, http.StatusBadRequest},
+			{"remove parent of secret", "PATCH", `[{"op": "remove", "path": "db"}]`, http.StatusBadRequest},
+			{"test parent of secret", "PATCH", `[{"op": "test", "path": "db", "value": {}}]`, http.StatusBadRequest},
+			{"element with secret", "PATCH", `[{"op": "add", "path": "users[0]", "value": {"token": "x"}}]`, http.StatusBadRequest},
+			{"too large", "PATCH", "[" + strings.Repeat(" ", adminMaxBodySize) + "]", http.StatusRequestEntityTooLarge},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				cfg := Config{Workers: 4, Password: "hunter2"}
+				cfg.DB.Password = "s3cret"
+				want := cfg
+				h := AdminHandler(&cfg, nil)
+
+				rec := serve(h, tc.method, tc.body)
+				if rec.Code != tc.code {
+					t.Errorf("want %d, got %d: %s", tc.code, rec.Code, rec.Body)
+				}
+				if body := rec.Body.String(); strings.Contains(body, "hunter2") || strings.Contains(body, "s3cret") {
+					t.Errorf("want no secret in body, got %s", body)
+				}
+				if !reflect.DeepEqual(want, cfg) {
+					t.Errorf("want cfg untouched, got %+v", cfg)
+				}
+			})
+		}
+	})
+
+	t.Run("no values in errors", func(t *testing.T) {
+		cfg := Config{Workers: 4}
+		h := AdminHandler(&cfg, nil)
+
+		rec := serve(h, "PATCH", `[{"op": "test", "path": "workers", "value": 5}]`)
+		if want := "patch op 0 (test workers): " + ErrTestFailed.Error() + "\n"; rec.Body.String() != want {
+			t.Errorf("want %q, got %q", want, rec.Body)
+		}
+		rec = serve(h, "PATCH", `[{"op": "replace", "path": "workers", "value": 0}]`)
+		if want := "invalid config: workers\n"; rec.Body.String() != want {
+			t.Errorf("want %q, got %q", want, rec.Body)
+		}
+	})
+
+	t.Run("store conflict", func(t *testing.T) {
+		cfg := Config{Workers: 4}
+		store := &putSource{MapSource: MapSource{}, err: fmt.Errorf("etcd: %w", ErrConflict)}
+
+		rec := serve(AdminHandler(&cfg, store), "PATCH", `[{"op": "replace", "path": "workers", "value": 8}]`)
+		if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "not persisted") {
+			t.Errorf("want 409 not persisted, got %d: %s", rec.Code, rec.Body)
+		}
+	})
+}
+
+func Test_AdminHandler_Live(t *testing.T) {
+	type Config struct {
+		Workers int      `cfg:"workers"`
+		Hosts   []string `cfg:"hosts"`
+	}
+
+	live := NewLive(&Config{Workers: 1, Hosts: []string{"a"}})
+	h := AdminHandler(live, nil)
+
+	// readers run along with the patches: go test -race reports unsynchronized
+	// commits.
+	done := make(chan struct{})
+	read := make(chan struct{})
+	go func() {
+		defer close(read)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if c := live.Get(); len(c.Hosts) != c.Workers {
+				t.Errorf("want consistent config, got %+v", c)
+				return
+			}
+		}
+	}()
+
+	for i := 2; i <= 50; i++ {
+		body := fmt.Sprintf(`[{"op": "replace", "path": "workers", "value": %d}, {"op": "add", "path": "hosts[%d]", "value": "h"}]`, i, i-1)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("PATCH", "/admin/config", strings.NewReader(body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("want 200, got %d: %s", rec.Code, rec.Body)
+		}
+	}
+	close(done)
+	<-read
+
+	if c := live.Get(); c.Workers != 50 || len(c.Hosts) != 50 {
+		t.Errorf("want 50 workers and hosts, got %+v", c)
+	}
+
+	t.Run("apply patch", func(t *testing.T) {
+		changes, err := ApplyPatch(live, []Op{{Op: OpReplace, Path: "workers", Value: 4}})
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if want := []Change{{Path: "workers"}}; !reflect.DeepEqual(want, changes) {
+			t.Errorf("want %+v, got %+v", want, changes)
+		}
+		if c := live.Get(); c.Workers != 4 {
+			t.Errorf("want 4 workers, got %+v", c)
+		}
+	})
+}
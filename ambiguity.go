@@ -0,0 +1,60 @@
+package cfg
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// AmbiguityMode is how Load treats config files that are ambiguous, see
+// DetectAmbiguity.
+type AmbiguityMode string
+
+const (
+	// AmbiguityWarn loads ambiguous files and raises a warning listing
+	// them, reported by LoadResult.
+	AmbiguityWarn AmbiguityMode = "warn"
+	// AmbiguityError fails Load with an error wrapping ErrAmbiguousFiles.
+	AmbiguityError AmbiguityMode = "error"
+)
+
+// ambiguousFiles returns the groups of paths that are copies or variants
+// of the same config file: paths whose names are equal once their
+// extension is removed, e.g. `config.yaml` and `config.yml` in one dir or
+// `config.yaml` in two dirs. Groups are in the order of their first path.
+func ambiguousFiles(paths []string) [][]string {
+	var stems []string
+	groups := make(map[string][]string)
+	for _, p := range paths {
+		base := filepath.Base(p)
+		stem := strings.ToLower(strings.TrimSuffix(base, filepath.Ext(base)))
+		if _, ok := groups[stem]; !ok {
+			stems = append(stems, stem)
+		}
+		groups[stem] = append(groups[stem], p)
+	}
+
+	var ambiguous [][]string
+	for _, stem := range stems {
+		if len(groups[stem]) > 1 {
+			ambiguous = append(ambiguous, groups[stem])
+		}
+	}
+	return ambiguous
+}
+
+// checkAmbiguity warns about or returns an error for the ambiguous files
+// of paths as configured by the DetectAmbiguity option.
+func (f *cfg) checkAmbiguity(paths []string) error {
+	if f.ambiguity == "" {
+		return nil
+	}
+	for _, group := range ambiguousFiles(paths) {
+		list := strings.Join(group, ", ")
+		if f.ambiguity == AmbiguityError {
+			return fmt.Errorf("%w: %s", ErrAmbiguousFiles, list)
+		}
+		f.warnf("ambiguous config files, merged in order: %s", list)
+	}
+	return nil
+}
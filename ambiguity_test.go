@@ -0,0 +1,82 @@
+package cfg
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_ambiguousFiles(t *testing.T) {
+	paths := []string{
+		"/etc/app/config.yaml",
+		"/etc/app/config.yml",
+		"/etc/app/secret.yaml",
+		"/home/app/secret.json",
+		"/etc/app/conf.d/10-port.yaml",
+		"/etc/app/conf.d/20-level.yaml",
+	}
+	want := [][]string{
+		{"/etc/app/config.yaml", "/etc/app/config.yml"},
+		{"/etc/app/secret.yaml", "/home/app/secret.json"},
+	}
+	if got := ambiguousFiles(paths); !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func Test_cfg_Load_DetectAmbiguity(t *testing.T) {
+	type Config struct {
+		Host string `cfg:"host"`
+	}
+
+	dir, stale := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "host: new\n")
+	writeFile(t, filepath.Join(stale, "config.yaml"), "host: stale\n")
+
+	t.Run("error", func(t *testing.T) {
+		var cfg Config
+		err := Load(&cfg, Dirs(dir, stale), DetectAmbiguity(AmbiguityError))
+		if !errors.Is(err, ErrAmbiguousFiles) {
+			t.Fatalf("want ErrAmbiguousFiles, got %v", err)
+		}
+		for _, path := range []string{filepath.Join(dir, "config.yaml"), filepath.Join(stale, "config.yaml")} {
+			if !strings.Contains(err.Error(), path) {
+				t.Errorf("want %s in err, got %v", path, err)
+			}
+		}
+	})
+
+	t.Run("warn", func(t *testing.T) {
+		var cfg Config
+		res, err := LoadResult(&cfg, Dirs(dir, stale), DetectAmbiguity(AmbiguityWarn))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		want := []string{"ambiguous config files, merged in order: " + filepath.Join(dir, "config.yaml") + ", " + filepath.Join(stale, "config.yaml")}
+		if !reflect.DeepEqual(want, res.Warnings) {
+			t.Errorf("want warnings %v, got %v", want, res.Warnings)
+		}
+		if cfg.Host != "stale" {
+			t.Errorf("want host stale, got %q", cfg.Host)
+		}
+	})
+
+	t.Run("unambiguous", func(t *testing.T) {
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir), DetectAmbiguity(AmbiguityError)); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+
+	t.Run("extensions", func(t *testing.T) {
+		writeFile(t, filepath.Join(dir, "config.yml"), "host: yml\n")
+
+		var cfg Config
+		err := Load(&cfg, Files(Optional("config.yaml"), Optional("config.yml")), Dirs(dir), DetectAmbiguity(AmbiguityError))
+		if !errors.Is(err, ErrAmbiguousFiles) {
+			t.Fatalf("want ErrAmbiguousFiles, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,162 @@
+package cfg
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+// ArchiveSource is a source that reads config values from a file within a
+// tar or zip archive, e.g. a bundle of config and assets that is
+// distributed as one artifact.
+//
+//	&cfg.ArchiveSource{
+//	  Path: "https://releases.example.com/config-v42.tar.gz",
+//	  File: "config/app.yaml",
+//	}
+//
+// The archive is a zip file or else a tar file, which may be compressed
+// like config files are, e.g. `.tar.gz`. The file within it is decoded by
+// the decoder of its extension.
+//
+// The archive, as it is stored, must match the digests of ExpectSHA256, if
+// any, and both the archive and the file within it, once decompressed,
+// are subject to MaxFileSize.
+type ArchiveSource struct {
+	Path   string       // path or http(s) URL of the archive.
+	File   string       // path of the config file within the archive.
+	Client *http.Client // client used for URLs. Defaults to http.DefaultClient.
+	Proxy  string       // URL of the proxy of URLs. Defaults to that of the `HTTP_PROXY` env var.
+	TLS    *tls.Config  // TLS config of URLs, e.g. with a client certificate and CAs for mTLS.
+}
+
+// Read returns the values decoded from File.
+func (s *ArchiveSource) Read(ctx context.Context) (map[string]interface{}, error) {
+	max := contextMaxFileSize(ctx)
+	data, err := s.fetch(ctx, max)
+	if err != nil {
+		return nil, fmt.Errorf("archive: %w", err)
+	}
+	if err := checkDigest(data, s.Path, contextDigests(ctx)); err != nil {
+		return nil, fmt.Errorf("archive: %w", err)
+	}
+
+	file, err := archiveFile(data, s.File, max)
+	if err != nil {
+		return nil, fmt.Errorf("archive %s: %w", s.Path, err)
+	}
+
+	vals := make(map[string]interface{})
+	if err := decodeArchiveFile(vals, file, s.File, max); err != nil {
+		return nil, fmt.Errorf("archive %s: %s: %w", s.Path, s.File, err)
+	}
+	return vals, nil
+}
+
+// String returns the name of the source, e.g. in the Provenance of a
+// Result.
+func (s *ArchiveSource) String() string {
+	return "archive:" + s.Path + "!" + s.File
+}
+
+// fetch returns the contents of the archive at Path, which must be at most
+// max bytes if max is positive.
+func (s *ArchiveSource) fetch(ctx context.Context, max int64) ([]byte, error) {
+	if !strings.HasPrefix(s.Path, "http://") && !strings.HasPrefix(s.Path, "https://") {
+		file, err := os.Open(s.Path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return readLimited(file, max)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.Path, nil)
+	if err != nil {
+		return nil, err
+	}
+	client, err := sourceClient(ctx, s.Client, true, s.Proxy, s.TLS)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := doRequest(client, req, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// zipMagic are the bytes zip archives start with.
+var zipMagic = []byte("PK\x03\x04")
+
+// archiveFile returns the contents of the file name within the zip or
+// tar archive data, which must be at most max bytes if max is positive.
+func archiveFile(data []byte, name string, max int64) ([]byte, error) {
+	name = path.Clean(strings.TrimPrefix(name, "/"))
+
+	if bytes.HasPrefix(data, zipMagic) {
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+		for _, zf := range zr.File {
+			if path.Clean(zf.Name) != name {
+				continue
+			}
+			rc, err := zf.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			return readLimited(rc, max)
+		}
+		return nil, fmt.Errorf("%s: %w", name, ErrFileNotFound)
+	}
+
+	r, err := decompress(io.NopCloser(bytes.NewReader(data)), "")
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s: %w", name, ErrFileNotFound)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag == tar.TypeReg && path.Clean(hdr.Name) == name {
+			return readLimited(tr, max)
+		}
+	}
+}
+
+// decodeArchiveFile decodes the contents of the file name, decompressing
+// them first if needed. The decompressed contents must be at most max
+// bytes if max is positive.
+func decodeArchiveFile(vals map[string]interface{}, data []byte, name string, max int64) error {
+	decode, err := lookupDecoder(fileExt(name))
+	if err != nil {
+		return err
+	}
+	r, err := decompress(io.NopCloser(bytes.NewReader(data)), name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	contents, err := readLimited(r, max)
+	if err != nil {
+		return err
+	}
+	return decode(bytes.NewReader(contents), vals)
+}
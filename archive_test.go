@@ -0,0 +1,156 @@
+package cfg
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// tarGz returns a gzip compressed tar archive of files.
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	for name, content := range files {
+		hdr := &tar.Header{Name: name, Mode: 0o600, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// zipArchive returns a zip archive of files.
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func Test_ArchiveSource(t *testing.T) {
+	files := map[string]string{
+		"./config/app.yaml": "host: localhost\nport: 8080\n",
+		"assets/logo.svg":   "<svg/>",
+	}
+	want := map[string]interface{}{"host": "localhost", "port": 8080}
+
+	dir := t.TempDir()
+	tarPath := filepath.Join(dir, "bundle.tar.gz")
+	if err := os.WriteFile(tarPath, tarGz(t, files), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	zipPath := filepath.Join(dir, "bundle.zip")
+	if err := os.WriteFile(zipPath, zipArchive(t, files), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	t.Cleanup(srv.Close)
+
+	for name, path := range map[string]string{
+		"tar":     tarPath,
+		"zip":     zipPath,
+		"tar url": srv.URL + "/bundle.tar.gz",
+	} {
+		t.Run(name, func(t *testing.T) {
+			src := &ArchiveSource{Path: path, File: "config/app.yaml"}
+			got, err := src.Read(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if !reflect.DeepEqual(want, got) {
+				t.Errorf("want %v, got %v", want, got)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		for _, path := range []string{tarPath, zipPath} {
+			src := &ArchiveSource{Path: path, File: "config/missing.yaml"}
+			if _, err := src.Read(context.Background()); !errors.Is(err, ErrFileNotFound) {
+				t.Errorf("%s: want ErrFileNotFound, got %v", path, err)
+			}
+		}
+	})
+
+	t.Run("load", func(t *testing.T) {
+		type Config struct {
+			Host string `cfg:"host"`
+			Port int    `cfg:"port"`
+		}
+		var cfg Config
+		res, err := LoadResult(&cfg, IgnoreFile(), WithSources(&ArchiveSource{Path: zipPath, File: "config/app.yaml"}))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Host != "localhost" || cfg.Port != 8080 {
+			t.Errorf("unexpected cfg %+v", cfg)
+		}
+		if got, want := res.Provenance["host"], "archive:"+zipPath+"!config/app.yaml"; got != want {
+			t.Errorf("want origin %s, got %s", want, got)
+		}
+	})
+
+	t.Run("digest", func(t *testing.T) {
+		type Config struct {
+			Host string `cfg:"host"`
+		}
+		for _, path := range []string{tarPath, srv.URL + "/bundle.tar.gz"} {
+			src := &ArchiveSource{Path: path, File: "config/app.yaml"}
+
+			var cfg Config
+			if err := Load(&cfg, IgnoreFile(), WithSources(src), ExpectSHA256(sha256Of(t, tarPath))); err != nil {
+				t.Fatalf("%s: unexpected err: %v", path, err)
+			}
+			err := Load(&cfg, IgnoreFile(), WithSources(src), ExpectSHA256(sha256Of(t, zipPath)))
+			if !errors.Is(err, ErrDigestMismatch) {
+				t.Errorf("%s: want ErrDigestMismatch, got %v", path, err)
+			}
+		}
+	})
+
+	t.Run("max file size", func(t *testing.T) {
+		type Config struct {
+			Host string `cfg:"host"`
+		}
+		for _, path := range []string{tarPath, zipPath, srv.URL + "/bundle.tar.gz"} {
+			var cfg Config
+			err := Load(&cfg, IgnoreFile(), WithSources(&ArchiveSource{Path: path, File: "config/app.yaml"}), MaxFileSize(16))
+			if !errors.Is(err, ErrLimitExceeded) {
+				t.Errorf("%s: want ErrLimitExceeded, got %v", path, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,186 @@
+package cfg
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSSecretSource is a source that reads a JSON secret from AWS Secrets
+// Manager, e.g. to keep database credentials out of config files. The
+// secret's JSON object is decoded like a JSON config file.
+//
+// Requests are signed with the credentials of the source, or else those of
+// the `AWS_ACCESS_KEY_ID`, `AWS_SECRET_ACCESS_KEY` and `AWS_SESSION_TOKEN`
+// env vars.
+type AWSSecretSource struct {
+	SecretID string // name or ARN of the secret.
+	Region   string // region of the secret. Defaults to the `AWS_REGION` env var.
+
+	AccessKeyID     string // access key of the credentials.
+	SecretAccessKey string // secret key of the credentials.
+	SessionToken    string // session token of temporary credentials, if any.
+
+	Endpoint string       // URL of the Secrets Manager endpoint. Defaults to the endpoint of Region.
+	Client   *http.Client // client used for requests. Defaults to http.DefaultClient.
+	Proxy    string       // URL of the proxy of requests. Defaults to that of the `HTTPS_PROXY` env var.
+	TLS      *tls.Config  // TLS config of requests, e.g. with a client certificate and CAs for mTLS.
+}
+
+// Read returns the values of the secret.
+func (s *AWSSecretSource) Read(ctx context.Context) (map[string]interface{}, error) {
+	secret, err := s.getSecretValue(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("aws secret %q: %w", s.SecretID, err)
+	}
+
+	vals := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(secret), &vals); err != nil {
+		return nil, fmt.Errorf("aws secret %q: secret is not a JSON object: %w", s.SecretID, err)
+	}
+	return vals, nil
+}
+
+// String returns the name of the source, e.g. in the Provenance of a
+// Result.
+func (s *AWSSecretSource) String() string {
+	return "aws-secret:" + s.SecretID
+}
+
+// getSecretValue calls the GetSecretValue action and returns the secret's
+// string.
+func (s *AWSSecretSource) getSecretValue(ctx context.Context) (string, error) {
+	region := s.Region
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		return "", fmt.Errorf("region is required")
+	}
+
+	accessKey, secretKey, token := s.AccessKeyID, s.SecretAccessKey, s.SessionToken
+	if accessKey == "" {
+		accessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+		secretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		token = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if accessKey == "" || secretKey == "" {
+		return "", fmt.Errorf("credentials are required")
+	}
+
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = "https://secretsmanager." + region + ".amazonaws.com"
+	}
+
+	body, err := json.Marshal(map[string]string{"SecretId": s.SecretID})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	if token != "" {
+		req.Header.Set("X-Amz-Security-Token", token)
+	}
+
+	signAWSRequest(req, body, accessKey, secretKey, region, "secretsmanager", time.Now().UTC())
+
+	client, err := sourceClient(ctx, s.Client, s.Endpoint != "", s.Proxy, s.TLS)
+	if err != nil {
+		return "", err
+	}
+	var out struct {
+		SecretString *string `json:"SecretString"`
+	}
+	if err := doRequest(client, req, &out); err != nil {
+		return "", err
+	}
+	if out.SecretString == nil {
+		return "", fmt.Errorf("secret has no string value")
+	}
+	return *out.SecretString, nil
+}
+
+// signAWSRequest signs req with AWS Signature Version 4. The host, the
+// content type and all the `X-Amz-*` headers of req are signed.
+func signAWSRequest(req *http.Request, body []byte, accessKey, secretKey, region, service string, t time.Time) {
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	signedHeaders := []string{"host"}
+	for name := range req.Header {
+		if name = strings.ToLower(name); name == "content-type" || strings.HasPrefix(name, "x-amz-") {
+			signedHeaders = append(signedHeaders, name)
+		}
+	}
+	sort.Strings(signedHeaders)
+
+	var canonicalHeaders strings.Builder
+	for _, h := range signedHeaders {
+		val := req.Header.Get(h)
+		if h == "host" {
+			val = req.URL.Host
+		}
+		canonicalHeaders.WriteString(h + ":" + strings.TrimSpace(val) + "\n")
+	}
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, strings.Join(signedHeaders, ";"), signature))
+}
+
+// canonicalQuery returns the query string of q sorted by key and encoded
+// as required by Signature Version 4.
+func canonicalQuery(q url.Values) string {
+	return strings.ReplaceAll(q.Encode(), "+", "%20")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,104 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_signAWSRequest(t *testing.T) {
+	// example request of the AWS Signature Version 4 documentation.
+	req, err := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	signAWSRequest(req, nil, "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "us-east-1", "iam",
+		time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, " +
+		"SignedHeaders=content-type;host;x-amz-date, " +
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("\nwant %s\ngot  %s", want, got)
+	}
+}
+
+func Test_AWSSecretSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" ||
+			!strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") ||
+			r.Header.Get("X-Amz-Security-Token") != "token" {
+			http.Error(w, "unauthorized", http.StatusForbidden)
+			return
+		}
+		var req struct{ SecretId string }
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.SecretId != "prod/db" {
+			http.Error(w, `{"__type":"ResourceNotFoundException"}`, http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"SecretString": `{"db": {"user": "admin", "password": "hunter2"}}`,
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	setenv(t, "AWS_ACCESS_KEY_ID", "AKID")
+	setenv(t, "AWS_SECRET_ACCESS_KEY", "secret")
+	setenv(t, "AWS_SESSION_TOKEN", "token")
+	setenv(t, "AWS_REGION", "eu-west-1")
+
+	type Config struct {
+		DB struct {
+			Host     string `cfg:"host" default:"localhost"`
+			User     string `cfg:"user"`
+			Password string `cfg:"password" validate:"required"`
+		} `cfg:"db"`
+	}
+
+	t.Run("secret", func(t *testing.T) {
+		var cfg Config
+		err := Load(&cfg, IgnoreFile(), WithSources(&AWSSecretSource{SecretID: "prod/db", Endpoint: srv.URL}))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.DB.Host != "localhost" || cfg.DB.User != "admin" || cfg.DB.Password != "hunter2" {
+			t.Errorf("unexpected cfg %+v", cfg)
+		}
+	})
+
+	t.Run("missing secret", func(t *testing.T) {
+		var cfg Config
+		err := Load(&cfg, IgnoreFile(), WithSources(&AWSSecretSource{SecretID: "nope", Endpoint: srv.URL}))
+		if err == nil || !strings.Contains(err.Error(), "ResourceNotFoundException") {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+
+	t.Run("proxy", func(t *testing.T) {
+		// the endpoint doesn't resolve, so the request must go through
+		// srv as a proxy.
+		var cfg Config
+		src := &AWSSecretSource{SecretID: "prod/db", Endpoint: "http://secretsmanager.invalid", Proxy: srv.URL}
+		if err := Load(&cfg, IgnoreFile(), WithSources(src)); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.DB.Password != "hunter2" {
+			t.Errorf("want password hunter2, got %q", cfg.DB.Password)
+		}
+	})
+
+	t.Run("max file size", func(t *testing.T) {
+		var cfg Config
+		err := Load(&cfg, IgnoreFile(), MaxFileSize(16), WithSources(&AWSSecretSource{SecretID: "prod/db", Endpoint: srv.URL}))
+		if !errors.Is(err, ErrLimitExceeded) {
+			t.Fatalf("want ErrLimitExceeded, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package cfg
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// AzureKeyVaultSource is a source that fills config fields with secrets of
+// an Azure Key Vault, e.g. to keep database credentials out of config
+// files.
+//
+//	&cfg.AzureKeyVaultSource{
+//	  Vault: "https://my-vault.vault.azure.net",
+//	  Secrets: map[string]string{
+//	    "db.password": "db-password",                              // latest version
+//	    "api.key":     "api-key/4f1c2e0d9b8a4c7e8d6f5a4b3c2d1e0f", // pinned version
+//	  },
+//	}
+//
+// Requests are authorized with Token, or else with a token of the managed
+// identity of the host obtained from the Azure Instance Metadata Service.
+type AzureKeyVaultSource struct {
+	// Vault is the URL of the key vault.
+	Vault string
+	// Secrets maps paths of config fields to the secrets they are set to.
+	// A secret is given by its name, optionally followed by `/<version>`.
+	// The latest version is used unless a version is given.
+	Secrets map[string]string
+
+	Token            string       // OAuth2 access token. Defaults to the token of the managed identity.
+	ClientID         string       // client ID of a user-assigned managed identity, if any.
+	MetadataEndpoint string       // URL of the metadata service. Defaults to http://169.254.169.254.
+	Client           *http.Client // client used for requests. Defaults to http.DefaultClient.
+	Proxy            string       // URL of the proxy of requests to Vault. Defaults to that of the `HTTP_PROXY` env var.
+	TLS              *tls.Config  // TLS config of requests to Vault, e.g. with a client certificate and CAs for mTLS.
+}
+
+// Read returns the values of the secrets, nested at the paths of their
+// fields.
+func (s *AzureKeyVaultSource) Read(ctx context.Context) (map[string]interface{}, error) {
+	if s.Vault == "" {
+		return nil, fmt.Errorf("azure key vault: vault is required")
+	}
+
+	paths := make([]string, 0, len(s.Secrets))
+	for path := range s.Secrets {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	token := s.Token
+	vals := make(map[string]interface{})
+	for _, path := range paths {
+		name := s.Secrets[path]
+		if token == "" {
+			t, err := s.accessToken(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("azure secret %q: %w", name, err)
+			}
+			token = t
+		}
+
+		secret, err := s.getSecret(ctx, name, token)
+		if err != nil {
+			return nil, fmt.Errorf("azure secret %q: %w", name, err)
+		}
+		if err := setPath(vals, strings.Split(path, "."), secret); err != nil {
+			return nil, fmt.Errorf("azure secret %q: %w", name, err)
+		}
+	}
+	return vals, nil
+}
+
+// String returns the name of the source, e.g. in the Provenance of a
+// Result.
+func (s *AzureKeyVaultSource) String() string {
+	return "azure-keyvault:" + s.Vault
+}
+
+// getSecret returns the value of the secret name, which may include a
+// version.
+func (s *AzureKeyVaultSource) getSecret(ctx context.Context, name, token string) (string, error) {
+	u := strings.TrimSuffix(s.Vault, "/") + "/secrets/" + name + "?api-version=7.4"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var out struct {
+		Value string `json:"value"`
+	}
+	client, err := sourceClient(ctx, s.Client, true, s.Proxy, s.TLS)
+	if err != nil {
+		return "", err
+	}
+	if err := doRequest(client, req, &out); err != nil {
+		return "", err
+	}
+	return out.Value, nil
+}
+
+// accessToken returns an access token for Key Vault of the managed identity
+// from the metadata service, never with the client of WithHTTPClient.
+func (s *AzureKeyVaultSource) accessToken(ctx context.Context) (string, error) {
+	endpoint := s.MetadataEndpoint
+	if endpoint == "" {
+		endpoint = "http://169.254.169.254"
+	}
+
+	query := url.Values{
+		"api-version": {"2018-02-01"},
+		"resource":    {"https://vault.azure.net"},
+	}
+	if s.ClientID != "" {
+		query.Set("client_id", s.ClientID)
+	}
+
+	u := strings.TrimSuffix(endpoint, "/") + "/metadata/identity/oauth2/token?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata", "true")
+
+	var out struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := doRequest(metadataClient(s.Client), req, &out); err != nil {
+		return "", fmt.Errorf("unable to get token: %w", err)
+	}
+	if out.AccessToken == "" {
+		return "", fmt.Errorf("unable to get token: empty token")
+	}
+	return out.AccessToken, nil
+}
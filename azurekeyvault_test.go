@@ -0,0 +1,88 @@
+package cfg
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_AzureKeyVaultSource(t *testing.T) {
+	secrets := map[string]string{
+		"/secrets/db-password": "hunter2",
+		"/secrets/api-key/v3":  "key3",
+	}
+
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" || r.URL.Query().Get("api-version") == "" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		secret, ok := secrets[r.URL.Path]
+		if !ok {
+			http.Error(w, `{"error":{"code":"SecretNotFound"}}`, http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"value": %q, "id": "%s"}`, secret, r.URL.Path)
+	}))
+	t.Cleanup(vault.Close)
+
+	imds := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.Header.Get("Metadata") != "true" || r.URL.Path != "/metadata/identity/oauth2/token" ||
+			q.Get("resource") != "https://vault.azure.net" || q.Get("client_id") != "id" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"access_token": "tok", "expires_in": "86399", "token_type": "Bearer"}`)
+	}))
+	t.Cleanup(imds.Close)
+
+	type Config struct {
+		DB struct {
+			Host     string `cfg:"host" default:"localhost"`
+			Password string `cfg:"password" validate:"required"`
+		} `cfg:"db"`
+		APIKey string `cfg:"api_key"`
+	}
+
+	t.Run("managed identity", func(t *testing.T) {
+		var cfg Config
+		err := Load(&cfg, IgnoreFile(), WithSources(&AzureKeyVaultSource{
+			Vault: vault.URL,
+			Secrets: map[string]string{
+				"db.password": "db-password",
+				"api_key":     "api-key/v3",
+			},
+			ClientID:         "id",
+			MetadataEndpoint: imds.URL,
+		}))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.DB.Host != "localhost" || cfg.DB.Password != "hunter2" || cfg.APIKey != "key3" {
+			t.Errorf("unexpected cfg %+v", cfg)
+		}
+	})
+
+	t.Run("missing secret", func(t *testing.T) {
+		src := &AzureKeyVaultSource{
+			Vault:   vault.URL,
+			Secrets: map[string]string{"db.password": "nope"},
+			Token:   "tok",
+		}
+		_, err := src.Read(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "SecretNotFound") {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+
+	t.Run("missing vault", func(t *testing.T) {
+		src := &AzureKeyVaultSource{Token: "tok"}
+		if _, err := src.Read(context.Background()); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package cfg
+
+import (
+	"encoding"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"gopkg.in/yaml.v3"
+)
+
+var binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+
+// binaryUnmarshaler returns v as an encoding.BinaryUnmarshaler if its
+// address implements the interface and it isn't a TextUnmarshaler, which
+// takes precedence for strings.
+func binaryUnmarshaler(v reflect.Value) (encoding.BinaryUnmarshaler, bool) {
+	if !v.CanAddr() || reflect.PtrTo(v.Type()).Implements(textUnmarshalerType) {
+		return nil, false
+	}
+	bu, ok := v.Addr().Interface().(encoding.BinaryUnmarshaler)
+	return bu, ok
+}
+
+// decodeBase64 decodes s in the standard or URL base64 encoding, padded or
+// not.
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if b, err := enc.DecodeString(s); err == nil {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid base64 value")
+}
+
+// binaryUnmarshalerHookFunc returns a DecodeHookFunc that unmarshals
+// []byte values, such as those of YAML `!!binary` scalars, into types
+// implementing encoding.BinaryUnmarshaler, and base64 strings into such
+// types that don't implement encoding.TextUnmarshaler.
+func binaryUnmarshalerHookFunc() mapstructure.DecodeHookFunc {
+	return func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{}) (interface{}, error) {
+		if !reflect.PtrTo(t).Implements(binaryUnmarshalerType) {
+			return data, nil
+		}
+
+		var b []byte
+		switch data := data.(type) {
+		case []byte:
+			b = data
+		case string:
+			if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+				return data, nil
+			}
+			var err error
+			if b, err = decodeBase64(data); err != nil {
+				return nil, err
+			}
+		default:
+			return data, nil
+		}
+
+		v := reflect.New(t)
+		//nolint:forcetypeassert
+		if err := v.Interface().(encoding.BinaryUnmarshaler).UnmarshalBinary(b); err != nil {
+			return nil, err
+		}
+		return v.Elem().Interface(), nil
+	}
+}
+
+// yamlBinaryValues replaces the strings of v that were decoded from the
+// `!!binary` scalars of the YAML node n with their bytes, so that they're
+// told apart from base64 strings. v is the value n was decoded into.
+func yamlBinaryValues(n *yaml.Node, v interface{}) interface{} {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) > 0 {
+			return yamlBinaryValues(n.Content[0], v)
+		}
+	case yaml.MappingNode:
+		if m, ok := v.(map[string]interface{}); ok {
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				key := n.Content[i].Value
+				if child, ok := m[key]; ok {
+					m[key] = yamlBinaryValues(n.Content[i+1], child)
+				}
+			}
+		}
+	case yaml.SequenceNode:
+		if s, ok := v.([]interface{}); ok {
+			for i, child := range n.Content {
+				if i < len(s) {
+					s[i] = yamlBinaryValues(child, s[i])
+				}
+			}
+		}
+	case yaml.ScalarNode:
+		if s, ok := v.(string); ok && n.Tag == "!!binary" {
+			return []byte(s)
+		}
+	}
+	return v
+}
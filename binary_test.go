@@ -0,0 +1,87 @@
+package cfg
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testID [4]byte
+
+func (id *testID) UnmarshalBinary(b []byte) error {
+	if len(b) != len(id) {
+		return errors.New("id must be 4 bytes")
+	}
+	copy(id[:], b)
+	return nil
+}
+
+type testKey struct {
+	b []byte
+}
+
+func (k *testKey) UnmarshalBinary(b []byte) error {
+	k.b = append([]byte(nil), b...)
+	return nil
+}
+
+func Test_cfg_Load_BinaryUnmarshaler(t *testing.T) {
+	type Config struct {
+		ID     testID   `cfg:"id"`
+		Key    testKey  `cfg:"key"`
+		EnvKey *testKey `cfg:"env_key"`
+		Raw    []byte   `cfg:"raw"`
+		Name   string   `cfg:"name"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), `
+id: !!binary AQIDBA==
+key: c2VjcmV0
+raw: !!binary cmF3
+name: !!binary bmFtZQ==
+`)
+	setenv(t, "ENV_KEY", "ZW52")
+
+	var cfg Config
+	if err := Load(&cfg, Dirs(dir), UseEnv("")); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := Config{
+		ID:     testID{1, 2, 3, 4},
+		Key:    testKey{b: []byte("secret")},
+		EnvKey: &testKey{b: []byte("env")},
+		Raw:    []byte("raw"),
+		Name:   "name",
+	}
+	if !reflect.DeepEqual(want, cfg) {
+		t.Errorf("\nwant %+v\ngot  %+v", want, cfg)
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, data := range []string{"id: !!binary AQID\n", "key: '%%%'\n"} {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, "config.yaml"), data)
+
+			var cfg Config
+			if err := Load(&cfg, Dirs(dir)); err == nil {
+				t.Errorf("%s: expected err", strings.TrimSpace(data))
+			}
+		}
+	})
+}
+
+func Test_decodeBase64(t *testing.T) {
+	for _, s := range []string{"aGk/Pw==", "aGk/Pw", "aGk_Pw==", "aGk_Pw", " aGk/Pw==\n"} {
+		b, err := decodeBase64(s)
+		if err != nil || string(b) != "hi??" {
+			t.Errorf("%q: want hi??, got %q (err %v)", s, b, err)
+		}
+	}
+	if _, err := decodeBase64("not base64!"); err == nil {
+		t.Error("expected err")
+	}
+}
@@ -0,0 +1,68 @@
+package cfg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// unsafeFileChars matches the characters of source names that are
+// replaced in the names of cache files.
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// readSource reads the values of the i-th source, retrying failed reads
+// as configured. If a cache dir is
+// configured, the values of a successful read are persisted to it and the
+// last persisted values are returned in place of a failed read.
+func (f *cfg) readSource(ctx context.Context, i int, src Source, name string) (map[string]interface{}, error) {
+	vals, err := f.readRetry(ctx, src, name)
+	if f.cacheDir == "" {
+		return vals, err
+	}
+
+	dir, dirErr := expandHome(f.cacheDir)
+	if dirErr != nil {
+		f.warnf("%s: unable to cache values: %v", name, dirErr)
+		return vals, err
+	}
+	file := filepath.Join(dir, fmt.Sprintf("%d-%s.json", i, unsafeFileChars.ReplaceAllString(name, "_")))
+	if err == nil {
+		if cacheErr := writeCache(file, vals); cacheErr != nil {
+			f.warnf("%s: unable to cache values: %v", name, cacheErr)
+		}
+		return vals, nil
+	}
+
+	cached, cacheErr := readCache(file)
+	if cacheErr != nil {
+		return nil, err
+	}
+	f.warnf("%s: using cached values of %s: %v", name, file, err)
+	return cached, nil
+}
+
+// writeCache atomically writes vals to file as JSON. The file is only
+// readable by its owner as values may include secrets.
+func writeCache(file string, vals map[string]interface{}) error {
+	data, err := json.Marshal(vals)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(file, data, 0o600)
+}
+
+// readCache reads the values cached in file.
+func readCache(file string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	vals := make(map[string]interface{})
+	if err := json.Unmarshal(data, &vals); err != nil {
+		return nil, err
+	}
+	return vals, nil
+}
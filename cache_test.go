@@ -0,0 +1,72 @@
+package cfg
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_cfg_Load_SourceCache(t *testing.T) {
+	type Config struct {
+		Server struct {
+			Port int `cfg:"port"`
+		} `cfg:"server"`
+	}
+
+	dir := filepath.Join(t.TempDir(), "cache")
+	up := true
+	src := SourceFunc(func(ctx context.Context) (map[string]interface{}, error) {
+		if !up {
+			return nil, errors.New("connection refused")
+		}
+		return map[string]interface{}{"server": map[string]interface{}{"port": 8080}}, nil
+	})
+
+	var cfg Config
+	res, err := LoadResult(&cfg, IgnoreFile(), WithSources(src), SourceCache(dir))
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(res.Warnings) != 0 {
+		t.Errorf("unexpected warnings %v", res.Warnings)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("want 1 cache file, got %v (%v)", files, err)
+	}
+	if info, err := os.Stat(files[0]); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("want cache file mode 0600, got %v (%v)", info.Mode(), err)
+	}
+
+	t.Run("fallback", func(t *testing.T) {
+		up = false
+		t.Cleanup(func() { up = true })
+
+		var cfg Config
+		res, err := LoadResult(&cfg, IgnoreFile(), WithSources(src), SourceCache(dir))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Server.Port != 8080 {
+			t.Errorf("want cached port 8080, got %d", cfg.Server.Port)
+		}
+		if len(res.Warnings) != 1 || !strings.Contains(res.Warnings[0], "connection refused") {
+			t.Errorf("unexpected warnings %v", res.Warnings)
+		}
+	})
+
+	t.Run("no cache", func(t *testing.T) {
+		up = false
+		t.Cleanup(func() { up = true })
+
+		var cfg Config
+		err := Load(&cfg, IgnoreFile(), WithSources(src), SourceCache(t.TempDir()))
+		if err == nil || !strings.Contains(err.Error(), "connection refused") {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+}
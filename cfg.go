@@ -1,19 +1,22 @@
 package cfg
 
 import (
-	"encoding/json"
+	"context"
+	"flag"
 	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
 	"os"
 	"path/filepath"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/mitchellh/mapstructure"
-	"github.com/pelletier/go-toml"
-	"gopkg.in/yaml.v3"
 )
 
 const (
@@ -29,6 +32,11 @@ const (
 	DefaultTag = "cfg"
 	// DefaultTimeLayout is the default time layout that cfg uses to parse times.
 	DefaultTimeLayout = time.RFC3339
+
+	// EnvFileSuffix is the suffix of the env vars that name a file holding
+	// the value of a field, e.g. `MYAPP_DB_PASSWORD_FILE`, as is the
+	// convention of Docker secrets.
+	EnvFileSuffix = "_FILE"
 )
 
 // Load reads a configuration file and loads it into the given struct. The
@@ -64,13 +72,31 @@ func Load(cfg interface{}, options ...Option) error {
 	return conf.Load(cfg)
 }
 
+// LoadContext loads the config like Load, stopping with an error wrapping
+// the context's error once ctx is done. ctx is passed on to the Read method
+// of sources, so that fetches from remote sources respect its cancellation
+// and deadline.
+func LoadContext(ctx context.Context, cfg interface{}, options ...Option) error {
+	conf := defaultCfg()
+
+	for _, opt := range options {
+		opt(conf)
+	}
+
+	return conf.loadContext(ctx, cfg)
+}
+
+// defaultCfg returns a cfg with the default settings and the options of
+// SetDefaultOptions applied.
 func defaultCfg() *cfg {
-	return &cfg{
+	f := &cfg{
 		filename:   []string{DefaultFilename, DefaultSecondaryFilename},
 		dirs:       []string{DefaultDir},
 		tag:        DefaultTag,
 		timeLayout: DefaultTimeLayout,
 	}
+	applyDefaultOptions(f)
+	return f
 }
 
 type cfg struct {
@@ -82,89 +108,472 @@ type cfg struct {
 	useStrict  bool
 	ignoreFile bool
 	envPrefix  string
+	migrations []Migration
+
+	schemaVersion int
+	pathFields    map[string]bool
+	scope         string
+	profile       string
+	vars          map[string]string
+	runtimeVars   bool
+	expandEnv     bool
+	sections      map[string]interface{} // registered sections, or the copies they are loaded into, by name.
+	frozen        bool
+	sources       []Source
+	compatChecks  []func(old, new interface{}) error
+	fsys          fs.FS
+	maxFileSize   int64
+	maxKeys       int
+	maxDepth      int
+	timeout       time.Duration
+	reader        *readerInput // input of LoadReader.
+	inherited     bool         // true to load the config passed by Inherit or InheritPipe, if any.
+	clock         func() time.Time
+	subCommand    string
+	flags         *flag.FlagSet
+	overrides     map[string]interface{} // values that override every other source, by path.
+	cacheDir      string                 // dir that the values of sources are cached in.
+	retryAttempts int                    // number of attempts to read a source.
+	retryBackoff  time.Duration          // delay before the second attempt, doubled after each attempt.
+	httpClient    *http.Client           // client of remote config files and sources.
+	searchParents bool                   // true to search the parents of dirs for config files.
+	policies      map[string]Policy      // policies of the WithPolicy option, by name.
+	xdgApp        string                 // app whose XDG config dirs are searched.
+	platformApp   string                 // app whose config dirs of the platform are searched.
+	lint          bool                   // true to warn about suspicious values.
+	fileEnv       string                 // env var naming the config file, overriding filename and dirs.
+	required      map[string]bool        // names of the Files option that must be found, non-nil if used.
+	ambiguity     AmbiguityMode          // how ambiguous config files are treated, if they are detected.
+	reloadWindows []Window               // windows during which Reload applies changes.
+	reloadEvery   time.Duration          // min interval between the changes applied by Reload.
+	reloadStagger time.Duration          // window over which the instances of a fleet spread the changes applied by Reload.
+	envKeyFunc    func(string) string    // maps field paths to env var names in place of the default format.
+	envSep        string                 // separator of the names of env vars, "_" if empty.
+	digests       map[string]bool        // SHA-256 digests in hex that loaded files must match, if any.
+	strictEnv     bool                   // true to fail on env vars under the prefix that set no field.
+	skipFile      string                 // absolute path of a config file left out of the load, if any.
+	commitLock    sync.Locker            // held while changes are committed to a Live config, if set.
+	writeLock     sync.Locker            // held by the writers of a Live config, if set.
+
+	ctx     context.Context   // context of the current load.
+	files   []string          // paths of the loaded config files.
+	fileDir string            // directory of the first loaded config file.
+	dotenv  map[string]string // variables of the loaded dotenv files.
+
+	sourceNames  []string                   // names of the sources other than files that were read.
+	unused       []string                   // keys that were not decoded into any field.
+	warnings     []string                   // warnings raised while loading.
+	origins      map[string]string          // origin of the value of each key, by path.
+	kinds        map[string]map[string]bool // kinds of the origins of the values of each key, by lowercased path.
+	scopeFound   bool                       // true if values of the scope were found.
+	profileFound bool                       // true if values of the profile were found.
+	flagVals     map[string]string          // values of the set flags, by name.
+	policyVals   map[string]interface{}     // merged values of the files and sources, checked by policies and lint.
+	envNames     map[string]bool            // names of the env vars looked up for fields.
 }
 
 func (f *cfg) Load(cfg interface{}) error {
+	return f.loadContext(context.Background(), cfg)
+}
+
+func (f *cfg) loadContext(ctx context.Context, cfg interface{}) error {
 	if !isStructPtr(cfg) {
 		return fmt.Errorf("cfg must be a pointer to a struct")
 	}
-	filePaths := f.findCfgFile()
+	if f.sections == nil {
+		f.sections = registeredSections()
+	}
+	if err := f.checkSections(); err != nil {
+		return err
+	}
+	if f.inherited {
+		if err := f.loadInherited(); err != nil {
+			return err
+		}
+	}
+	if f.schemaVersion == 0 {
+		version, err := structVersion(cfg)
+		if err != nil {
+			return err
+		}
+		f.schemaVersion = version
+	}
+	if f.runtimeVars {
+		f.addRuntimeVars()
+	}
+	if f.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.timeout)
+		defer cancel()
+	}
+	if f.httpClient != nil {
+		ctx = context.WithValue(ctx, httpClientKey{}, f.httpClient)
+	}
+	if f.maxFileSize > 0 {
+		ctx = context.WithValue(ctx, maxFileSizeKey{}, f.maxFileSize)
+	}
+	if f.digests != nil {
+		ctx = context.WithValue(ctx, digestsKey{}, f.digests)
+	}
+	f.ctx = ctx
+
+	filePaths, err := f.findCfgFile()
+	if err != nil {
+		return err
+	}
+	if !f.ignoreFile {
+		if err := f.checkAmbiguity(filePaths); err != nil {
+			return err
+		}
+	}
 
-	if f.ignoreFile && !f.useEnv {
+	if f.ignoreFile && !f.hasOtherSources() {
 		return ErrInvalidSources
 	}
 
-	if len(filePaths) == 0 && !f.useEnv {
+	// with Files, only missing required files are an error.
+	if len(filePaths) == 0 && !f.hasOtherSources() && f.required == nil {
 		return fmt.Errorf("%s: %w", f.filename, ErrFileNotFound)
 	}
 
 	if !f.ignoreFile {
-		vals := make(map[string]interface{})
+		if len(filePaths) > 0 {
+			f.fileDir = dirOf(filePaths[0])
+		}
 
 		for _, filePath := range filePaths {
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("%s: %w", filePath, err)
+			}
+
+			f.files = append(f.files, filePath)
+
+			if fileExt(filePath) == ".env" {
+				if err := f.loadDotenv(filePath); err != nil {
+					return err
+				}
+				continue
+			}
+
+			vals := make(map[string]interface{})
+
 			err := f.decodeFile(vals, filePath)
 			if err != nil {
 				return err
 			}
 
+			if err := f.applyProfile(vals); err != nil {
+				return fmt.Errorf("%s: %w", filePath, err)
+			}
+
+			if err := f.applyScope(vals, filePath); err != nil {
+				return fmt.Errorf("%s: %w", filePath, err)
+			}
+
+			if err := f.prepareVals(vals, filePath, true); err != nil {
+				return fmt.Errorf("%s: %w", filePath, err)
+			}
+
 			if err := f.decodeMap(vals, cfg); err != nil {
 				return err
 			}
 		}
 	}
 
-	return f.processCfg(cfg)
+	if f.reader != nil {
+		if err := f.loadReader(cfg); err != nil {
+			return err
+		}
+	}
+
+	for i, src := range f.sources {
+		name := sourceName(src)
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("source %T: %w", src, err)
+		}
+		f.sourceNames = append(f.sourceNames, name)
+
+		vals, err := f.readSource(ctx, i, src, name)
+		if err != nil {
+			return fmt.Errorf("source %T: %w", src, err)
+		}
+		if err := f.prepareVals(vals, name, false); err != nil {
+			return fmt.Errorf("source %T: %w", src, err)
+		}
+		if err := f.decodeMap(vals, cfg); err != nil {
+			return err
+		}
+	}
+
+	if err := f.checkPolicies(); err != nil {
+		return err
+	}
+
+	if f.useEnv {
+		f.sourceNames = append(f.sourceNames, "env")
+	}
+
+	if f.flags != nil {
+		f.sourceNames = append(f.sourceNames, "flags")
+		f.flagVals = setFlags(f.flags)
+	}
+
+	if f.scope != "" && !f.scopeFound && len(f.files) > 0 {
+		f.warnf("scope %q not found in any config file", f.scope)
+	}
+
+	if f.profile != "" && !f.profileFound && len(f.files) > 0 {
+		f.warnf("profile %q not found in any config file", f.profile)
+	}
+
+	if err := f.processCfg(cfg); err != nil {
+		return err
+	}
+
+	if err := f.checkUnusedEnv(); err != nil {
+		return err
+	}
+
+	if f.frozen {
+		f.freeze(cfg)
+	}
+
+	return nil
 }
 
-func (f *cfg) findCfgFile() []string {
+// findCfgFile returns the paths of the config files to load: the file
+// named by the env var of the FileFromEnv option if it is set, or else the
+// files of each name in each dir, in order, followed by the objects of
+// object URLs. Names that are glob patterns match every file in lexical
+// order.
+func (f *cfg) findCfgFile() ([]string, error) {
+	if f.fileEnv != "" {
+		if name := os.Getenv(f.fileEnv); name != "" {
+			paths, err := f.findEnvFile(name)
+			return f.withoutSkipFile(paths), err
+		}
+	}
+
 	var paths []string
-	for _, dir := range f.dirs {
+	found := make(map[string]bool)
+	for _, dir := range f.searchDirs() {
+		// dirs that reference unset env vars only are skipped rather than
+		// searched as the working directory.
+		if dir = os.ExpandEnv(dir); dir == "" {
+			continue
+		}
+		dir, err := expandHome(dir)
+		if err != nil {
+			return nil, err
+		}
+		searchDirs := []string{dir}
+		if f.searchParents && f.fsys == nil {
+			searchDirs = parentDirs(dir)
+		}
+		for _, d := range searchDirs {
+			inDir, err := f.findInDir(d, found)
+			if err != nil {
+				return nil, err
+			}
+			if len(inDir) > 0 {
+				paths = append(paths, inDir...)
+				break
+			}
+		}
+	}
+	// objects are loaded from their URL regardless of the search dirs.
+	for _, name := range f.filename {
+		url := os.ExpandEnv(name)
+		if !isObjectURL(url) {
+			continue
+		}
+		exists, err := f.fileExists(url)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			paths = append(paths, url)
+			found[name] = true
+		}
+	}
+
+	if !f.ignoreFile {
 		for _, name := range f.filename {
-			path := filepath.Join(dir, name)
-			if fileExists(path) {
-				paths = append(paths, path)
+			if f.required[name] && !found[name] {
+				return nil, fmt.Errorf("%s: required file: %w", name, ErrFileNotFound)
 			}
 		}
 	}
-	return paths
+	return f.withoutSkipFile(paths), nil
+}
+
+// withoutSkipFile returns paths without the config file skipFile.
+func (f *cfg) withoutSkipFile(paths []string) []string {
+	if f.skipFile == "" || f.fsys != nil {
+		return paths
+	}
+	kept := paths[:0]
+	for _, path := range paths {
+		if abs, err := filepath.Abs(path); err == nil && abs == f.skipFile {
+			continue
+		}
+		kept = append(kept, path)
+	}
+	return kept
+}
+
+// findEnvFile returns the path of the config file name, the value of the
+// env var of the FileFromEnv option. The file must exist since it was
+// named explicitly.
+func (f *cfg) findEnvFile(name string) ([]string, error) {
+	if isObjectURL(name) {
+		return []string{name}, nil
+	}
+	path, err := expandHome(name)
+	if err != nil {
+		return nil, err
+	}
+	if !f.localFileExists(path) {
+		return nil, fmt.Errorf("%s (from %s): %w", path, f.fileEnv, ErrFileNotFound)
+	}
+	return []string{path}, nil
+}
+
+// findInDir returns the paths of the config files in dir, marking the
+// names of those found in found.
+func (f *cfg) findInDir(dir string, found map[string]bool) ([]string, error) {
+	var paths []string
+	for _, name := range f.filename {
+		if isObjectURL(os.ExpandEnv(name)) {
+			continue
+		}
+		path := filepath.Join(dir, os.ExpandEnv(name))
+		if isGlob(name) {
+			matches, err := f.glob(path)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			paths = append(paths, matches...)
+			found[name] = found[name] || len(matches) > 0
+			continue
+		}
+		if f.localFileExists(path) {
+			paths = append(paths, path)
+			found[name] = true
+		}
+	}
+	return paths, nil
+}
+
+// parentDirs returns dir followed by each of its parent dirs up to the
+// root. Parents of relative dirs are resolved against the working dir.
+func parentDirs(dir string) []string {
+	dirs := []string{dir}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return dirs
+	}
+	for {
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return dirs
+		}
+		dirs = append(dirs, parent)
+		abs = parent
+	}
+}
+
+// prepareVals runs the raw values of a config file or source through the
+// variable expansion, migrations and version check, and decodes the values
+// of the registered sections, leaving vals ready to be decoded into cfg.
+// file is set for the values of config files, which raise a warning if
+// they declare no version while one is expected. Sources are not
+// versioned, and so are never warned about.
+func (f *cfg) prepareVals(vals map[string]interface{}, origin string, file bool) error {
+	if err := f.checkLimits(vals); err != nil {
+		return err
+	}
+
+	if err := f.expandMap(vals); err != nil {
+		return err
+	}
+
+	if err := f.migrate(vals); err != nil {
+		return err
+	}
+
+	if _, ok := vals[VersionKey]; !ok && file && f.schemaVersion != 0 {
+		f.warnf("%s: no %s declared, expected version %d", origin, VersionKey, f.schemaVersion)
+	}
+
+	if err := f.checkVersion(vals); err != nil {
+		return err
+	}
+
+	f.recordPolicyVals(vals)
+
+	if err := f.decodeSections(vals); err != nil {
+		return err
+	}
+
+	if err := f.selectSubCommand(vals); err != nil {
+		return err
+	}
+
+	f.recordOrigin(vals, origin)
+
+	return nil
 }
 
 // decodeFile reads the file and unmarshalls it using a decoder based on the file extension.
 func (f *cfg) decodeFile(vals map[string]interface{}, file string) error {
-	fd, err := os.Open(file)
+	fd, err := f.open(file)
 	if err != nil {
 		return err
 	}
 	defer fd.Close()
 
-	switch filepath.Ext(file) {
-	case ".yaml", ".yml":
-		if err := yaml.NewDecoder(fd).Decode(&vals); err != nil {
-			return err
-		}
-	case ".json":
-		if err := json.NewDecoder(fd).Decode(&vals); err != nil {
-			return err
-		}
-	case ".toml":
-		tree, err := toml.LoadReader(fd)
-		if err != nil {
+	return f.decodeReader(vals, fd, file)
+}
+
+// decodeReader unmarshalls the contents of r using a decoder based on the
+// extension of name, executing them as a template first if variables are
+// configured.
+func (f *cfg) decodeReader(vals map[string]interface{}, r io.Reader, name string) error {
+	decode, err := lookupDecoder(fileExt(name))
+	if err != nil {
+		return err
+	}
+
+	if f.vars != nil {
+		if r, err = f.renderTemplate(r, name); err != nil {
 			return err
 		}
-		for field, val := range tree.ToMap() {
-			vals[field] = val
-		}
-	default:
-		return fmt.Errorf("unsupported file extension")
 	}
 
-	return nil
+	return decode(r, vals)
 }
 
 // decodeMap decodes a map of values into result using the mapstructure library.
 func (f *cfg) decodeMap(m map[string]interface{}, result interface{}) error {
+	if err := f.checkStrictTypes(m, reflect.TypeOf(result), ""); err != nil {
+		return err
+	}
+	var md mapstructure.Metadata
+	if err := f.decodeValue(m, result, &md); err != nil {
+		return f.suggestKeys(err, result)
+	}
+	f.unused = append(f.unused, md.Unused...)
+	return nil
+}
+
+// decodeValue decodes an arbitrary value into result using the mapstructure
+// library. md, if not nil, is filled with the keys that were decoded and
+// those that were not.
+func (f *cfg) decodeValue(input interface{}, result interface{}, md *mapstructure.Metadata) error {
 	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
 		WeaklyTypedInput: true,
+		Metadata:         md,
 		Result:           result,
 		TagName:          f.tag,
 		ErrorUnused:      f.useStrict,
@@ -172,12 +581,16 @@ func (f *cfg) decodeMap(m map[string]interface{}, result interface{}) error {
 			mapstructure.StringToTimeDurationHookFunc(),
 			mapstructure.StringToTimeHookFunc(f.timeLayout),
 			stringToRegexpHookFunc(),
+			binaryUnmarshalerHookFunc(),
+			sectionDecoderHookFunc(),
+			mapstructure.TextUnmarshallerHookFunc(),
+			numberToPercentHookFunc(),
 		),
 	})
 	if err != nil {
 		return err
 	}
-	return dec.Decode(m)
+	return dec.Decode(input)
 }
 
 // stringToRegexpHookFunc returns a DecodeHookFunc that converts strings to regexp.Regexp.
@@ -202,11 +615,59 @@ func stringToRegexpHookFunc() mapstructure.DecodeHookFunc {
 // where applicable.
 func (f *cfg) processCfg(cfg interface{}) error {
 	fields := flattenCfg(cfg, f.tag)
+	roots := f.sectionRoots()
+	for _, root := range roots {
+		flattenField(root, &fields, f.tag)
+	}
 	errs := make(fieldErrors)
 
-	for _, field := range fields {
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		defaulted := field.setDefault && isZero(field.v)
+		origin := f.origins[field.path()]
 		if err := f.processField(field); err != nil {
 			errs[field.path()] = err
+			continue
+		}
+		grown := f.growFromEnv(field)
+		// the elements of a composite default are flattened once set so
+		// that their own fields are processed in turn, as are those of a
+		// composite replaced or grown by env vars or overrides.
+		if defaulted {
+			flattenField(field, &fields, f.tag)
+		} else if grown || isEnvComposite(field.t) && f.origins[field.path()] != origin {
+			fields = append(fields[:i+1], dropDescendants(fields[i+1:], field.path())...)
+			flattenField(field, &fields, f.tag)
+		}
+		allocNilElems(field, &fields, f.tag)
+	}
+	f.warnUnmatchedOverrides(fields)
+
+	for _, field := range fields {
+		if _, ok := errs[field.path()]; ok {
+			continue
+		}
+		if err := f.checkSourceTag(field); err != nil {
+			errs[field.path()] = err
+		}
+	}
+
+	if f.lint {
+		for _, field := range fields {
+			if _, ok := errs[field.path()]; !ok {
+				f.lintField(field)
+			}
+		}
+	}
+
+	// validators run once all fields are processed so that they observe
+	// the defaults of their own fields.
+	for _, field := range append(fields, roots...) {
+		if _, ok := errs[field.path()]; ok {
+			continue
+		}
+		if err := validateField(field); err != nil {
+			errs[field.path()] = err
 		}
 	}
 
@@ -217,6 +678,107 @@ func (f *cfg) processCfg(cfg interface{}) error {
 	return nil
 }
 
+// dropDescendants returns the fields of fs that are not nested in the field
+// at path, keeping their order.
+func dropDescendants(fs []*field, path string) []*field {
+	kept := make([]*field, 0, len(fs))
+	for _, f := range fs {
+		if p := f.path(); !strings.HasPrefix(p, path+".") && !strings.HasPrefix(p, path+"[") {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// growFromEnv appends elements to the slice of structs of slice for as
+// long as env vars set the fields of the element past its end, e.g.
+// `MYAPP_SERVERS_2_HOST` for a slice of 2 servers, so that slices can be
+// set from env vars alone. It reports whether the slice grew.
+func (f *cfg) growFromEnv(slice *field) bool {
+	v := slice.v
+	if !f.useEnv && f.dotenv == nil || v.Kind() != reflect.Slice || isNoEnvField(slice) {
+		return false
+	}
+	t := v.Type().Elem()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || isScalarStruct(t) {
+		return false
+	}
+
+	// elements are probed in a copy of the slice so that the fields of its
+	// existing elements are left in place unless it grows.
+	n := v.Len()
+	for {
+		probe := *slice
+		probe.v = reflect.MakeSlice(v.Type(), n+1, n+1)
+		if t != v.Type().Elem() {
+			probe.v.Index(n).Set(reflect.New(t))
+		}
+		elemFields := []*field{newSliceField(&probe, n, f.tag)}
+		flattenField(elemFields[0], &elemFields, f.tag)
+		if !f.anyEnvSet(elemFields) {
+			break
+		}
+		n++
+	}
+	if n == v.Len() {
+		return false
+	}
+
+	grown := reflect.MakeSlice(v.Type(), n, n)
+	reflect.Copy(grown, v)
+	if t != v.Type().Elem() {
+		for i := v.Len(); i < n; i++ {
+			grown.Index(i).Set(reflect.New(t))
+		}
+	}
+	v.Set(grown)
+	return true
+}
+
+// anyEnvSet reports whether the env var, dotenv variable or `_FILE` env var
+// of the path of any of fields is set. Names of env tags are ignored as they
+// are the same for each element of a slice.
+func (f *cfg) anyEnvSet(fields []*field) bool {
+	for _, field := range fields {
+		name := f.formatEnvKey(field.path())
+		if _, ok := f.dotenv[name]; ok {
+			return true
+		}
+		if !f.useEnv {
+			continue
+		}
+		if _, ok := os.LookupEnv(name); ok {
+			return true
+		}
+		if _, ok := os.LookupEnv(name + EnvFileSuffix); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// allocNilElems allocates the nil elements of a slice or array of struct
+// pointers and flattens them into fs, so that their fields are set from env
+// vars and defaulted like those of a slice of structs.
+func allocNilElems(field *field, fs *[]*field, tagKey string) {
+	v := field.v
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return
+	}
+	if t := v.Type().Elem(); t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct || isScalarStruct(t.Elem()) {
+		return
+	}
+	for i := 0; i < v.Len(); i++ {
+		if elem := v.Index(i); elem.IsNil() {
+			elem.Set(reflect.New(elem.Type().Elem()))
+			flattenField(newSliceField(field, i, tagKey), fs, tagKey)
+		}
+	}
+}
+
 // processField processes a single field and is called by processCfg
 // for each field in cfg.
 func (f *cfg) processField(field *field) error {
@@ -224,64 +786,232 @@ func (f *cfg) processField(field *field) error {
 		return fmt.Errorf("field cannot have both a required validation and a default value")
 	}
 
-	if f.useEnv {
-		if err := f.setFromEnv(field.v, field.path()); err != nil {
+	noEnv := isNoEnvField(field)
+	if val, ok := f.dotenv[f.envKey(field)]; ok && !noEnv {
+		val, err := f.expandVars(val)
+		if err != nil {
+			return fmt.Errorf("unable to set from dotenv: %w", err)
+		}
+		if err := f.setEnvValue(field.v, val, field.path(), field.sep); err != nil {
+			return fmt.Errorf("unable to set from dotenv: %w", err)
+		}
+		f.setOrigin(field.path(), "dotenv")
+	}
+
+	if f.useEnv && !noEnv {
+		if err := f.setFromEnv(field.v, f.envKey(field), field.path(), field.sep); err != nil {
 			return fmt.Errorf("unable to set from env: %w", err)
 		}
 	}
 
+	if val, ok := f.flagVals[field.path()]; ok {
+		if err := f.setValue(field.v, val, field.sep); err != nil {
+			return fmt.Errorf("unable to set from flag: %w", err)
+		}
+		f.setOrigin(field.path(), "flag")
+	}
+
+	if val, ok := f.overrides[field.path()]; ok {
+		if err := f.setOverride(field.v, val, field.sep); err != nil {
+			return fmt.Errorf("unable to set override: %w", err)
+		}
+		f.setOrigin(field.path(), "override")
+	}
+
+	if len(field.transforms) > 0 {
+		if err := transformValue(field.v, field.transforms); err != nil {
+			return fmt.Errorf("unable to transform: %w", err)
+		}
+	}
+
 	if field.required && isZero(field.v) {
 		return fmt.Errorf("required validation failed")
 	}
 
 	if field.setDefault && isZero(field.v) {
-		if err := f.setDefaultValue(field.v, field.defaultVal); err != nil {
+		if err := f.setDefaultValue(field.v, field.defaultVal, field.sep); err != nil {
 			return fmt.Errorf("unable to set default: %w", err)
 		}
+		f.setOrigin(field.path(), "default")
+	}
+
+	if field.isPath || f.pathFields[field.path()] {
+		if err := transformStrings(field.v, f.resolvePath); err != nil {
+			return fmt.Errorf("unable to resolve path: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// now returns the current time according to the configured clock.
+func (f *cfg) now() time.Time {
+	if f.clock != nil {
+		return f.clock()
 	}
+	return time.Now()
+}
+
+// Validator is implemented by types that validate their own values. Fields
+// of config structs whose type implements Validator are validated after the
+// config is loaded and any error is returned as an error of that field.
+type Validator interface {
+	Validate() error
+}
 
+// validateField calls Validate on the field's value if it implements
+// Validator. nil pointers are not validated.
+func validateField(field *field) error {
+	v := field.v
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+	} else if v.CanAddr() {
+		v = v.Addr()
+	}
+	if !v.CanInterface() {
+		return nil
+	}
+	if validator, ok := v.Interface().(Validator); ok {
+		return validator.Validate()
+	}
 	return nil
 }
 
-func (f *cfg) setFromEnv(fv reflect.Value, key string) error {
-	key = f.formatEnvKey(key)
-	if val, ok := os.LookupEnv(key); ok {
-		return f.setValue(fv, val)
+// setFromEnv sets fv, the field at key, to the value of the env var name
+// if it is set, or else to the contents of the file named by the env var
+// name + `_FILE`, if that is set. sep separates the elements of slices.
+func (f *cfg) setFromEnv(fv reflect.Value, name, key, sep string) error {
+	if f.envNames == nil {
+		f.envNames = make(map[string]bool)
+	}
+	f.envNames[name] = true
+
+	val, ok := os.LookupEnv(name)
+	file, fromFile := os.LookupEnv(name + EnvFileSuffix)
+	switch {
+	case ok && fromFile:
+		return fmt.Errorf("both %s and %s%s are set", name, name, EnvFileSuffix)
+	case ok:
+		var err error
+		if val, err = f.expandVars(val); err != nil {
+			return err
+		}
+	case fromFile:
+		data, err := f.readEnvFile(file)
+		if err != nil {
+			return fmt.Errorf("%s%s: %w", name, EnvFileSuffix, err)
+		}
+		val = strings.TrimRight(string(data), "\r\n")
+	default:
+		return nil
 	}
+
+	if err := f.setEnvValue(fv, val, key, sep); err != nil {
+		return err
+	}
+	f.setOrigin(key, "env")
 	return nil
 }
 
+// readEnvFile returns the contents of the file of a `_FILE` env var, which
+// are subject to the max file size.
+func (f *cfg) readEnvFile(name string) ([]byte, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return readLimited(file, f.maxFileSize)
+}
+
+// envKey returns the name of the env var of field: the name of its env
+// tag, if any, or else its formatted path.
+func (f *cfg) envKey(field *field) string {
+	if field.envName != "" && field.sliceIdx < 0 {
+		return field.envName
+	}
+	return f.formatEnvKey(field.path())
+}
+
+// isNoEnvField reports whether field or any of its ancestors is tagged
+// with `env:"-"`, and so is never set from env vars.
+func isNoEnvField(field *field) bool {
+	for f := field; f != nil; f = f.parent {
+		if f.noEnv {
+			return true
+		}
+	}
+	return false
+}
+
 func (f *cfg) formatEnvKey(key string) string {
+	if f.envKeyFunc != nil {
+		return f.envKeyFunc(key)
+	}
+	sep := f.envSep
+	if sep == "" {
+		sep = "_"
+	}
 	// loggers[0].level --> loggers_0_level
-	key = strings.NewReplacer(".", "_", "[", "_", "]", "").Replace(key)
+	if f.subCommand != "" {
+		key = f.subCommand + "." + key
+	}
+	key = strings.NewReplacer(".", sep, "[", sep, "]", "").Replace(key)
 	if f.envPrefix != "" {
-		key = fmt.Sprintf("%s_%s", f.envPrefix, key)
+		key = f.envPrefix + sep + key
 	}
 	return strings.ToUpper(key)
 }
 
 // setDefaultValue calls setValue but disallows booleans from
 // being set.
-func (f *cfg) setDefaultValue(fv reflect.Value, val string) error {
+func (f *cfg) setDefaultValue(fv reflect.Value, val, sep string) error {
 	if fv.Kind() == reflect.Bool {
 		return fmt.Errorf("unsupported type: %v", fv.Kind())
 	}
-	return f.setValue(fv, val)
+	val, err := f.expandVars(val)
+	if err != nil {
+		return err
+	}
+	if isComposite(fv.Type()) {
+		return f.setComposite(fv, val)
+	}
+	return f.setValue(fv, val, sep)
 }
 
-// setValue sets fv to val. it attempts to convert val to the correct
-// type based on the field's kind. if conversion fails an error is
-// returned.
+// setValue sets fv to val. types implementing encoding.TextUnmarshaler
+// (other than time.Time) unmarshal val themselves, otherwise it attempts
+// to convert val to the correct type based on the field's kind. the elements
+// of slices are separated by sep, or "," if it is empty. if conversion fails
+// an error is returned.
 // fv must be settable else this panics.
-func (f *cfg) setValue(fv reflect.Value, val string) error {
+func (f *cfg) setValue(fv reflect.Value, val, sep string) error {
+	if tu, ok := textUnmarshaler(fv); ok {
+		return tu.UnmarshalText([]byte(val))
+	}
+
+	if bu, ok := binaryUnmarshaler(fv); ok {
+		b, err := decodeBase64(val)
+		if err != nil {
+			return err
+		}
+		return bu.UnmarshalBinary(b)
+	}
+
+	if sd, ok := sectionDecoder(fv); ok {
+		return setSection(sd, val)
+	}
+
 	switch fv.Kind() {
 	case reflect.Ptr:
 		if fv.IsNil() {
 			fv.Set(reflect.New(fv.Type().Elem()))
 		}
-		return f.setValue(fv.Elem(), val)
+		return f.setValue(fv.Elem(), val, sep)
 	case reflect.Slice:
-		if err := f.setSlice(fv, val); err != nil {
+		if err := f.setSlice(fv, val, sep); err != nil {
 			return err
 		}
 	case reflect.Bool:
@@ -320,6 +1050,10 @@ func (f *cfg) setValue(fv reflect.Value, val string) error {
 		fv.SetString(val)
 	case reflect.Struct: // struct is only allowed a default in the special case where it's a time.Time
 		if _, ok := fv.Interface().(time.Time); ok {
+			if val == "now" {
+				fv.Set(reflect.ValueOf(f.now()))
+				return nil
+			}
 			t, err := time.Parse(f.timeLayout, val)
 			if err != nil {
 				return err
@@ -341,14 +1075,15 @@ func (f *cfg) setValue(fv reflect.Value, val string) error {
 }
 
 // setSlice val to sv. val should be a Go slice formatted as a string
-// (e.g. "[1,2]") and sv must be a slice value. if conversion of val
-// to a slice fails then an error is returned.
+// (e.g. "[1,2]") and sv must be a slice value. elements are separated
+// by sep, or "," if it is empty. if conversion of val to a slice fails
+// then an error is returned.
 // sv must be settable else this panics.
-func (f *cfg) setSlice(sv reflect.Value, val string) error {
-	ss := stringSlice(val)
+func (f *cfg) setSlice(sv reflect.Value, val, sep string) error {
+	ss := stringSlice(val, sep)
 	slice := reflect.MakeSlice(sv.Type(), len(ss), cap(ss))
 	for i, s := range ss {
-		if err := f.setValue(slice.Index(i), s); err != nil {
+		if err := f.setValue(slice.Index(i), s, sep); err != nil {
 			return err
 		}
 	}
@@ -1,6 +1,7 @@
 package cfg
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -140,7 +141,7 @@ func validPodConfig() Pod {
 }
 
 func Test_cfg_Load(t *testing.T) {
-	for _, f := range []string{"pod.yaml", "pod.json", "pod.toml"} {
+	for _, f := range []string{"pod.yaml", "pod.json", "pod.toml", "pod.xml"} {
 		t.Run(f, func(t *testing.T) {
 			var cfg Pod
 			err := Load(&cfg, File(f), Dirs(filepath.Join("testdata", "valid")))
@@ -185,7 +186,7 @@ func Test_cfg_Load_NonStructPtr(t *testing.T) {
 }
 
 func Test_cfg_Load_Required(t *testing.T) {
-	for _, f := range []string{"pod.yaml", "pod.json", "pod.toml"} {
+	for _, f := range []string{"pod.yaml", "pod.json", "pod.toml", "pod.xml"} {
 		t.Run(f, func(t *testing.T) {
 			var cfg Pod
 			err := Load(&cfg, File(f), Dirs(filepath.Join("testdata", "invalid")))
@@ -218,7 +219,7 @@ func Test_cfg_Load_Required(t *testing.T) {
 
 func Test_cfg_Load_Defaults(t *testing.T) {
 	t.Run("non-zero values are not overridden", func(t *testing.T) {
-		for _, f := range []string{"server.yaml", "server.json", "server.toml"} {
+		for _, f := range []string{"server.yaml", "server.json", "server.toml", "server.properties", "server.xml", "server.json5"} {
 			t.Run(f, func(t *testing.T) {
 				type Server struct {
 					Host   string `cfg:"host" default:"127.0.0.1"`
@@ -259,7 +260,7 @@ func Test_cfg_Load_Defaults(t *testing.T) {
 	})
 
 	t.Run("bad defaults reported as errors", func(t *testing.T) {
-		for _, f := range []string{"server.yaml", "server.json", "server.toml"} {
+		for _, f := range []string{"server.yaml", "server.json", "server.toml", "server.properties", "server.xml", "server.json5"} {
 			t.Run(f, func(t *testing.T) {
 				type Server struct {
 					Host   string `cfg:"host" default:"127.0.0.1"`
@@ -304,7 +305,7 @@ func Test_cfg_Load_Defaults(t *testing.T) {
 }
 
 func Test_cfg_Load_RequiredAndDefaults(t *testing.T) {
-	for _, f := range []string{"server.yaml", "server.json", "server.toml"} {
+	for _, f := range []string{"server.yaml", "server.json", "server.toml", "server.properties", "server.xml", "server.json5"} {
 		t.Run(f, func(t *testing.T) {
 			type Server struct {
 				Host   string `cfg:"host" default:"127.0.0.1"`
@@ -347,7 +348,7 @@ func Test_cfg_Load_RequiredAndDefaults(t *testing.T) {
 }
 
 func Test_cfg_Load_UseStrict(t *testing.T) {
-	for _, f := range []string{"server.yaml", "server.json", "server.toml"} {
+	for _, f := range []string{"server.yaml", "server.json", "server.toml", "server.properties", "server.xml", "server.json5"} {
 		t.Run(f, func(t *testing.T) {
 			type Server struct {
 				Host string `fig:"host"`
@@ -378,8 +379,50 @@ func Test_cfg_Load_UseStrict(t *testing.T) {
 	}
 }
 
+func Test_SetDefaultOptions(t *testing.T) {
+	type Config struct {
+		Host  string    `config:"host"`
+		Port  int       `config:"port"`
+		Start time.Time `config:"start"`
+	}
+
+	SetDefaultOptions(Tag("config"), TimeLayout("2006-01-02"), UseEnv("acme"))
+	t.Cleanup(func() { SetDefaultOptions() })
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "host: localhost\nstart: \"2026-01-02\"\n")
+	setenv(t, "ACME_PORT", "8080")
+
+	var cfg Config
+	if err := Load(&cfg, Dirs(dir)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	want := Config{Host: "localhost", Port: 8080, Start: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}
+	if cfg != want {
+		t.Errorf("want %+v, got %+v", want, cfg)
+	}
+
+	t.Run("options take precedence", func(t *testing.T) {
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir), UseEnv("other")); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Port != 0 {
+			t.Errorf("want port 0, got %d", cfg.Port)
+		}
+	})
+
+	t.Run("cleared", func(t *testing.T) {
+		SetDefaultOptions()
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir)); err == nil {
+			t.Fatal("expected err parsing start with the default time layout")
+		}
+	})
+}
+
 func Test_cfg_Load_WithOptions(t *testing.T) {
-	for _, f := range []string{"server.yaml", "server.json", "server.toml"} {
+	for _, f := range []string{"server.yaml", "server.json", "server.toml", "server.properties", "server.xml", "server.json5"} {
 		t.Run(f, func(t *testing.T) {
 			type Server struct {
 				Host   string `custom:"host" default:"127.0.0.1"`
@@ -497,13 +540,138 @@ func Test_cfg_Load_IgnoreFile(t *testing.T) {
 	}
 }
 
+func Test_cfg_Load_PointerScalars(t *testing.T) {
+	type Config struct {
+		Timeout  *time.Duration `cfg:"timeout" default:"5s"`
+		Ratio    *float64       `cfg:"ratio" default:"0.5"`
+		Debug    *bool          `cfg:"debug" default:"true"`
+		Verbose  *bool          `cfg:"verbose" default:"true"`
+		Retries  *int           `cfg:"retries" default:"3"`
+		Interval *time.Duration `cfg:"interval"`
+		Enabled  *bool          `cfg:"enabled" validate:"required"`
+		Workers  *int           `cfg:"workers" validate:"required"`
+	}
+
+	load := func(t *testing.T, data string) (Config, error) {
+		t.Helper()
+		var cfg Config
+		err := LoadBytes(&cfg, []byte(data), ".yaml", UseEnv("app"))
+		return cfg, err
+	}
+
+	t.Run("defaults and env", func(t *testing.T) {
+		os.Clearenv()
+		setenv(t, "APP_INTERVAL", "3s")
+		setenv(t, "APP_ENABLED", "false")
+		setenv(t, "APP_WORKERS", "4")
+
+		cfg, err := load(t, "verbose: false\nretries: 0\n")
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		if cfg.Timeout == nil || *cfg.Timeout != 5*time.Second {
+			t.Errorf("timeout: want 5s, got %v", cfg.Timeout)
+		}
+		if cfg.Ratio == nil || *cfg.Ratio != 0.5 {
+			t.Errorf("ratio: want 0.5, got %v", cfg.Ratio)
+		}
+		if cfg.Debug == nil || !*cfg.Debug {
+			t.Errorf("debug: want default true, got %v", cfg.Debug)
+		}
+		if cfg.Verbose == nil || *cfg.Verbose {
+			t.Errorf("verbose: want explicit false to be kept, got %v", cfg.Verbose)
+		}
+		if cfg.Retries == nil || *cfg.Retries != 3 {
+			t.Errorf("retries: want zero value to be defaulted like an int, got %v", cfg.Retries)
+		}
+		if cfg.Interval == nil || *cfg.Interval != 3*time.Second {
+			t.Errorf("interval: want 3s, got %v", cfg.Interval)
+		}
+		if cfg.Enabled == nil || *cfg.Enabled {
+			t.Errorf("enabled: want false, got %v", cfg.Enabled)
+		}
+		if cfg.Workers == nil || *cfg.Workers != 4 {
+			t.Errorf("workers: want 4, got %v", cfg.Workers)
+		}
+	})
+
+	t.Run("required", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			data string
+			env  map[string]string
+		}{
+			"unset":      {data: "{}"},
+			"zero file":  {data: "enabled: true\nworkers: 0\n"},
+			"zero env":   {data: "{}", env: map[string]string{"APP_ENABLED": "true", "APP_WORKERS": "0"}},
+			"bool unset": {data: "workers: 1\n"},
+		} {
+			t.Run(name, func(t *testing.T) {
+				os.Clearenv()
+				for k, v := range tc.env {
+					setenv(t, k, v)
+				}
+				_, err := load(t, tc.data)
+				if err == nil {
+					t.Fatal("expected err")
+				}
+				for path, err := range err.(fieldErrors) {
+					if path != "enabled" && path != "workers" {
+						t.Errorf("unexpected err for %s: %v", path, err)
+					}
+				}
+			})
+		}
+	})
+}
+
+func Test_LoadContext(t *testing.T) {
+	type Config struct {
+		Host string `cfg:"host"`
+	}
+
+	var gotCtx context.Context
+	src := SourceFunc(func(ctx context.Context) (map[string]interface{}, error) {
+		gotCtx = ctx
+		return map[string]interface{}{"host": "localhost"}, nil
+	})
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "load")
+
+	var cfg Config
+	if err := LoadContext(ctx, &cfg, IgnoreFile(), WithSources(src)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if cfg.Host != "localhost" {
+		t.Errorf("want host localhost, got %q", cfg.Host)
+	}
+	if gotCtx == nil || gotCtx.Value(key{}) != "load" {
+		t.Errorf("source did not receive the load context")
+	}
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var cfg Config
+		err := LoadContext(ctx, &cfg, File("server.yaml"), Dirs(filepath.Join("testdata", "valid")))
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("want context.Canceled, got %v", err)
+		}
+	})
+}
+
 func Test_cfg_findCfgFile(t *testing.T) {
 	t.Run("finds existing file", func(t *testing.T) {
 		conf := defaultCfg()
 		conf.filename = []string{"pod.yaml"}
 		conf.dirs = []string{".", "testdata", filepath.Join("testdata", "valid")}
 
-		filePaths := conf.findCfgFile()
+		filePaths, err := conf.findCfgFile()
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
 		if len(filePaths) == 0 {
 			t.Fatalf("filePaths slice shouldn't be empty")
 		}
@@ -519,17 +687,218 @@ func Test_cfg_findCfgFile(t *testing.T) {
 		conf.filename = []string{"nope.nope"}
 		conf.dirs = []string{".", "testdata", filepath.Join("testdata", "valid")}
 
-		filePaths := conf.findCfgFile()
+		filePaths, err := conf.findCfgFile()
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
 		if len(filePaths) > 0 {
 			t.Fatalf("got file %s but empty was expected", filePaths)
 		}
 	})
+
+	t.Run("expands env vars", func(t *testing.T) {
+		setenv(t, "CFG_TEST_DIR", "testdata")
+		setenv(t, "CFG_TEST_NAME", "pod")
+
+		conf := defaultCfg()
+		conf.filename = []string{"${CFG_TEST_NAME}.yaml"}
+		conf.dirs = []string{"$CFG_TEST_UNSET", filepath.Join("$CFG_TEST_DIR", "valid")}
+
+		filePaths, err := conf.findCfgFile()
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		want := []string{filepath.Join("testdata", "valid", "pod.yaml")}
+		if !reflect.DeepEqual(want, filePaths) {
+			t.Fatalf("want files %v, got %v", want, filePaths)
+		}
+	})
+
+	t.Run("searches parents", func(t *testing.T) {
+		root := t.TempDir()
+		nested := filepath.Join(root, "a", "b", "c")
+		if err := os.MkdirAll(nested, 0o700); err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, filepath.Join(root, "config.yaml"), "{}")
+		writeFile(t, filepath.Join(root, "a", "config.yaml"), "{}")
+
+		conf := defaultCfg()
+		conf.dirs = []string{nested}
+		conf.searchParents = true
+
+		filePaths, err := conf.findCfgFile()
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		want := []string{filepath.Join(root, "a", "config.yaml")}
+		if !reflect.DeepEqual(want, filePaths) {
+			t.Fatalf("want files %v, got %v", want, filePaths)
+		}
+
+		conf.searchParents = false
+		if filePaths, _ := conf.findCfgFile(); len(filePaths) > 0 {
+			t.Fatalf("got files %v but empty was expected", filePaths)
+		}
+	})
+}
+
+func Test_cfg_Load_Files(t *testing.T) {
+	type Config struct {
+		Server struct {
+			Host string `cfg:"host"`
+			Port int    `cfg:"port"`
+		} `cfg:"server"`
+		Level string   `cfg:"level"`
+		Tags  []string `cfg:"tags"`
+	}
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "conf.d"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "base.yaml"), "server:\n  host: 0.0.0.0\n  port: 80\nlevel: info\n")
+	writeFile(t, filepath.Join(dir, "conf.d", "20-level.yaml"), "level: debug\ntags: [b]\n")
+	writeFile(t, filepath.Join(dir, "conf.d", "10-port.yaml"), "server:\n  port: 8080\ntags: [a]\n")
+	writeFile(t, filepath.Join(dir, "conf.d", "README.md"), "not a config file")
+	if err := os.Mkdir(filepath.Join(dir, "conf.d", "30-dir.yaml"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg Config
+	res, err := LoadResult(&cfg, Files(Required("base.yaml"), Optional("conf.d/*.yaml")), Dirs(dir))
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	var want Config
+	want.Server.Host = "0.0.0.0"
+	want.Server.Port = 8080
+	want.Level = "debug"
+	want.Tags = []string{"b"}
+	if !reflect.DeepEqual(want, cfg) {
+		t.Errorf("\nwant %+v\ngot  %+v", want, cfg)
+	}
+
+	wantFiles := []string{
+		filepath.Join(dir, "base.yaml"),
+		filepath.Join(dir, "conf.d", "10-port.yaml"),
+		filepath.Join(dir, "conf.d", "20-level.yaml"),
+	}
+	if !reflect.DeepEqual(wantFiles, res.Files) {
+		t.Errorf("want files %v, got %v", wantFiles, res.Files)
+	}
+
+	t.Run("bad pattern", func(t *testing.T) {
+		var cfg Config
+		if err := Load(&cfg, File("conf.d/[.yaml"), Dirs(dir)); !errors.Is(err, filepath.ErrBadPattern) {
+			t.Fatalf("want ErrBadPattern, got %v", err)
+		}
+	})
+
+	t.Run("missing optional", func(t *testing.T) {
+		var cfg Config
+		if err := Load(&cfg, Files(Optional("base.yaml"), Optional("override.yaml"), Optional("none.d/*.yaml")), Dirs(dir)); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Level != "info" {
+			t.Errorf("want level info, got %q", cfg.Level)
+		}
+	})
+
+	t.Run("none found", func(t *testing.T) {
+		var cfg Config
+		if err := Load(&cfg, Files(Optional("override.yaml")), Dirs(dir)); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+
+	t.Run("missing required", func(t *testing.T) {
+		var cfg Config
+		err := Load(&cfg, Files(Required("base.yaml"), Required("override.yaml")), Dirs(dir))
+		if !errors.Is(err, ErrFileNotFound) {
+			t.Fatalf("want ErrFileNotFound, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "override.yaml") {
+			t.Errorf("want missing file in err, got %v", err)
+		}
+	})
+
+	t.Run("required pattern without match", func(t *testing.T) {
+		var cfg Config
+		if err := Load(&cfg, Files(Required("none.d/*.yaml")), Dirs(dir)); !errors.Is(err, ErrFileNotFound) {
+			t.Fatalf("want ErrFileNotFound, got %v", err)
+		}
+	})
+
+	t.Run("replaces default filenames", func(t *testing.T) {
+		writeFile(t, filepath.Join(dir, "config.yaml"), "level: warn\n")
+		var cfg Config
+		if err := Load(&cfg, Files(Required("base.yaml")), Dirs(dir)); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Level != "info" {
+			t.Errorf("want level info, got %q", cfg.Level)
+		}
+	})
+}
+
+func Test_cfg_Load_FileFromEnv(t *testing.T) {
+	type Config struct {
+		Host string `cfg:"host"`
+		Port int    `cfg:"port"`
+	}
+
+	dir, other := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "host: default\nport: 80\n")
+	writeFile(t, filepath.Join(other, "prod.json"), `{"host": "prod"}`)
+
+	t.Run("set", func(t *testing.T) {
+		setenv(t, "MYAPP_CONFIG", filepath.Join(other, "prod.json"))
+
+		var cfg Config
+		res, err := LoadResult(&cfg, FileFromEnv("MYAPP_CONFIG"), Dirs(dir))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if want := (Config{Host: "prod"}); cfg != want {
+			t.Errorf("want %+v, got %+v", want, cfg)
+		}
+		if want := []string{filepath.Join(other, "prod.json")}; !reflect.DeepEqual(want, res.Files) {
+			t.Errorf("want files %v, got %v", want, res.Files)
+		}
+	})
+
+	t.Run("unset", func(t *testing.T) {
+		setenv(t, "MYAPP_CONFIG", "")
+
+		var cfg Config
+		if err := Load(&cfg, FileFromEnv("MYAPP_CONFIG"), Dirs(dir)); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if want := (Config{Host: "default", Port: 80}); cfg != want {
+			t.Errorf("want %+v, got %+v", want, cfg)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		setenv(t, "MYAPP_CONFIG", filepath.Join(other, "missing.yaml"))
+
+		var cfg Config
+		err := Load(&cfg, FileFromEnv("MYAPP_CONFIG"), Dirs(dir))
+		if !errors.Is(err, ErrFileNotFound) {
+			t.Fatalf("want ErrFileNotFound, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "MYAPP_CONFIG") {
+			t.Errorf("want env var in err, got %v", err)
+		}
+	})
 }
 
 func Test_cfg_decodeFile(t *testing.T) {
 	conf := defaultCfg()
 
-	for _, f := range []string{"bad.yaml", "bad.json", "bad.toml"} {
+	for _, f := range []string{"bad.yaml", "bad.json", "bad.toml", "bad.properties", "bad.xml", "bad.json5"} {
 		t.Run(f, func(t *testing.T) {
 			file := filepath.Join("testdata", "invalid", f)
 			if !fileExists(file) {
@@ -651,6 +1020,93 @@ func Test_cfg_processCfg(t *testing.T) {
 		}
 	})
 
+	t.Run("pointer slice elements set by env", func(t *testing.T) {
+		conf := defaultCfg()
+		conf.tag = "cfg"
+		conf.useEnv = true
+		conf.envPrefix = "spec"
+
+		os.Clearenv()
+		setenv(t, "SPEC_CONTAINERS_0_IMAGE", "nginx")
+		setenv(t, "SPEC_CONTAINERS_1_IMAGE", "redis")
+
+		type Container struct {
+			Name  string `cfg:"name"`
+			Image string `cfg:"image" validate:"required"`
+			Pull  string `cfg:"pull" default:"always"`
+		}
+		cfg := struct {
+			Containers []*Container `cfg:"containers"`
+		}{}
+		cfg.Containers = []*Container{{Name: "web"}, nil}
+
+		err := conf.processCfg(&cfg)
+		if err != nil {
+			t.Fatalf("processCfg() returned unexpected error: %v", err)
+		}
+		want := []*Container{
+			{Name: "web", Image: "nginx", Pull: "always"},
+			{Image: "redis", Pull: "always"},
+		}
+		if !reflect.DeepEqual(want, cfg.Containers) {
+			t.Errorf("\nwant %+v, %+v\ngot  %+v, %+v", want[0], want[1], cfg.Containers[0], cfg.Containers[1])
+		}
+	})
+
+	t.Run("slice grown by env", func(t *testing.T) {
+		conf := defaultCfg()
+		conf.tag = "cfg"
+		conf.useEnv = true
+		conf.envPrefix = "app"
+
+		os.Clearenv()
+		setenv(t, "APP_SERVERS_0_HOST", "a0")
+		setenv(t, "APP_SERVERS_1_HOST", "a1")
+		setenv(t, "APP_SERVERS_2_PORT", "9000")
+		setenv(t, "APP_SERVERS_4_HOST", "gap")
+		setenv(t, "APP_REPLICAS_0_HOST", "r0")
+
+		type Server struct {
+			Host string `cfg:"host"`
+			Port int    `cfg:"port" default:"80"`
+		}
+		cfg := struct {
+			Servers  []Server  `cfg:"servers"`
+			Replicas []*Server `cfg:"replicas"`
+		}{}
+		cfg.Servers = []Server{{Host: "file"}}
+
+		err := conf.processCfg(&cfg)
+		if err != nil {
+			t.Fatalf("processCfg() returned unexpected error: %v", err)
+		}
+		want := []Server{{Host: "a0", Port: 80}, {Host: "a1", Port: 80}, {Port: 9000}}
+		if !reflect.DeepEqual(want, cfg.Servers) {
+			t.Errorf("want %+v, got %+v", want, cfg.Servers)
+		}
+		if len(cfg.Replicas) != 1 || *cfg.Replicas[0] != (Server{Host: "r0", Port: 80}) {
+			t.Errorf("want replicas [{r0 80}], got %+v", cfg.Replicas)
+		}
+	})
+
+	t.Run("slice grown by env with IgnoreFile", func(t *testing.T) {
+		os.Clearenv()
+		setenv(t, "ITEMS_0_NAME", "a")
+		setenv(t, "ITEMS_1_NAME", "b")
+
+		var cfg struct {
+			Items []struct {
+				Name string `cfg:"name" validate:"required"`
+			} `cfg:"items"`
+		}
+		if err := Load(&cfg, IgnoreFile(), UseEnv("")); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if len(cfg.Items) != 2 || cfg.Items[0].Name != "a" || cfg.Items[1].Name != "b" {
+			t.Errorf("want items a and b, got %+v", cfg.Items)
+		}
+	})
+
 	t.Run("embedded struct set by env", func(t *testing.T) {
 		conf := defaultCfg()
 		conf.useEnv = true
@@ -865,7 +1321,7 @@ func Test_cfg_setFromEnv(t *testing.T) {
 	fv := reflect.ValueOf(&s)
 
 	os.Clearenv()
-	err := conf.setFromEnv(fv, "config.string")
+	err := conf.setFromEnv(fv, conf.formatEnvKey("config.string"), "config.string", "")
 	if err != nil {
 		t.Fatalf("setFromEnv() unexpected error: %v", err)
 	}
@@ -874,7 +1330,7 @@ func Test_cfg_setFromEnv(t *testing.T) {
 	}
 
 	setenv(t, "CFG_CONFIG_STRING", "goroutine")
-	err = conf.setFromEnv(fv, "config.string")
+	err = conf.setFromEnv(fv, conf.formatEnvKey("config.string"), "config.string", "")
 	if err != nil {
 		t.Fatalf("setFromEnv() unexpected error: %v", err)
 	}
@@ -889,6 +1345,7 @@ func Test_cfg_formatEnvKey(t *testing.T) {
 	for _, tc := range []struct {
 		key    string
 		prefix string
+		sep    string
 		want   string
 	}{
 		{
@@ -913,9 +1370,21 @@ func Test_cfg_formatEnvKey(t *testing.T) {
 			prefix: "auth_s",
 			want:   "AUTH_S_CLIENT_HTTP_TIMEOUT",
 		},
+		{
+			key:    "logger.log_level",
+			prefix: "myapp",
+			sep:    "__",
+			want:   "MYAPP__LOGGER__LOG_LEVEL",
+		},
+		{
+			key:  "loggers[0].log_level",
+			sep:  "__",
+			want: "LOGGERS__0__LOG_LEVEL",
+		},
 	} {
-		t.Run(fmt.Sprintf("%s/%s", tc.prefix, tc.key), func(t *testing.T) {
+		t.Run(fmt.Sprintf("%s/%s%s", tc.prefix, tc.key, tc.sep), func(t *testing.T) {
 			conf.envPrefix = tc.prefix
+			conf.envSep = tc.sep
 			got := conf.formatEnvKey(tc.key)
 			if got != tc.want {
 				t.Errorf("formatEnvKey() == %s, expected %s", got, tc.want)
@@ -924,12 +1393,228 @@ func Test_cfg_formatEnvKey(t *testing.T) {
 	}
 }
 
+func Test_cfg_Load_EnvTag(t *testing.T) {
+	type Config struct {
+		Database struct {
+			URL  string `cfg:"url" env:"DATABASE_URL"`
+			Pool int    `cfg:"pool"`
+		} `cfg:"database"`
+		Port    int `cfg:"port" env:"PORT"`
+		Servers []struct {
+			Host string `cfg:"host"`
+		} `cfg:"servers" env:"SERVERS"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "database:\n  url: postgres://localhost\n")
+
+	setenv(t, "DATABASE_URL", "postgres://db")
+	setenv(t, "MYAPP_DATABASE_URL", "postgres://ignored")
+	setenv(t, "MYAPP_DATABASE_POOL", "5")
+	setenv(t, "PORT", "8080")
+	setenv(t, "SERVERS", `[{"host":"a"},{"host":"b"}]`)
+
+	var cfg Config
+	res, err := LoadResult(&cfg, Dirs(dir), UseEnv("myapp"), EnvKeyFunc(func(path string) string {
+		return "MYAPP_" + strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+	}))
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if cfg.Database.URL != "postgres://db" || cfg.Database.Pool != 5 || cfg.Port != 8080 {
+		t.Errorf("unexpected cfg %+v", cfg)
+	}
+	if len(cfg.Servers) != 2 || cfg.Servers[1].Host != "b" {
+		t.Errorf("want servers from SERVERS, got %+v", cfg.Servers)
+	}
+	if got := res.Provenance["database.url"]; got != "env" {
+		t.Errorf("want database.url from env, got %s", got)
+	}
+
+	t.Run("requires UseEnv", func(t *testing.T) {
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir)); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Database.URL != "postgres://localhost" {
+			t.Errorf("want url from file, got %s", cfg.Database.URL)
+		}
+	})
+}
+
+func Test_cfg_Load_EnvFile(t *testing.T) {
+	type Config struct {
+		DB struct {
+			Password string `cfg:"password"`
+		} `cfg:"db"`
+		Token string `cfg:"token" env:"API_TOKEN"`
+		Port  int    `cfg:"port"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "db"), "hunter2\n")
+	writeFile(t, filepath.Join(dir, "token"), "abc")
+	writeFile(t, filepath.Join(dir, "port"), "8080\r\n")
+
+	setenv(t, "MYAPP_DB_PASSWORD_FILE", filepath.Join(dir, "db"))
+	setenv(t, "API_TOKEN_FILE", filepath.Join(dir, "token"))
+	setenv(t, "MYAPP_PORT_FILE", filepath.Join(dir, "port"))
+
+	var cfg Config
+	res, err := LoadResult(&cfg, IgnoreFile(), UseEnv("myapp"))
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if cfg.DB.Password != "hunter2" || cfg.Token != "abc" || cfg.Port != 8080 {
+		t.Errorf("unexpected cfg %+v", cfg)
+	}
+	if got := res.Provenance["db.password"]; got != "env" {
+		t.Errorf("want db.password from env, got %s", got)
+	}
+
+	t.Run("both set", func(t *testing.T) {
+		setenv(t, "MYAPP_PORT", "9090")
+		var cfg Config
+		if err := Load(&cfg, IgnoreFile(), UseEnv("myapp")); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		setenv(t, "MYAPP_PORT_FILE", filepath.Join(dir, "missing"))
+		var cfg Config
+		if err := Load(&cfg, IgnoreFile(), UseEnv("myapp")); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+	t.Run("max file size", func(t *testing.T) {
+		var cfg Config
+		err := Load(&cfg, IgnoreFile(), UseEnv("myapp"), MaxFileSize(4))
+		if err == nil || !strings.Contains(err.Error(), "MYAPP_DB_PASSWORD_FILE: "+ErrLimitExceeded.Error()) {
+			t.Fatalf("want limit exceeded err, got %v", err)
+		}
+	})
+}
+
+func Test_cfg_Load_SliceSep(t *testing.T) {
+	type Config struct {
+		URLs    []string `cfg:"urls" sep:";"`
+		DSNs    []string `cfg:"dsns" sep:"|" default:"host=a,b|host=c"`
+		Ports   []int    `cfg:"ports" sep:" "`
+		Default []string `cfg:"default"`
+	}
+
+	setenv(t, "URLS", "https://a.example.com/?x=1,2;https://b.example.com")
+	setenv(t, "PORTS", "[80 443]")
+	setenv(t, "DEFAULT", "a,b")
+
+	var cfg Config
+	if err := Load(&cfg, IgnoreFile(), UseEnv("")); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	want := Config{
+		URLs:    []string{"https://a.example.com/?x=1,2", "https://b.example.com"},
+		DSNs:    []string{"host=a,b", "host=c"},
+		Ports:   []int{80, 443},
+		Default: []string{"a", "b"},
+	}
+	if !reflect.DeepEqual(want, cfg) {
+		t.Errorf("\nwant %+v\ngot  %+v", want, cfg)
+	}
+
+	env := ToEnv(&cfg, "")
+	if got := env["URLS"]; got != "https://a.example.com/?x=1,2;https://b.example.com" {
+		t.Errorf("want URLS joined by ;, got %q", got)
+	}
+}
+
+func Test_cfg_Load_EnvExcluded(t *testing.T) {
+	type Config struct {
+		Audit struct {
+			Sink string `cfg:"sink"`
+		} `cfg:"audit" env:"-"`
+		Admin string `cfg:"admin" env:"-"`
+		Port  int    `cfg:"port"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "audit:\n  sink: file\nadmin: root\nport: 80\n")
+	writeFile(t, filepath.Join(dir, "config.env"), "ADMIN=dotenv\n")
+
+	setenv(t, "AUDIT_SINK", "none")
+	setenv(t, "ADMIN", "intruder")
+	setenv(t, "PORT", "8080")
+
+	var cfg Config
+	if err := Load(&cfg, Dirs(dir), Files(Required("config.yaml"), Required("config.env")), UseEnv("")); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if cfg.Audit.Sink != "file" || cfg.Admin != "root" {
+		t.Errorf("want excluded fields from file, got %+v", cfg)
+	}
+	if cfg.Port != 8080 {
+		t.Errorf("want port from env, got %d", cfg.Port)
+	}
+	if env := ToEnv(&cfg, ""); len(env) != 1 || env["PORT"] != "8080" {
+		t.Errorf("want only PORT, got %v", env)
+	}
+}
+
+func Test_cfg_Load_EnvKeyFunc(t *testing.T) {
+	type Config struct {
+		Port    int `cfg:"port"`
+		Servers []struct {
+			Host string `cfg:"host"`
+		} `cfg:"servers"`
+		LogLevel string `cfg:"log_level" default:"info"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "servers:\n  - host: a\n")
+
+	setenv(t, "PORT", "8080")
+	setenv(t, "myApp.servers[0].host", "b")
+	setenv(t, "myApp.log_level", "debug")
+	setenv(t, "MYAPP_LOG_LEVEL", "warn")
+
+	var paths []string
+	keyFunc := EnvKeyFunc(func(path string) string {
+		paths = append(paths, path)
+		if path == "port" {
+			return "PORT"
+		}
+		return "myApp." + path
+	})
+
+	var cfg Config
+	if err := Load(&cfg, Dirs(dir), UseEnv("myapp"), keyFunc); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if cfg.Port != 8080 || cfg.Servers[0].Host != "b" || cfg.LogLevel != "debug" {
+		t.Errorf("unexpected cfg %+v", cfg)
+	}
+	for _, want := range []string{"port", "servers[0].host", "log_level"} {
+		if !containsString(paths, want) {
+			t.Errorf("want %s in paths, got %v", want, paths)
+		}
+	}
+}
+
+func containsString(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
 func Test_cfg_setDefaultValue(t *testing.T) {
 	conf := defaultCfg()
 	var b bool
 	fv := reflect.ValueOf(&b).Elem()
 
-	err := conf.setDefaultValue(fv, "true")
+	err := conf.setDefaultValue(fv, "true", "")
 	if err == nil {
 		t.Fatalf("expected err")
 	}
@@ -942,7 +1627,7 @@ func Test_cfg_setValue(t *testing.T) {
 		var s *string
 		fv := reflect.ValueOf(&s)
 
-		err := conf.setValue(fv, "bat")
+		err := conf.setValue(fv, "bat", "")
 		if err != nil {
 			t.Fatalf("unexpected err: %v", err)
 		}
@@ -956,7 +1641,7 @@ func Test_cfg_setValue(t *testing.T) {
 		var slice []int
 		fv := reflect.ValueOf(&slice).Elem()
 
-		err := conf.setValue(fv, "5")
+		err := conf.setValue(fv, "5", "")
 		if err != nil {
 			t.Fatalf("unexpected err: %v", err)
 		}
@@ -970,7 +1655,7 @@ func Test_cfg_setValue(t *testing.T) {
 		var i int
 		fv := reflect.ValueOf(&i).Elem()
 
-		err := conf.setValue(fv, "-8")
+		err := conf.setValue(fv, "-8", "")
 		if err != nil {
 			t.Fatalf("unexpected err: %v", err)
 		}
@@ -984,7 +1669,7 @@ func Test_cfg_setValue(t *testing.T) {
 		var b bool
 		fv := reflect.ValueOf(&b).Elem()
 
-		err := conf.setValue(fv, "true")
+		err := conf.setValue(fv, "true", "")
 		if err != nil {
 			t.Fatalf("unexpected err: %v", err)
 		}
@@ -998,7 +1683,7 @@ func Test_cfg_setValue(t *testing.T) {
 		var b bool
 		fv := reflect.ValueOf(&b).Elem()
 
-		err := conf.setValue(fv, "αλήθεια")
+		err := conf.setValue(fv, "αλήθεια", "")
 		if err == nil {
 			t.Fatalf("returned nil err")
 		}
@@ -1008,7 +1693,7 @@ func Test_cfg_setValue(t *testing.T) {
 		var d time.Duration
 		fv := reflect.ValueOf(&d).Elem()
 
-		err := conf.setValue(fv, "5h")
+		err := conf.setValue(fv, "5h", "")
 		if err != nil {
 			t.Fatalf("unexpected err: %v", err)
 		}
@@ -1022,7 +1707,7 @@ func Test_cfg_setValue(t *testing.T) {
 		var d time.Duration
 		fv := reflect.ValueOf(&d).Elem()
 
-		err := conf.setValue(fv, "5decades")
+		err := conf.setValue(fv, "5decades", "")
 		if err == nil {
 			t.Fatalf("expexted err")
 		}
@@ -1032,7 +1717,7 @@ func Test_cfg_setValue(t *testing.T) {
 		var i uint
 		fv := reflect.ValueOf(&i).Elem()
 
-		err := conf.setValue(fv, "42")
+		err := conf.setValue(fv, "42", "")
 		if err != nil {
 			t.Fatalf("unexpected err: %v", err)
 		}
@@ -1046,7 +1731,7 @@ func Test_cfg_setValue(t *testing.T) {
 		var f float32
 		fv := reflect.ValueOf(&f).Elem()
 
-		err := conf.setValue(fv, "0.015625")
+		err := conf.setValue(fv, "0.015625", "")
 		if err != nil {
 			t.Fatalf("unexpected err: %v", err)
 		}
@@ -1060,7 +1745,7 @@ func Test_cfg_setValue(t *testing.T) {
 		var f float32
 		fv := reflect.ValueOf(&f).Elem()
 
-		err := conf.setValue(fv, "-i")
+		err := conf.setValue(fv, "-i", "")
 		if err == nil {
 			t.Fatalf("expected err")
 		}
@@ -1070,7 +1755,7 @@ func Test_cfg_setValue(t *testing.T) {
 		var s string
 		fv := reflect.ValueOf(&s).Elem()
 
-		err := conf.setValue(fv, "bat")
+		err := conf.setValue(fv, "bat", "")
 		if err != nil {
 			t.Fatalf("unexpected err: %v", err)
 		}
@@ -1084,7 +1769,7 @@ func Test_cfg_setValue(t *testing.T) {
 		var tme time.Time
 		fv := reflect.ValueOf(&tme).Elem()
 
-		err := conf.setValue(fv, "2020-01-01T00:00:00Z")
+		err := conf.setValue(fv, "2020-01-01T00:00:00Z", "")
 		if err != nil {
 			t.Fatalf("unexpected err: %v", err)
 		}
@@ -1103,7 +1788,7 @@ func Test_cfg_setValue(t *testing.T) {
 		var tme time.Time
 		fv := reflect.ValueOf(&tme).Elem()
 
-		err := conf.setValue(fv, "2020-Feb-01T00:00:00Z")
+		err := conf.setValue(fv, "2020-Feb-01T00:00:00Z", "")
 		if err == nil {
 			t.Fatalf("expected err")
 		}
@@ -1113,7 +1798,7 @@ func Test_cfg_setValue(t *testing.T) {
 		var re regexp.Regexp
 		fv := reflect.ValueOf(&re).Elem()
 
-		err := conf.setValue(fv, "[a-z]+")
+		err := conf.setValue(fv, "[a-z]+", "")
 		if err != nil {
 			t.Fatalf("unexpected err: %v", err)
 		}
@@ -1127,7 +1812,7 @@ func Test_cfg_setValue(t *testing.T) {
 		var re regexp.Regexp
 		fv := reflect.ValueOf(&re).Elem()
 
-		err := conf.setValue(fv, "[a-")
+		err := conf.setValue(fv, "[a-", "")
 		if err == nil {
 			t.Fatalf("expected err")
 		}
@@ -1137,7 +1822,7 @@ func Test_cfg_setValue(t *testing.T) {
 		var i interface{}
 		fv := reflect.ValueOf(i)
 
-		err := conf.setValue(fv, "empty")
+		err := conf.setValue(fv, "empty", "")
 		if err == nil {
 			t.Fatalf("expected err")
 		}
@@ -1147,7 +1832,7 @@ func Test_cfg_setValue(t *testing.T) {
 		s := struct{ Name string }{}
 		fv := reflect.ValueOf(&s).Elem()
 
-		err := conf.setValue(fv, "foo")
+		err := conf.setValue(fv, "foo", "")
 		if err == nil {
 			t.Fatalf("expected err")
 		}
@@ -1217,11 +1902,26 @@ func Test_cfg_setSlice(t *testing.T) {
 			},
 			Val: "[[a-z]+,.*]",
 		},
+		{
+			Name:      "duration pointers",
+			InSlice:   &[]*time.Duration{},
+			WantSlice: &[]*time.Duration{durationPtr(time.Hour + 30*time.Minute + 5*time.Second), durationPtr(2 * time.Minute)},
+			Val:       "[1h30m5s,2m]",
+		},
+		{
+			Name:    "time pointers",
+			InSlice: &[]*time.Time{},
+			WantSlice: &[]*time.Time{
+				timePtr(time.Date(2019, 12, 25, 10, 30, 30, 0, time.UTC)),
+				timePtr(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+			},
+			Val: "[2019-12-25T10:30:30Z,2020-01-01T00:00:00Z]",
+		},
 	} {
 		t.Run(tc.Val, func(t *testing.T) {
 			in := reflect.ValueOf(tc.InSlice).Elem()
 
-			err := f.setSlice(in, tc.Val)
+			err := f.setSlice(in, tc.Val, "")
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -1238,13 +1938,17 @@ func Test_cfg_setSlice(t *testing.T) {
 		in := &[]uint{}
 		val := "[-5]"
 
-		err := f.setSlice(reflect.ValueOf(in).Elem(), val)
+		err := f.setSlice(reflect.ValueOf(in).Elem(), val, "")
 		if err == nil {
 			t.Fatalf("expected err")
 		}
 	})
 }
 
+func durationPtr(d time.Duration) *time.Duration { return &d }
+
+func timePtr(t time.Time) *time.Time { return &t }
+
 func setenv(t *testing.T, key, value string) {
 	t.Helper()
 	t.Setenv(key, value)
@@ -0,0 +1,39 @@
+package cfg
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func Test_cfg_Load_Clock(t *testing.T) {
+	type Config struct {
+		Started time.Time `cfg:"started" default:"now"`
+		Built   time.Time `cfg:"built"`
+	}
+
+	fixed := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	fsys := fstest.MapFS{"config.yaml": {Data: []byte("built: 2019-12-25T00:00:00Z\n")}}
+
+	var cfg Config
+	if err := Load(&cfg, FS(fsys), Clock(func() time.Time { return fixed })); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if !cfg.Started.Equal(fixed) {
+		t.Errorf("want started %v, got %v", fixed, cfg.Started)
+	}
+	if want := time.Date(2019, 12, 25, 0, 0, 0, 0, time.UTC); !cfg.Built.Equal(want) {
+		t.Errorf("want built %v, got %v", want, cfg.Built)
+	}
+
+	t.Run("defaults to time.Now", func(t *testing.T) {
+		before := time.Now()
+		var cfg Config
+		if err := Load(&cfg, FS(fsys)); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Started.Before(before) || cfg.Started.After(time.Now()) {
+			t.Errorf("want started around now, got %v", cfg.Started)
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package cfg
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// selectSubCommand replaces the contents of vals with the section of the
+// configured sub-command, if any. vals is left empty if the file has no
+// such section.
+func (f *cfg) selectSubCommand(vals map[string]interface{}) error {
+	if f.subCommand == "" {
+		return nil
+	}
+
+	var section map[string]interface{}
+	cur := vals
+	keys := strings.Split(f.subCommand, ".")
+	for i, key := range keys {
+		next, ok := cur[key]
+		if !ok {
+			break
+		}
+		m, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: must be a map", strings.Join(keys[:i+1], "."))
+		}
+		if i == len(keys)-1 {
+			section = m
+		}
+		cur = m
+	}
+
+	for k := range vals {
+		delete(vals, k)
+	}
+	for k, v := range section {
+		vals[k] = v
+	}
+	return nil
+}
+
+// setFlags returns the values of the flags of fs that were set on the
+// command line, by name.
+func setFlags(fs *flag.FlagSet) map[string]string {
+	vals := make(map[string]string)
+	fs.Visit(func(fl *flag.Flag) {
+		vals[fl.Name] = fl.Value.String()
+	})
+	return vals
+}
@@ -0,0 +1,91 @@
+package cfg
+
+import (
+	"flag"
+	"io"
+	"path/filepath"
+	"testing"
+)
+
+func Test_cfg_Load_SubCommand(t *testing.T) {
+	type ServeConfig struct {
+		Host string `cfg:"host" default:"localhost"`
+		Port int    `cfg:"port"`
+		TLS  bool   `cfg:"tls"`
+	}
+	type MigrateConfig struct {
+		Dir string `cfg:"dir"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), `
+version: 1
+commands:
+  serve:
+    host: 0.0.0.0
+    port: 8080
+  migrate:
+    dir: migrations
+`)
+
+	t.Run("sections", func(t *testing.T) {
+		var serve ServeConfig
+		if err := Load(&serve, Dirs(dir), SubCommand("commands.serve"), UseStrict()); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if want := (ServeConfig{Host: "0.0.0.0", Port: 8080}); serve != want {
+			t.Errorf("\nwant %+v\ngot  %+v", want, serve)
+		}
+
+		var migrate MigrateConfig
+		if err := Load(&migrate, Dirs(dir), SubCommand("commands.migrate"), UseStrict()); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if migrate.Dir != "migrations" {
+			t.Errorf("want dir migrations, got %q", migrate.Dir)
+		}
+	})
+
+	t.Run("flag and env overrides", func(t *testing.T) {
+		setenv(t, "MYAPP_COMMANDS_SERVE_PORT", "9090")
+		setenv(t, "MYAPP_COMMANDS_SERVE_HOST", "127.0.0.1")
+
+		fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		fs.String("host", "", "")
+		fs.Bool("tls", false, "")
+		fs.Int("port", 0, "")
+		if err := fs.Parse([]string{"-tls", "-host", "example.com"}); err != nil {
+			t.Fatal(err)
+		}
+
+		var serve ServeConfig
+		res, err := LoadResult(&serve, Dirs(dir), SubCommand("commands.serve"), UseEnv("myapp"), Flags(fs))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if want := (ServeConfig{Host: "example.com", Port: 9090, TLS: true}); serve != want {
+			t.Errorf("\nwant %+v\ngot  %+v", want, serve)
+		}
+		if res.Provenance["host"] != "flag" || res.Provenance["port"] != "env" {
+			t.Errorf("unexpected provenance %v", res.Provenance)
+		}
+	})
+
+	t.Run("missing section", func(t *testing.T) {
+		var serve ServeConfig
+		if err := Load(&serve, Dirs(dir), SubCommand("commands.nope")); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if want := (ServeConfig{Host: "localhost"}); serve != want {
+			t.Errorf("\nwant %+v\ngot  %+v", want, serve)
+		}
+	})
+
+	t.Run("section is not a map", func(t *testing.T) {
+		var serve ServeConfig
+		if err := Load(&serve, Dirs(dir), SubCommand("version")); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package cfg
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Decompressor returns a reader of the decompressed contents of r.
+type Decompressor func(r io.Reader) (io.Reader, error)
+
+// compression is a format of compressed config files.
+type compression struct {
+	magic      []byte // the bytes compressed contents start with.
+	decompress Decompressor
+}
+
+var (
+	compressionsMu sync.RWMutex
+	compressions   = map[string]compression{
+		".gz":  {magic: []byte{0x1f, 0x8b}, decompress: gunzip},
+		".zst": {magic: []byte{0x28, 0xb5, 0x2f, 0xfd}, decompress: unsupportedZstd},
+	}
+)
+
+// RegisterDecompressor registers the decompressor of config files with the
+// extension ext, e.g. `.zst`, or whose contents start with magic.
+// Compressed files are named after the file they were compressed from,
+// e.g. `routes.yaml.zst`, and are decoded by the decoder of that file once
+// decompressed. Registering an extension that is already registered
+// replaces the previous decompressor.
+//
+// Only gzip (`.gz`) is decompressed without extra setup. zstd (`.zst`)
+// files are recognized, but loading them fails until a decompressor is
+// registered, e.g. that of github.com/klauspost/compress:
+//
+//	cfg.RegisterDecompressor(".zst", []byte{0x28, 0xb5, 0x2f, 0xfd}, func(r io.Reader) (io.Reader, error) {
+//	  return zstd.NewReader(r)
+//	})
+func RegisterDecompressor(ext string, magic []byte, fn Decompressor) {
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	compressionsMu.Lock()
+	defer compressionsMu.Unlock()
+	compressions[ext] = compression{magic: magic, decompress: fn}
+}
+
+// decompress returns a reader of the decompressed contents of the file
+// name read from rc if the file is compressed, by its extension or else
+// by its leading bytes, or else of its contents as they are.
+func decompress(rc io.ReadCloser, name string) (io.ReadCloser, error) {
+	compressionsMu.RLock()
+	defer compressionsMu.RUnlock()
+
+	if c, ok := compressions[filepath.Ext(name)]; ok {
+		return decompressWith(c, rc, rc)
+	}
+
+	br := bufio.NewReader(rc)
+	for _, c := range compressions {
+		if len(c.magic) == 0 {
+			continue
+		}
+		if head, _ := br.Peek(len(c.magic)); bytes.Equal(head, c.magic) {
+			return decompressWith(c, br, rc)
+		}
+	}
+	return readCloser{Reader: br, Closer: rc}, nil
+}
+
+// decompressWith decompresses r with c. c closes the file.
+func decompressWith(c compression, r io.Reader, closer io.Closer) (io.ReadCloser, error) {
+	dr, err := c.decompress(r)
+	if err != nil {
+		closer.Close()
+		return nil, err
+	}
+	return readCloser{Reader: dr, Closer: closer}, nil
+}
+
+// fileExt returns the extension of the file name, ignoring the extension
+// of its compression, if any, e.g. `.yaml` for `routes.yaml.gz`.
+func fileExt(name string) string {
+	ext := filepath.Ext(name)
+	compressionsMu.RLock()
+	_, ok := compressions[ext]
+	compressionsMu.RUnlock()
+	if ok {
+		return filepath.Ext(strings.TrimSuffix(name, ext))
+	}
+	return ext
+}
+
+// readCloser reads from Reader and closes Closer.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func gunzip(r io.Reader) (io.Reader, error) {
+	return gzip.NewReader(r)
+}
+
+// unsupportedZstd is the decompressor of zstd files until one is
+// registered, as only gzip is decompressed without extra setup.
+func unsupportedZstd(io.Reader) (io.Reader, error) {
+	return nil, fmt.Errorf("zstd compressed files are not supported without extra setup: register a decompressor with RegisterDecompressor")
+}
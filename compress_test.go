@@ -0,0 +1,125 @@
+package cfg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeGzip(t *testing.T, path, content string) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_cfg_Load_Compressed(t *testing.T) {
+	type Config struct {
+		Host  string `cfg:"host"`
+		Port  int    `cfg:"port"`
+		Token string `cfg:"token"`
+	}
+
+	t.Run("by extension", func(t *testing.T) {
+		dir := t.TempDir()
+		writeGzip(t, filepath.Join(dir, "routes.yaml.gz"), "host: localhost\nport: 8080\n")
+		writeGzip(t, filepath.Join(dir, "secrets.env.gz"), "TOKEN=abc\n")
+
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir), Files(Required("routes.yaml.gz"), Required("secrets.env.gz"))); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Host != "localhost" || cfg.Port != 8080 || cfg.Token != "abc" {
+			t.Errorf("unexpected cfg %+v", cfg)
+		}
+	})
+
+	t.Run("by magic bytes", func(t *testing.T) {
+		dir := t.TempDir()
+		writeGzip(t, filepath.Join(dir, "config.yaml"), "host: localhost\n")
+
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir)); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Host != "localhost" {
+			t.Errorf("want host localhost, got %q", cfg.Host)
+		}
+	})
+
+	t.Run("max size applies once decompressed", func(t *testing.T) {
+		dir := t.TempDir()
+		writeGzip(t, filepath.Join(dir, "config.yaml.gz"), "host: "+strings.Repeat("a", 1024)+"\n")
+
+		var cfg Config
+		err := Load(&cfg, Dirs(dir), File("config.yaml.gz"), MaxFileSize(512))
+		if !errors.Is(err, ErrLimitExceeded) {
+			t.Fatalf("want ErrLimitExceeded, got %v", err)
+		}
+	})
+
+	t.Run("zstd requires a decompressor", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "config.yaml.zst"), "\x28\xb5\x2f\xfd")
+
+		var cfg Config
+		err := Load(&cfg, Dirs(dir), File("config.yaml.zst"))
+		if err == nil || !strings.Contains(err.Error(), "RegisterDecompressor") {
+			t.Fatalf("want err naming RegisterDecompressor, got %v", err)
+		}
+	})
+}
+
+func Test_RegisterDecompressor(t *testing.T) {
+	RegisterDecompressor("b64", nil, func(r io.Reader) (io.Reader, error) {
+		return base64.NewDecoder(base64.StdEncoding, r), nil
+	})
+	t.Cleanup(func() {
+		compressionsMu.Lock()
+		defer compressionsMu.Unlock()
+		delete(compressions, ".b64")
+	})
+
+	type Config struct {
+		Host string `cfg:"host"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml.b64"), base64.StdEncoding.EncodeToString([]byte("host: localhost\n")))
+
+	var cfg Config
+	if err := Load(&cfg, Dirs(dir), File("config.yaml.b64")); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if cfg.Host != "localhost" {
+		t.Errorf("want host localhost, got %q", cfg.Host)
+	}
+}
+
+func Test_fileExt(t *testing.T) {
+	for name, want := range map[string]string{
+		"config.yaml":        ".yaml",
+		"routes.yaml.gz":     ".yaml",
+		"/etc/app/.env.zst":  ".env",
+		"config.prod.toml":   ".toml",
+		"s3://bucket/a.json": ".json",
+	} {
+		if got := fileExt(name); got != want {
+			t.Errorf("fileExt(%q) == %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package cfg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/pelletier/go-toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Decoder decodes the contents of a config file read from r into vals.
+type Decoder func(r io.Reader, vals map[string]interface{}) error
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = map[string]Decoder{
+		".yaml":       decodeYAML,
+		".yml":        decodeYAML,
+		".json":       decodeJSON,
+		".json5":      decodeJSON5,
+		".toml":       decodeTOML,
+		".properties": decodeProperties,
+		".xml":        decodeXML,
+	}
+)
+
+// RegisterDecoder registers the decoder of config files with the extension
+// ext, e.g. `.hcl`. Registering an extension that is already registered
+// replaces the previous decoder, including the built-in ones.
+//
+//	cfg.RegisterDecoder(".hcl", func(r io.Reader, vals map[string]interface{}) error {
+//	  src, err := io.ReadAll(r)
+//	  if err != nil {
+//	    return err
+//	  }
+//	  return hcl.Unmarshal(src, &vals)
+//	})
+func RegisterDecoder(ext string, fn Decoder) {
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	decoders[ext] = fn
+}
+
+// lookupDecoder returns the decoder registered for the extension ext.
+func lookupDecoder(ext string) (Decoder, error) {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+	fn, ok := decoders[ext]
+	if !ok {
+		return nil, fmt.Errorf("unsupported file extension %q", ext)
+	}
+	return fn, nil
+}
+
+// decodeYAML decodes a YAML document into vals. Anchors, aliases and merge
+// keys (`<<`) are resolved by the decoder, so vals only ever holds plain
+// values.
+func decodeYAML(r io.Reader, vals map[string]interface{}) error {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+		return err
+	}
+	if err := resolveYAMLTags(&doc); err != nil {
+		return err
+	}
+	if err := doc.Decode(&vals); err != nil {
+		return err
+	}
+	yamlBinaryValues(&doc, vals)
+	return nil
+}
+
+func decodeJSON(r io.Reader, vals map[string]interface{}) error {
+	return json.NewDecoder(r).Decode(&vals)
+}
+
+func decodeTOML(r io.Reader, vals map[string]interface{}) error {
+	tree, err := toml.LoadReader(r)
+	if err != nil {
+		return err
+	}
+	for field, val := range tree.ToMap() {
+		vals[field] = val
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package cfg
+
+import (
+	"bufio"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_RegisterDecoder(t *testing.T) {
+	// decodes lines of space separated keys and values.
+	decodeLines := func(r io.Reader, vals map[string]interface{}) error {
+		s := bufio.NewScanner(r)
+		for s.Scan() {
+			if k, v, ok := strings.Cut(s.Text(), " "); ok {
+				vals[k] = v
+			}
+		}
+		return s.Err()
+	}
+	RegisterDecoder("lines", decodeLines)
+	t.Cleanup(func() {
+		decodersMu.Lock()
+		defer decodersMu.Unlock()
+		delete(decoders, ".lines")
+	})
+
+	type Config struct {
+		Host string `cfg:"host"`
+		Port int    `cfg:"port"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.lines"), "host localhost\nport 8080\n")
+
+	var cfg Config
+	if err := Load(&cfg, Dirs(dir), File("config.lines")); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if cfg.Host != "localhost" || cfg.Port != 8080 {
+		t.Errorf("unexpected cfg %+v", cfg)
+	}
+}
+
+func Test_lookupDecoder(t *testing.T) {
+	for _, ext := range []string{".yaml", ".yml", ".json", ".json5", ".toml", ".properties", ".xml"} {
+		if _, err := lookupDecoder(ext); err != nil {
+			t.Errorf("%s: unexpected err: %v", ext, err)
+		}
+	}
+	if _, err := lookupDecoder(".hcl"); err == nil {
+		t.Error("expected err for .hcl")
+	}
+}
+
+func Test_cfg_Load_YAMLAnchors(t *testing.T) {
+	type DB struct {
+		Host string `cfg:"host"`
+		Port int    `cfg:"port"`
+	}
+	type Config struct {
+		Primary DB       `cfg:"primary"`
+		Replica DB       `cfg:"replica"`
+		Backup  DB       `cfg:"backup"`
+		Hosts   []string `cfg:"hosts"`
+		Peers   []string `cfg:"peers"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), `
+replica: &db
+  host: localhost
+  port: 5432
+primary:
+  <<: *db
+  host: primary
+backup:
+  <<: [*db]
+hosts: &hosts [a, b]
+peers: *hosts
+scopes:
+  eu:
+    backup:
+      host: backup-eu
+`)
+
+	var cfg Config
+	if err := Load(&cfg, Dirs(dir), UseStrict(), Scope("eu")); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := Config{
+		Primary: DB{Host: "primary", Port: 5432},
+		Replica: DB{Host: "localhost", Port: 5432},
+		Backup:  DB{Host: "backup-eu", Port: 5432},
+		Hosts:   []string{"a", "b"},
+		Peers:   []string{"a", "b"},
+	}
+	if !reflect.DeepEqual(want, cfg) {
+		t.Errorf("\nwant %+v\ngot  %+v", want, cfg)
+	}
+}
+
+func Test_decodeYAML_Aliases(t *testing.T) {
+	vals := make(map[string]interface{})
+	err := decodeYAML(strings.NewReader("a: &a {k: v}\nb: *a\nx-c: 1\n"), vals)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if vals["x-c"] != 1 {
+		t.Error("want keys prefixed with x- to be kept")
+	}
+
+	// aliased values must not share their maps so that overlaying one of
+	// them does not alter the others.
+	vals["b"].(map[string]interface{})["k"] = "changed"
+	if vals["a"].(map[string]interface{})["k"] != "v" {
+		t.Error("aliased maps are shared")
+	}
+}
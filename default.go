@@ -0,0 +1,190 @@
+package cfg
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// isComposite reports whether t is a map, or a slice whose elements are
+// structs, maps or slices. Defaults of such types are given as a literal,
+// e.g. `[{name:a},{name:b}]` or `{a:[1,2],b:[3]}`.
+func isComposite(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Map:
+		return true
+	case reflect.Slice, reflect.Array:
+		elem := t.Elem()
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		switch elem.Kind() {
+		case reflect.Map, reflect.Slice, reflect.Array:
+			return true
+		case reflect.Struct:
+			return !isScalarStruct(elem)
+		}
+	}
+	return false
+}
+
+// isScalarStruct reports whether t is a struct type that is set from a
+// single string, such as time.Time, or from bytes, or that decodes its
+// subtree itself as a SectionDecoder.
+func isScalarStruct(t reflect.Type) bool {
+	return t == reflect.TypeOf(time.Time{}) ||
+		t == reflect.TypeOf(regexp.Regexp{}) ||
+		reflect.PtrTo(t).Implements(textUnmarshalerType) ||
+		reflect.PtrTo(t).Implements(binaryUnmarshalerType) ||
+		reflect.PtrTo(t).Implements(sectionDecoderType)
+}
+
+// setComposite parses the composite literal val and decodes it into fv.
+func (f *cfg) setComposite(fv reflect.Value, val string) error {
+	lit, err := parseLiteral(val)
+	if err != nil {
+		return err
+	}
+	return f.decodeValue(lit, fv.Addr().Interface(), nil)
+}
+
+// parseLiteral parses a composite literal made up of lists (`[a,b]`),
+// maps (`{k:v,k2:v2}`) and scalars into a tree of []interface{},
+// map[string]interface{} and string values. Scalars may be quoted with
+// single or double quotes to include any of `,[]{}`, or `:` in keys.
+func parseLiteral(s string) (interface{}, error) {
+	p := &literalParser{s: s}
+	v, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.i < len(p.s) {
+		return nil, p.errorf("unexpected %q", p.s[p.i])
+	}
+	return v, nil
+}
+
+type literalParser struct {
+	s string
+	i int
+}
+
+func (p *literalParser) value() (interface{}, error) {
+	p.skipSpace()
+	if p.i >= len(p.s) {
+		return "", nil
+	}
+	switch p.s[p.i] {
+	case '[':
+		return p.list()
+	case '{':
+		return p.object()
+	default:
+		return p.scalar(",]}")
+	}
+}
+
+func (p *literalParser) list() (interface{}, error) {
+	p.i++ // [
+	list := make([]interface{}, 0)
+	for {
+		p.skipSpace()
+		if p.consume(']') {
+			return list, nil
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+
+		p.skipSpace()
+		if p.consume(']') {
+			return list, nil
+		}
+		if !p.consume(',') {
+			return nil, p.errorf("expected ',' or ']'")
+		}
+	}
+}
+
+func (p *literalParser) object() (interface{}, error) {
+	p.i++ // {
+	obj := make(map[string]interface{})
+	for {
+		p.skipSpace()
+		if p.consume('}') {
+			return obj, nil
+		}
+		key, err := p.scalar(",:[]{}")
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if !p.consume(':') {
+			return nil, p.errorf("expected ':' after key %q", key)
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		obj[key] = v
+
+		p.skipSpace()
+		if p.consume('}') {
+			return obj, nil
+		}
+		if !p.consume(',') {
+			return nil, p.errorf("expected ',' or '}'")
+		}
+	}
+}
+
+// scalar parses a quoted scalar or an unquoted one that ends at any of
+// the bytes in stop.
+func (p *literalParser) scalar(stop string) (string, error) {
+	p.skipSpace()
+	if p.i < len(p.s) && (p.s[p.i] == '"' || p.s[p.i] == '\'') {
+		quote := p.s[p.i]
+		end := strings.IndexByte(p.s[p.i+1:], quote)
+		if end < 0 {
+			return "", p.errorf("unterminated string")
+		}
+		s := p.s[p.i+1 : p.i+1+end]
+		p.i += end + 2
+		return s, nil
+	}
+
+	start := p.i
+	for p.i < len(p.s) && strings.IndexByte(stop, p.s[p.i]) < 0 {
+		p.i++
+	}
+	return strings.TrimSpace(p.s[start:p.i]), nil
+}
+
+func (p *literalParser) consume(c byte) bool {
+	if p.i < len(p.s) && p.s[p.i] == c {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *literalParser) skipSpace() {
+	for p.i < len(p.s) && (p.s[p.i] == ' ' || p.s[p.i] == '\t' || p.s[p.i] == '\n') {
+		p.i++
+	}
+}
+
+func (p *literalParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid literal %q at offset %d: %s", p.s, p.i, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,125 @@
+package cfg
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_cfg_Load_CompositeDefaults(t *testing.T) {
+	type Upstream struct {
+		Name    string        `cfg:"name"`
+		URL     string        `cfg:"url"`
+		Timeout time.Duration `cfg:"timeout" default:"5s"`
+	}
+	type Config struct {
+		Upstreams []Upstream          `cfg:"upstreams" default:"[{name:a,url:'http://a:80'},{name:b,url:http://b,timeout:1s}]"`
+		Groups    map[string][]string `cfg:"groups" default:"{admin:[alice,bob],dev:[carol]}"`
+		Weights   []map[string]int    `cfg:"weights" default:"[{a:1,b:2},{c:3}]"`
+		Matrix    [][]int             `cfg:"matrix" default:"[[1,2],[3]]"`
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "config.yaml"), "{}\n")
+
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir)); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		want := Config{
+			Upstreams: []Upstream{
+				{Name: "a", URL: "http://a:80", Timeout: 5 * time.Second},
+				{Name: "b", URL: "http://b", Timeout: time.Second},
+			},
+			Groups:  map[string][]string{"admin": {"alice", "bob"}, "dev": {"carol"}},
+			Weights: []map[string]int{{"a": 1, "b": 2}, {"c": 3}},
+			Matrix:  [][]int{{1, 2}, {3}},
+		}
+		if !reflect.DeepEqual(want, cfg) {
+			t.Errorf("\nwant %+v\ngot  %+v", want, cfg)
+		}
+	})
+
+	t.Run("file values take precedence", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "config.yaml"), "upstreams:\n  - name: c\n")
+
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir)); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		want := []Upstream{{Name: "c", Timeout: 5 * time.Second}}
+		if !reflect.DeepEqual(want, cfg.Upstreams) {
+			t.Errorf("\nwant %+v\ngot  %+v", want, cfg.Upstreams)
+		}
+	})
+}
+
+func Test_cfg_Load_CompositeDefaults_Invalid(t *testing.T) {
+	type Config struct {
+		Ports []map[string]int `cfg:"ports" default:"[{http:eighty}]"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "{}\n")
+
+	var cfg Config
+	if err := Load(&cfg, Dirs(dir)); err == nil {
+		t.Fatal("expected err")
+	}
+}
+
+func Test_parseLiteral(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want interface{}
+	}{
+		{"[]", []interface{}{}},
+		{"{}", map[string]interface{}{}},
+		{"[a, b ,c]", []interface{}{"a", "b", "c"}},
+		{"{ a : 1 , b: [x, y] }", map[string]interface{}{"a": "1", "b": []interface{}{"x", "y"}}},
+		{"[{name:a},{name:b}]", []interface{}{map[string]interface{}{"name": "a"}, map[string]interface{}{"name": "b"}}},
+		{`{"a:b":'x,y', url:http://h:1}`, map[string]interface{}{"a:b": "x,y", "url": "http://h:1"}},
+	} {
+		got, err := parseLiteral(tc.in)
+		if err != nil {
+			t.Errorf("%s: unexpected err: %v", tc.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(tc.want, got) {
+			t.Errorf("%s: want %#v, got %#v", tc.in, tc.want, got)
+		}
+	}
+
+	for _, in := range []string{"[a,b", "{a}", "{a:1", "['a", "[a]]"} {
+		if _, err := parseLiteral(in); err == nil {
+			t.Errorf("%s: expected err", in)
+		}
+	}
+}
+
+func Test_isComposite(t *testing.T) {
+	type S struct{ A int }
+	for _, tc := range []struct {
+		v    interface{}
+		want bool
+	}{
+		{[]S{}, true},
+		{[]*S{}, true},
+		{map[string]int{}, true},
+		{[][]int{}, true},
+		{[]int{}, false},
+		{[]time.Time{}, false},
+		{[]TimeRange{}, false},
+		{S{}, false},
+		{"", false},
+	} {
+		if got := isComposite(reflect.TypeOf(tc.v)); got != tc.want {
+			t.Errorf("%T: want %v, got %v", tc.v, tc.want, got)
+		}
+	}
+}
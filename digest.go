@@ -0,0 +1,53 @@
+package cfg
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// verifyDigest reads r, the contents of the file name, and checks that
+// their SHA-256 digest is one of the expected digests, if any. It returns
+// a reader of the contents it read.
+func (f *cfg) verifyDigest(r io.Reader, name string) (io.Reader, error) {
+	if f.digests == nil {
+		return r, nil
+	}
+	if f.maxFileSize > 0 {
+		r = limitReader(r, f.maxFileSize)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDigest(data, name, f.digests); err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+// checkDigest checks that the SHA-256 digest of data, the contents of the
+// file name, is one of digests, unless digests is nil.
+func checkDigest(data []byte, name string, digests map[string]bool) error {
+	if digests == nil {
+		return nil
+	}
+	sum := sha256.Sum256(data)
+	if digest := hex.EncodeToString(sum[:]); !digests[digest] {
+		return fmt.Errorf("%s: %w: got sha256 %s", name, ErrDigestMismatch, digest)
+	}
+	return nil
+}
+
+// digestsKey is the context key of the digests of the ExpectSHA256 option.
+type digestsKey struct{}
+
+// contextDigests returns the digests of the ExpectSHA256 option that ctx
+// carries, or nil if there are none.
+func contextDigests(ctx context.Context) map[string]bool {
+	digests, _ := ctx.Value(digestsKey{}).(map[string]bool)
+	return digests
+}
@@ -0,0 +1,75 @@
+package cfg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func sha256Of(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func Test_cfg_Load_ExpectSHA256(t *testing.T) {
+	type Config struct {
+		Host string `cfg:"host"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "host: localhost\n")
+	writeGzip(t, filepath.Join(dir, "routes.yaml.gz"), "host: routes\n")
+	digest := sha256Of(t, filepath.Join(dir, "config.yaml"))
+
+	t.Run("match", func(t *testing.T) {
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir), File("config.yaml"), ExpectSHA256("0000", strings.ToUpper(digest))); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Host != "localhost" {
+			t.Errorf("want host localhost, got %q", cfg.Host)
+		}
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		var cfg Config
+		err := Load(&cfg, Dirs(dir), File("config.yaml"), ExpectSHA256("0000"))
+		if !errors.Is(err, ErrDigestMismatch) {
+			t.Fatalf("want ErrDigestMismatch, got %v", err)
+		}
+		if cfg.Host != "" {
+			t.Errorf("want cfg untouched, got %+v", cfg)
+		}
+	})
+
+	t.Run("compressed files are hashed as stored", func(t *testing.T) {
+		var cfg Config
+		err := Load(&cfg, Dirs(dir), Files(Required("routes.yaml.gz")), ExpectSHA256(sha256Of(t, filepath.Join(dir, "routes.yaml.gz"))))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Host != "routes" {
+			t.Errorf("want host routes, got %q", cfg.Host)
+		}
+	})
+
+	t.Run("reader", func(t *testing.T) {
+		var cfg Config
+		err := LoadReader(&cfg, strings.NewReader("host: other\n"), "yaml", ExpectSHA256(digest))
+		if !errors.Is(err, ErrDigestMismatch) {
+			t.Fatalf("want ErrDigestMismatch, got %v", err)
+		}
+		if err := LoadReader(&cfg, strings.NewReader("host: localhost\n"), "yaml", ExpectSHA256(digest)); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+}
@@ -1,7 +1,7 @@
 /*
 package cfg loads configuration files into Go structs with extra juice for validating fields and setting defaults.
 
-Config files may be defined in yaml, json or toml format.
+Config files may be defined in yaml, json, json5, toml, xml, Java properties or dotenv format.
 
 When you call `Load()`, cfg takes the following steps:
 
@@ -66,6 +66,15 @@ Configuration
 
 Pass options as additional parameters to `Load()` to configure cfg's behaviour.
 
+`SetDefaultOptions()` sets options that apply to every subsequent `Load()` in the process, before its own options, so that a wrapper package can enforce conventions such as the tag, time layout and env prefix once:
+
+  cfg.SetDefaultOptions(cfg.Tag("config"), cfg.TimeLayout(time.RFC3339), cfg.UseEnv("acme"))
+
+A `Loader` bundles options for reuse, e.g. in a library that a platform team ships to configure the dirs, sources and policies of every service. Loaders are composed with `Chain()`, later loaders taking precedence: their single settings replace those of earlier loaders while sources, overrides and policies are merged.
+
+  app := cfg.Chain(platform.Loader, cfg.NewLoader(cfg.File("app.yaml"), cfg.UseEnv("app")))
+  err := app.Load(&conf)
+
 IgnoreFile
 
 Change the file and directories cfg searches in with `File()`.
@@ -75,9 +84,92 @@ Change the file and directories cfg searches in with `File()`.
     cfg.Dirs(".", "home/user/myapp", "/opt/myapp"),
   )
 
-Cfg searches for the file in dirs sequentially and uses the first matching file.
+Cfg searches for the file in dirs sequentially and uses the first matching file. Env vars in dirs and file names (e.g. `$HOME/.config/myapp`) are expanded when the config is loaded, as is a leading `~` or `~user` in dirs (e.g. `~/.myapp`).
+
+With `SearchParents()`, the parents of each dir are searched up to the root when the dir has no config file, e.g. for CLI tools run from nested directories of a project:
+
+  cfg.Load(&cfg, cfg.File(".myapp.yaml"), cfg.SearchParents())
+
+`UseXDG()` searches the config dirs of an app according to the XDG base directory spec: `$XDG_CONFIG_HOME/myapp` (by default `~/.config/myapp`) and the dirs of `$XDG_CONFIG_DIRS` (by default `/etc/xdg/myapp`). The files of the user dir take precedence over those of system dirs, and the files of `Dirs()` over both:
+
+  cfg.Load(&cfg, cfg.UseXDG("myapp"))
+
+`PlatformDirs()` searches the idiomatic dirs of the platform instead, `%PROGRAMDATA%\myapp` and `%APPDATA%\myapp` on Windows and the XDG dirs elsewhere, so the same binary finds its config on every platform.
+
+`FileFromEnv()` lets operators point the app at any config file at runtime through an env var, which takes precedence over `File()` and `Dirs()` when set:
+
+  cfg.Load(&cfg, cfg.FileFromEnv("MYAPP_CONFIG"), cfg.Dirs(".", "/etc/myapp"))
+
+Use `Files()` to choose the files to load in place of the default filenames, each one merged over the previous ones, and which of them must be found. Load fails if a `Required()` file is missing while an `Optional()` one is skipped. File names may be glob patterns that match every file in lexical order, e.g. for a drop-in `conf.d` directory:
+
+  cfg.Load(&cfg,
+    cfg.Files(cfg.Required("config.yaml"), cfg.Optional("secret.yaml"), cfg.Optional("conf.d/*.yaml")),
+    cfg.Dirs("/etc/myapp"),
+  )
+
+Files found in several dirs, or with several extensions such as `config.yaml` and `config.yml`, are merged in search order. To catch stale copies, `DetectAmbiguity()` reports all matched paths as a warning with `cfg.AmbiguityWarn`, or fails with an error wrapping `ErrAmbiguousFiles` with `cfg.AmbiguityError`:
+
+  cfg.Load(&cfg, cfg.Dirs(".", "/etc/myapp"), cfg.DetectAmbiguity(cfg.AmbiguityError))
+
+Files are read from the OS file system unless another one is given with `FS()`, e.g. an `embed.FS`:
+
+  //go:embed config
+  var configFS embed.FS
+
+  cfg.Load(&cfg, cfg.FS(configFS), cfg.Dirs("config"))
+
+To load a config from any other stream, e.g. stdin, use `LoadReader()` along with the extension of its format:
+
+  err := cfg.LoadReader(&conf, os.Stdin, "yaml")
+
+Configs already held in memory, e.g. fetched from a secret store, are loaded with `LoadBytes()`.
+
+Files in object storage are loaded by passing their URL to `File()`, e.g. to stage per-tenant configs in a bucket:
+
+  cfg.Load(&cfg, cfg.File("s3://configs/tenants/${TENANT}.yaml"))
+
+S3 objects are requested with the credentials and endpoint of the usual `AWS_*` env vars. Google Cloud Storage objects (`gs://bucket/object`) are requested with a token of the metadata server, or from the emulator of `STORAGE_EMULATOR_HOST`. Only objects that are not found (404) count as missing files; other failures, such as denied access, fail the load.
+
+The decoder (yaml/json/json5/toml/xml/properties/env) used is picked based on the file's extension. Decoders for other extensions can be plugged in with `RegisterDecoder()`:
+
+  cfg.RegisterDecoder(".hcl", func(r io.Reader, vals map[string]interface{}) error {
+    src, err := io.ReadAll(r)
+    if err != nil {
+      return err
+    }
+    return hcl.Unmarshal(src, &vals)
+  })
+
+Compressed files, e.g. `routes.yaml.gz`, are decompressed before they're decoded by the decoder of the extension that precedes that of their compression. gzip files are also recognized by their leading bytes whatever their name. Only gzip works without extra setup: zstd (`.zst`) files are recognized, but loading them fails until a decompressor is plugged in with `RegisterDecompressor()`, since cfg does not depend on a zstd library:
+
+  cfg.RegisterDecompressor(".zst", []byte{0x28, 0xb5, 0x2f, 0xfd}, func(r io.Reader) (io.Reader, error) {
+    return zstd.NewReader(r)
+  })
+
+Anchors, aliases and merge keys (`<<`) in yaml files are resolved before the file is decoded, so they work with strict parsing:
+
+  replica: &db
+    host: localhost
+    port: 5432
+  primary:
+    <<: *db
+    host: primary
 
-The decoder (yaml/json/toml) used is picked based on the file's extension.
+`!!binary` values in yaml files can be decoded into `[]byte` fields. Handlers for custom yaml tags are registered with `RegisterYAMLTag()`:
+
+  cfg.RegisterYAMLTag("!vault", func(path string) (interface{}, error) {
+    return vault.Read(path)
+  })
+
+  # config.yaml
+  password: !vault secret/db
+
+Dotted keys in properties files (e.g. `server.port=8080`) are expanded into nested sections.
+
+The children and attributes of the root element of xml files are decoded alike, with repeated elements decoded as lists.
+
+Variables in dotenv files (e.g. `.env`) are mapped onto fields using the same key rules as the environment (see below),
+so `SERVER_HOST=localhost` sets the field `Server.Host`. Variables in the environment take precedence over those in dotenv files.
 
 Tag
 
@@ -122,6 +214,28 @@ environment variables:
   MYAPP_LOG_LEVEL
   MYAPP_SERVER_HOST
 
+An `env` key in the field tag binds the field to an env var of a fixed, well-known name, regardless of the prefix and of the field's path:
+
+  type Config struct {
+    DatabaseURL string `cfg:"database_url" env:"DATABASE_URL"`
+  }
+
+Following the convention of Docker secrets, a field whose env var is unset is read from the file named by the same env var suffixed with `_FILE`, if set, e.g. `MYAPP_DB_PASSWORD_FILE=/run/secrets/db`. Trailing newlines are trimmed, and setting both env vars is an error.
+
+Fields tagged `env:"-"`, and any fields nested in them, are never read from the environment, e.g. values that must only come from an audited config file:
+
+  type Config struct {
+    AuditSink string `cfg:"audit_sink" env:"-"`
+  }
+
+A misspelled env var, e.g. `MYAPP_SERVER_PRT`, is ignored like any other. With `StrictEnv()`, env vars under the prefix that no field reads fail the load with an error wrapping `ErrUnusedEnv` that names them and suggests the closest known env var.
+
+`EnvSeparator()` changes the separator, e.g. to `__` so that nesting is told apart from field names that contain underscores: `MYAPP__LOGGER__LOG_LEVEL`.
+
+To keep an existing naming convention, `EnvKeyFunc()` maps each field's path (e.g. `server.host`) to the name of its env var in place of this format, without the prefix:
+
+  cfg.Load(&cfg, cfg.UseEnv(""), cfg.EnvKeyFunc(func(path string) string { return "myApp." + path }))
+
 Fields contained in struct slices whose elements already exists can be also be set via the environment in the form PARENT_IDX_FIELD, where idx is the index of the field in the slice.
 
   type Config struct {
@@ -136,7 +250,23 @@ With the config above individual servers may be configured with the following en
   MYAPP_SERVER_1_HOST
   ...
 
-Note: the Server slice must already have members inside it (i.e. from loading of the configuration file) for the containing fields to be altered via the environment. cfg will not instantiate and insert elements into the slice.
+Elements past the end of the slice are appended for as long as env vars set fields of the next index, so that with `IgnoreFile()` a list can be specified by env vars alone. Indices must be contiguous: with `MYAPP_SERVER_0_HOST` and `MYAPP_SERVER_2_HOST` set and no config file, the slice holds a single server. Nil elements of slices of struct pointers are allocated so that their fields can be set.
+
+Maps and slices of structs are set as a whole from JSON, or from the literal syntax of defaults. Their values are checked against the field's type, and defaults and required validations apply to the new elements, so that errors name the path of each invalid value:
+
+  MYAPP_SERVER='[{"host": "a"}, {"host": "b"}]'
+  MYAPP_LABELS='{"team": "core"}'
+
+Likewise, a struct is set as a whole by a JSON object, so that a subtree of the config can be injected through one env var. The env vars of its own fields still apply on top:
+
+  MYAPP_DATABASE='{"host": "db", "port": 5432}'
+  MYAPP_DATABASE_PORT=6432
+
+`ToEnv()` does the reverse: it renders a loaded config as the env vars that `UseEnv()` reads, e.g. for child processes that are configured by env vars only:
+
+  env := cfg.ToEnv(&conf, "myapp") // map[MYAPP_LOG_LEVEL:info MYAPP_SERVER_HOST:localhost ...]
+
+Fields tagged `secret:"true"` are left out, including the credentials of the built-in sections such as `Database.Password` and the inline PEM of `TLS`.
 
 Time
 
@@ -154,10 +284,93 @@ Change the layout cfg uses to parse times using `TimeLayout()`.
 
 By default cfg parses time using the `RFC.3339` layout (`2006-01-02T15:04:05Z07:00`).
 
+The value `now` in the environment or a default sets a time field to the current time, as given by the clock set with `Clock()` or else `time.Now()`:
+
+  type Config struct {
+    Started time.Time `cfg:"started" default:"now"`
+  }
+
+  cfg.Load(&cfg, cfg.Clock(func() time.Time { return fixed }))
+
+Types
+
+Fields whose type implements `encoding.TextUnmarshaler` are decoded from strings in the config file, the environment and defaults by calling `UnmarshalText`.
+
+Fields whose type implements `encoding.BinaryUnmarshaler` (and not `encoding.TextUnmarshaler`) are decoded by calling `UnmarshalBinary` with the bytes of YAML `!!binary` values, or with the base64 decoded value of strings and env vars:
+
+  key: !!binary c2VjcmV0 # or key: c2VjcmV0, MYAPP_KEY=c2VjcmV0
+
+Cfg ships with a few such types for values commonly found in configuration:
+
+  type Config struct {
+    Business    cfg.TimeRange `cfg:"business" default:"09:00-17:00"`
+    Maintenance cfg.Window    `cfg:"maintenance" default:"Sat,Sun 02:00-04:00"`
+    Sampling    cfg.Percent   `cfg:"sampling" default:"10%"` // or `default:"0.1"`
+    Upstream    cfg.HostPort  `cfg:"upstream" default:"localhost:5432"`
+    Listen      cfg.ListenAddr `cfg:"listen" default:":8080"` // or e.g. `default:"unix:/run/app.sock"`
+    Limit       cfg.Rate      `cfg:"limit" default:"100/s burst=20"` // or e.g. `default:"300/10s"`
+  }
+
+Sections
+
+Cfg ships with reusable sections for settings that most services share. Each section is validated when the config is loaded.
+
+  type Config struct {
+    Server struct {
+      TLS cfg.TLS `cfg:"tls"` // cert_file, key_file, ca_file, min_version, ...
+    } `cfg:"server"`
+  }
+
+  tlsConfig, err := conf.Server.TLS.Build()
+
+Similarly `cfg.Database` holds database connection settings and builds a connection string with `DSN()`,
+`cfg.Logging` holds logger settings and creates a `*slog.Logger` with `NewLogger()` (Go 1.21+),
+`cfg.HTTPServer` configures an `*http.Server` with `Apply()` and `cfg.HTTPClient` creates an `*http.Client` with `Build()`.
+
+Any field whose type implements the `Validator` interface is validated in the same way, with errors reported under the field's path.
+
+A field whose shape is too dynamic for a static struct can implement `SectionDecoder` to decode its raw subtree itself. `Decode` is called with the subtree of each file and source that sets the field, in load order, or with the JSON object of an env var or default. The provenance of each value of the subtree is still recorded under its path.
+
+  func (r *Routes) Decode(vals map[string]interface{}) error {
+    for name, route := range vals {
+      r.byName[name] = route
+    }
+    return nil
+  }
+
 # Strict Parsing
 
 By default cfg ignores any fields in the config file that are not present in the struct. This behaviour can be changed using `UseStrict()` to achieve strict parsing.
 When strict parsing is enabled, extra fields in the config file will cause an error.
+An unknown key that is a typo of a field's key suggests it, e.g. `unknown key 'log_lvl', did you mean 'log_level'?`.
+
+Values are otherwise weakly typed, e.g. `port: "8080"` decodes into an int field. A `strictType:"true"` key in the field tag rejects such values for number, bool and string fields whose values must be typed, such as ports and IDs:
+
+  type Config struct {
+    Port int `cfg:"port" strictType:"true"` // port: "8080" is an error
+  }
+
+Formats without types, such as .properties and XML files, can't set such fields.
+
+Migrations
+
+Config files written for an older version of the struct can be upgraded before they are decoded using `Migrations()`.
+The version of a file is read from its top-level `version` key and every migration newer than it is run in order.
+
+  cfg.Load(&cfg, cfg.Migrations(
+    cfg.Migration{Version: 2, Migrate: func(vals map[string]interface{}) error {
+      vals["log_level"] = vals["loglevel"]
+      delete(vals, "loglevel")
+      return nil
+    }},
+  ))
+
+The schema version expected by the struct can be enforced with `SchemaVersion()` or a `cfgversion` tag on one of its top-level fields.
+Files declaring a different version, after migrations have run, result in a wrapped `ErrVersionMismatch`.
+
+  type Config struct {
+    Version int `cfg:"version" cfgversion:"2"`
+  }
 
 Required
 
@@ -176,7 +389,7 @@ Cfg uses the following properties to check if a field is set:
   time.Time:             !time.IsZero()
   time.Duration:         != 0
 
-  *pointers to non-struct types (with the exception of time.Time) are de-referenced if they are non-nil and then checked
+  *pointers to non-struct types (with the exception of time.Time) are de-referenced if they are non-nil and then checked, whether they were set by a config file, env vars or flags. Non-nil pointers to bools are always set.
 
 See example below to help understand:
 
@@ -227,10 +440,12 @@ Cfg attempts to parse the value based on the field's type. If parsing fails then
 A default value can be set for the following types:
 
   all basic types except bool and complex
+  *bool
   time.Time
   time.Duration
   *regexp.Regexp
   slices (of above types)
+  maps and slices of structs, maps or slices
 
 Successive elements of slice defaults should be separated by a comma. The entire slice can optionally be enclosed in square brackets:
 
@@ -238,7 +453,49 @@ Successive elements of slice defaults should be separated by a comma. The entire
     Durations []time.Duration `default:"[30m,1h,90m,2h]"` // or `default:"30m,1h,90m,2h"`
   }
 
-Note: the default setter knows if it should fill a field or not by comparing if the current value of the field is equal to the corresponding zero value for that field's type. This happens after the configuration is loaded and has the implication that the zero value set explicitly by the user will get overwritten by any default value registered for that field. It's for this reason that defaults on booleans are not permitted, as a boolean field with a default value of `true` would always be true (since if it were set to false it'd be overwritten).
+For elements that contain commas, such as URLs with query params or DSNs, the `sep` tag sets another separator, which also applies to the values of env vars, flags and overrides:
+
+  type Config struct {
+    Mirrors []string `cfg:"mirrors" sep:";" default:"https://a.example.com/?x=1,2;https://b.example.com"`
+  }
+
+Defaults of maps and of slices of structs, maps or slices are given as a literal of lists and `key:value` maps. Values that contain any of `,[]{}` can be quoted. The fields of defaulted structs get their own defaults applied:
+
+  type Config struct {
+    Upstreams []Upstream          `default:"[{name:a,url:'http://a:80'},{name:b,url:'http://b:80'}]"`
+    Groups    map[string][]string `default:"{admin:[alice,bob],dev:[carol]}"`
+  }
+
+Note: the default setter knows if it should fill a field or not by comparing if the current value of the field is equal to the corresponding zero value for that field's type. This happens after the configuration is loaded and has the implication that the zero value set explicitly by the user will get overwritten by any default value registered for that field. It's for this reason that defaults on booleans are not permitted, as a boolean field with a default value of `true` would always be true (since if it were set to false it'd be overwritten). Use a *bool instead, which is only defaulted when it is nil:
+
+  type Config struct {
+    Compress *bool `cfg:"compress" default:"true"`
+  }
+
+Pointers to other types are dereferenced and defaulted like their values.
+
+Transform
+
+A transform key in the field tag normalizes the field's value after it is loaded and before it is validated.
+Transformers are applied in order and may be used on string fields and slices of strings.
+
+  type Config struct {
+    Level string `cfg:"level" transform:"trimspace,lower"`
+  }
+
+The transformers `trimspace`, `lower` and `upper` are built in. Additional transformers can be added with `RegisterTransformer()`.
+
+Path
+
+A path key set to true in the field tag makes cfg canonicalize the field's value once it is loaded and defaulted.
+A leading `~` or `~user` is expanded to the home directory of the current user or of user, relative paths are resolved against the directory of the config file and the result is cleaned.
+
+  type Config struct {
+    DataDir string `cfg:"data_dir" path:"true" default:"data"` // e.g. /etc/myapp/data
+  }
+
+Fields can also be designated as paths without a tag by passing their paths to `PathFields()`.
+The directory that relative paths were resolved against is reported in the `Result` returned by `LoadResult()`.
 
 Mutual exclusion
 
@@ -250,6 +507,295 @@ This is not allowed:
     Level string `validate:"required" default:"warn"` // will result in an error
   }
 
+Source
+
+Restrict where the value of a field may come from with the `source` tag, a comma separated list of `file`, `env`, `flag`, `override` and `source` (any source given with `WithSources()`). Load returns an error if the field, or any of its nested fields, is set by another origin, e.g. a secret written to a plain config file even if env overrides it. Dotenv files count as files and defaults are always allowed.
+
+  type Config struct {
+    Password string `cfg:"password" source:"env,source"`
+  }
+
+Limits
+
+When loading untrusted or generated files, `MaxFileSize()`, `MaxKeys()` and `MaxDepth()` protect against pathological configs. Exceeding a limit returns an error wrapping `ErrLimitExceeded`. `LoadTimeout()` bounds the time spent reading files and sources.
+
+  err := cfg.Load(&conf, cfg.MaxFileSize(1<<20), cfg.MaxKeys(10000), cfg.MaxDepth(16), cfg.LoadTimeout(5*time.Second))
+
+`LoadContext()` loads the config under a context, e.g. that of a request or of the shutdown of the service. Sources receive the context so that remote fetches respect its cancellation and deadline.
+
+  err := cfg.LoadContext(ctx, &conf, cfg.WithSources(src))
+
+For immutable deployments, `ExpectSHA256()` pins the config files, including object URLs, to digests baked into the image or manifest. A file whose SHA-256 digest doesn't match fails to load with an error wrapping `ErrDigestMismatch`:
+
+  err := cfg.Load(&conf, cfg.File("s3://configs/myapp.yaml"), cfg.ExpectSHA256("9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"))
+
+The archives of `ArchiveSource` are checked as well. The values of other sources and the files of `_FILE` env vars are not.
+
+Sources
+
+Values can be provided by other means than files, e.g. a database, an API or memory, by implementing the `Source` interface. Use `WithSources()` to read them after the config files. Their values override those of the files and go through the same defaults, env and validation steps.
+
+  err := cfg.Load(&conf, cfg.WithSources(cfg.MapSource{"host": "example.com"}, myAPISource))
+
+`EtcdSource` reads a config file stored under an etcd v3 key, or maps the keys under a prefix onto nested fields (e.g. `/myapp/server/port` onto `server.port`):
+
+  err := cfg.Load(&conf, cfg.WithSources(&cfg.EtcdSource{Endpoint: "http://etcd:2379", Key: "/myapp/", Prefix: true}))
+
+`EtcdSource` is also a `WritableSource`, whose `Put()` writes a value back with a compare-and-swap that fails with `ErrConflict` if the value changed since it was read, so that admin tooling can edit live config safely:
+
+  err := src.Put(ctx, "server.port", 9090)
+
+Use `Retry()` so that transient network failures at startup don't fail the load. Each source is read up to the given number of attempts, with a backoff that doubles after each failed attempt:
+
+  err := cfg.Load(&conf, cfg.WithSources(src), cfg.Retry(5, 100*time.Millisecond))
+
+Use `WithHTTPClient()` to fetch config files from object URLs, and to read the sources of this package that have no client of their own, with a custom `*http.Client`, e.g. one with a client certificate for mTLS-protected endpoints. The client is only used with endpoints you configure, such as that of `AWS_ENDPOINT_URL_S3` or of a source: the default endpoints of cloud providers and metadata servers are requested with `http.DefaultClient`, so that the credentials of the client don't leak to them. A `cfg.HTTPClient` section with TLS settings and headers builds one:
+
+  client, err := bootstrap.Upstream.Build()
+  err = cfg.Load(&conf, cfg.WithSources(src), cfg.WithHTTPClient(client))
+
+Requests follow the proxy of the `HTTP_PROXY`, `HTTPS_PROXY` and `NO_PROXY` env vars, unless the client says otherwise, except those to metadata servers, which are never proxied. The HTTP sources of this package take a `Proxy` to use another proxy for their requests:
+
+  src := &cfg.EtcdSource{Endpoint: "http://etcd.internal:2379", Key: "/myapp/config", Proxy: "http://egress.internal:3128"}
+
+They also take a `TLS` config, e.g. with a client certificate and the CAs of mTLS-only endpoints, which a `cfg.TLS` section builds:
+
+  conf, err := bootstrap.Etcd.TLS.Build()
+  src := &cfg.EtcdSource{Endpoint: "https://etcd.internal:2379", Key: "/myapp/config", TLS: conf}
+
+With `SourceCache()`, the values of each successful read of a source are persisted to disk and used in place of the source while it is unreachable, so that services can restart during an outage of a config server:
+
+  err := cfg.Load(&conf, cfg.WithSources(src), cfg.SourceCache("/var/cache/myapp"))
+
+Wrap a source in a `RolloutSource` to canary new values of a remote source. A `rollout` stanza of the values, with a `percent` of the instances, `hosts` to pick by ID, or both, picks the instances that apply them by a hash of their host name, and the other instances keep the values they last applied when they reload:
+
+  src := &cfg.RolloutSource{Source: &cfg.EtcdSource{Endpoint: "http://etcd.internal:2379", Key: "/myapp/config.yaml"}}
+
+`RedisSource` reads a config file stored in a Redis key, e.g. runtime settings shared by a fleet:
+
+  err := cfg.Load(&conf, cfg.WithSources(&cfg.RedisSource{Addr: "redis:6379", Key: "myapp:config", Format: "json"}))
+
+`AWSSecretSource` reads a JSON secret from AWS Secrets Manager, so that credentials never live in files. The region and credentials default to the `AWS_REGION`, `AWS_ACCESS_KEY_ID`, `AWS_SECRET_ACCESS_KEY` and `AWS_SESSION_TOKEN` env vars:
+
+  err := cfg.Load(&conf, cfg.WithSources(&cfg.AWSSecretSource{SecretID: "prod/db", Region: "eu-west-1"}))
+
+`GCPSecretSource` resolves versions of Google Secret Manager secrets into fields. Outside of GKE workloads using workload identity, set its `Token`:
+
+  err := cfg.Load(&conf, cfg.WithSources(&cfg.GCPSecretSource{Secrets: map[string]string{"db.password": "db-password"}}))
+
+`AzureKeyVaultSource` likewise fills fields with secrets of an Azure Key Vault, authenticating with the managed identity of the host unless a `Token` is set:
+
+  err := cfg.Load(&conf, cfg.WithSources(&cfg.AzureKeyVaultSource{Vault: "https://my-vault.vault.azure.net", Secrets: map[string]string{"db.password": "db-password"}}))
+
+`ArchiveSource` reads a config file within a tar (optionally compressed) or zip archive, given by its path or URL, so that a bundle of config and assets can be distributed as one artifact:
+
+  err := cfg.Load(&conf, cfg.WithSources(&cfg.ArchiveSource{Path: "https://releases.example.com/config-v42.tar.gz", File: "config/app.yaml"}))
+
+Sub-commands
+
+The sub-commands of a CLI can each load their own section of one config file with `SubCommand()`, which also prefixes env var keys with the section's path. `Flags()` overrides fields with the flags that were set on the command line, which take precedence over env vars.
+
+  # config.yaml
+  serve:
+    port: 8080
+  migrate:
+    dir: migrations
+
+  fs := flag.NewFlagSet("serve", flag.ExitOnError)
+  fs.Int("port", 0, "port to listen on")
+  fs.Parse(os.Args[2:])
+
+  err := cfg.Load(&serveCfg, cfg.SubCommand("serve"), cfg.UseEnv("myapp"), cfg.Flags(fs)) // MYAPP_SERVE_PORT, -port
+
+`Override()` forces fields to the given values above every other source, including flags, e.g. for Helm-style `--set key=value` pairs:
+
+  err := cfg.Load(&conf, cfg.Override(map[string]interface{}{"server.port": 8443, "servers[0].host": "a"}))
+
+`ParseSet()` parses such pairs into overrides whose values are converted to the type of their fields:
+
+  overrides, err := cfg.ParseSet([]string{"server.port=9090", "tags=[a,b]"})
+
+`SaveOverrides()` writes the fields whose values differ from those of the config files other than the target file, e.g. after env vars, flags or overrides adjusted them, to a file that can be loaded over the config files later. When an existing YAML file is rewritten, its comments and the order of its keys are kept. Fields tagged `secret:"true"` (or nested in such a field) are never written:
+
+  type Config struct {
+    Port     int    `cfg:"port"`
+    Password string `cfg:"password" secret:"true"`
+  }
+
+  err := cfg.SaveOverrides(&conf, "/etc/myapp/overrides/config.yaml", cfg.UseEnv("myapp"))
+
+Profiles
+
+Use `Profile()` to keep the config of every environment in a single file. The profile's subtree under the top-level `profiles` key is merged over the base values.
+
+  # config.yaml
+  server:
+    host: 0.0.0.0
+    port: 80
+  profiles:
+    dev:
+      server:
+        host: localhost
+
+  err := cfg.Load(&conf, cfg.Profile("dev")) // conf.Server.Host == "localhost"
+
+Policies
+
+Platform teams can enforce organization-wide rules with policies that check the raw values of the config files and sources, merged in the order they are loaded. Policies registered with `RegisterPolicy()`, typically at init time of a shared package, apply to every load, and `WithPolicy()` adds one to a single load. Load returns an error wrapping `ErrPolicyViolation` listing every failed policy.
+
+  cfg.RegisterPolicy("tls-required", func(vals map[string]interface{}) error {
+    if tls, _ := vals["tls"].(map[string]interface{}); tls["enabled"] != true {
+      return errors.New("tls must be enabled")
+    }
+    return nil
+  })
+
+Scopes
+
+Use `Scope()` to overlay the values of a tenant (or any other named scope) over the base config. The scope's values are taken from its subtree under the top-level `scopes` key and from the file `scopes/<name>.<ext>` next to the config file.
+
+  # config.yaml
+  server:
+    port: 80
+  scopes:
+    tenant-42:
+      server:
+        port: 8042
+
+  err := cfg.Load(&conf, cfg.Scope("tenant-42")) // conf.Server.Port == 8042
+
+Variables
+
+Use `Vars()` to parameterize a config per deployment. Config files are executed as text/template templates with the variables as data and `${name}` references in file values, env vars and defaults are replaced with the variable's value.
+
+  # config.yaml
+  bucket: logs-{{ .region }}
+  endpoint: https://${region}.example.com
+
+  err := cfg.Load(&conf, cfg.Vars(map[string]string{"region": "eu-1"}))
+
+With `RuntimeVars()`, the variables `runtime.hostname`, `runtime.pid`, `runtime.goVersion`, `runtime.os` and `runtime.arch` describe the running process, e.g. to bind a field to the host name:
+
+  type Config struct {
+    Instance string `cfg:"instance" default:"${runtime.hostname}"`
+  }
+
+  err := cfg.Load(&conf, cfg.RuntimeVars())
+
+`ExpandEnv()` expands `$NAME` and `${NAME}` references to env vars in the string values of config files. Unset env vars expand to nothing, `$$` stands for a literal `$`, and variables take precedence over env vars of the same name:
+
+  # config.yaml
+  path: ${HOME}/data
+  url: $BASE_URL/api
+
+Plugins
+
+Plugins and extensions can register their own config struct under a top-level section with `RegisterSection()`, typically at init time. Load decodes each registered section from the config files and applies defaults, env vars and validations to it as it does to the config struct.
+
+  var metricsCfg struct {
+    Addr string `cfg:"addr" default:":9090"`
+  }
+
+  func init() {
+    cfg.RegisterSection("metrics", &metricsCfg)
+  }
+
+Reloading
+
+`Reload()` loads the config again and reports the fields that changed. Fields tagged `reload:"restart-required"` (or nested in such a field) only take effect after a restart: when one of them changed, Reload leaves the config untouched and returns an error wrapping `ErrRestartRequired` along with the changes. `Diff()` compares two configs without loading.
+
+  type Config struct {
+    Addr  string `cfg:"addr" reload:"restart-required"`
+    Level string `cfg:"level"`
+  }
+
+  changes, err := cfg.Reload(&conf)
+  if errors.Is(err, cfg.ErrRestartRequired) {
+    // notify that a restart is needed
+  }
+
+`ApplyPatch()` edits a running config with operations modeled after JSON Patch but addressed by field path, e.g. for admin APIs. The patched config is validated again, and the changes are returned and applied as Reload applies them, or not at all:
+
+  changes, err := cfg.ApplyPatch(&conf, []cfg.Op{
+    {Op: cfg.OpTest, Path: "server.port", Value: 8080}, // fails with ErrTestFailed if the port changed
+    {Op: cfg.OpReplace, Path: "server.port", Value: 9090},
+  })
+
+`AdminHandler()` serves such patches over HTTP, sent as a JSON array of `{"op", "path", "value"}` objects, for operational tuning without a redeploy. Secret fields cannot be patched, and the changed fields are written to a `WritableSource`, if given, so that the overrides survive restarts. The handler does not authenticate requests.
+
+Reload, ApplyPatch and AdminHandler set the config struct in place, which races with the goroutines reading it. Hold the config in a `Live` instead: they then commit changes under a lock that `Get()` holds too, and serialize with each other:
+
+  live := cfg.NewLive(&conf)
+  admin.Handle("/config", cfg.AdminHandler(live, etcdSrc, options...))
+
+  // in request handlers
+  conf := live.Get()
+
+Use `CompatCheck()` to reject reloaded configs that are not compatible with the current one, e.g. a pool shrunk below its current usage.
+
+`ReloadWindows()` and `ReloadRateLimit()` defer changes outside of the given windows, e.g. during peak traffic, or more often than once per interval. Reload then leaves the config untouched and returns an error wrapping `ErrReloadDeferred`, and keeps the new values pending: they are applied from another goroutine when the next window opens or the interval has passed, unless a later Reload finds newer values first or `Forget()` drops them. Hold the config in a `Live` so that its readers synchronize with that write.
+
+`ReloadStagger()` spreads the changes applied by a fleet of instances over a window, each instance delaying by an offset derived from a hash of its host name, so that they don't all reconnect to a remote source at once. Use `ReloadContext()` to bound the delay, e.g. by the shutdown of the service.
+
+`LevelVar()` returns a `*slog.LevelVar` that follows a log level field (a string or a `cfg.Logging` section) across reloads, and `SyncLevel()` does the same for any level that unmarshals from text, such as a `zap.AtomicLevel`:
+
+  lvl, err := cfg.LevelVar(&conf, "log")
+  logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: lvl}))
+
+For zero-downtime restarts, `Inherit()` passes the loaded config to a re-executed child process through the env var `CFG_INHERITED`, and the `Inherited()` option loads it in the child in place of the config files, so that both run the same config even if the files changed in between:
+
+  cmd := exec.Command(os.Args[0], os.Args[1:]...)
+  err := cfg.Inherit(cmd, &conf)
+
+  // in the child
+  err := cfg.Load(&conf, cfg.Dirs("/etc/myapp"), cfg.Inherited())
+
+The env of a process can be read by other processes of the same user: to keep secrets out of it, `InheritPipe()` passes the config through a pipe in `cmd.ExtraFiles` instead, on systems other than Windows.
+
+Results
+
+`LoadResult()` loads the config like `Load()` and returns a `Result` describing the load: the files and other sources consulted, the keys that did not match any field, warnings, the origin of each field's value and a hash of the config.
+
+  res, err := cfg.LoadResult(&conf)
+  log.Printf("loaded config %s from %v, unused keys: %v", res.Hash, res.Files, res.Unused)
+
+`Paths()` lists the fields whose value came from a given origin, e.g. to log the effective provenance of the config at startup:
+
+  log.Printf("defaulted: %v, from env: %v", res.Paths("default"), res.Paths("env"))
+
+`Lint()` adds warnings about values that are likely mistakes: durations set from numbers without a unit (read as nanoseconds), ports out of range, paths with trailing spaces and times more than 100 years in the past or future.
+
+  res, err := cfg.LoadResult(&conf, cfg.Lint())
+  for _, w := range res.Warnings {
+    log.Printf("config: %s", w)
+  }
+
+Metrics
+
+Fields tagged with `metric:"true"` (or nested in such a field) are exported as a Prometheus gauge labeled with their path by `MetricsHandler()`, so that dashboards can correlate behavior with config changes:
+
+  type Config struct {
+    Workers int `cfg:"workers" metric:"true"`
+  }
+
+  http.Handle("/metrics/config", cfg.MetricsHandler(&conf)) // config_value{path="workers"} 8
+
+Freezing
+
+In tests, load with `Freeze()` to catch code that writes into a shared config struct. `CheckUnchanged()` returns an error wrapping `ErrMutated` that lists the paths of the fields mutated since the struct was loaded.
+
+  err := cfg.Load(&conf, cfg.Freeze())
+  ...
+  err = cfg.CheckUnchanged(&conf) // config mutated: server.port
+
+The package keeps the snapshots of `Freeze()`, the hooks of `SyncLevel()` and `LevelVar()` and the schedule of `ReloadRateLimit()` by config pointer for the life of the process. Call `Forget()` once a config is no longer used, e.g. for configs loaded per tenant, to release them.
+
+Waiting
+
+When the config is provided by another process at startup, `WaitForValid()` retries loading until it succeeds or a timeout elapses.
+
+  err := cfg.WaitForValid(ctx, time.Minute, &conf, cfg.Dirs("/etc/myapp"))
+
 Errors
 
 A wrapped error `ErrFileNotFound` is returned when cfg is not able to find a config file to load. This can be useful for instance to fallback to a different configuration loading mechanism.
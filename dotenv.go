@@ -0,0 +1,109 @@
+package cfg
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// loadDotenv reads the dotenv file and adds its variables to f.dotenv.
+// variables defined by later files overwrite those of earlier ones.
+func (f *cfg) loadDotenv(file string) error {
+	fd, err := f.open(file)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	if err := f.addDotenv(fd); err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	return nil
+}
+
+// addDotenv parses the dotenv variables read from r and adds them to
+// f.dotenv.
+func (f *cfg) addDotenv(r io.Reader) error {
+	vars, err := parseDotenv(r)
+	if err != nil {
+		return err
+	}
+
+	if f.dotenv == nil {
+		f.dotenv = make(map[string]string)
+	}
+	for k, v := range vars {
+		f.dotenv[k] = v
+	}
+	return nil
+}
+
+// parseDotenv parses `KEY=value` lines. Blank lines and lines starting
+// with `#` are ignored and keys may be preceded by `export`. Values may be
+// double quoted, in which case escape sequences are interpreted, or single
+// quoted, in which case they are taken literally. Unquoted values end at
+// the first ` #`.
+func parseDotenv(r io.Reader) (map[string]string, error) {
+	vars := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, val, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("line %d: invalid variable %q", n, line)
+		}
+
+		val, err := parseDotenvValue(strings.TrimSpace(val))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		vars[key] = val
+	}
+
+	return vars, scanner.Err()
+}
+
+// parseDotenvValue unquotes val.
+func parseDotenvValue(val string) (string, error) {
+	switch {
+	case strings.HasPrefix(val, `"`):
+		end := closingQuote(val)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted value %s", val)
+		}
+		return strconv.Unquote(val[:end+1])
+	case strings.HasPrefix(val, "'"):
+		end := strings.Index(val[1:], "'")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted value %s", val)
+		}
+		return val[1 : end+1], nil
+	default:
+		if i := strings.Index(val, " #"); i >= 0 {
+			val = val[:i]
+		}
+		return strings.TrimSpace(val), nil
+	}
+}
+
+// closingQuote returns the index of the double quote closing the double
+// quoted string at the start of s, or -1 if there is none.
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
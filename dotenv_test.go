@@ -0,0 +1,112 @@
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_cfg_Load_Dotenv(t *testing.T) {
+	type Config struct {
+		Host     string `cfg:"host"`
+		LogLevel string `cfg:"log_level"`
+		Server   struct {
+			Ports   []int         `cfg:"ports"`
+			Timeout time.Duration `cfg:"timeout" default:"5s"`
+		} `cfg:"server"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "host: localhost\nlog_level: info\n")
+	writeFile(t, filepath.Join(dir, ".env"), `
+# overrides for local development
+export MYAPP_LOG_LEVEL=debug
+MYAPP_SERVER_PORTS="[80,443]"
+`)
+
+	t.Run("with prefix", func(t *testing.T) {
+		os.Clearenv()
+		setenv(t, "MYAPP_SERVER_PORTS", "[8080]")
+
+		var cfg Config
+		err := Load(&cfg, File(".env"), Dirs(dir), UseEnv("myapp"))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		var want Config
+		want.Host = "localhost"
+		want.LogLevel = "debug"
+		want.Server.Ports = []int{8080}
+		want.Server.Timeout = 5 * time.Second
+
+		if !reflect.DeepEqual(want, cfg) {
+			t.Errorf("\nwant %+v\ngot %+v", want, cfg)
+		}
+	})
+
+	t.Run("without env", func(t *testing.T) {
+		writeFile(t, filepath.Join(dir, "app.env"), "SERVER_TIMEOUT=1m\n")
+
+		var cfg Config
+		err := Load(&cfg, File("app.env"), Dirs(dir))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		if cfg.Server.Timeout != time.Minute || cfg.Host != "localhost" {
+			t.Errorf("unexpected cfg: %+v", cfg)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		writeFile(t, filepath.Join(dir, "bad.env"), "HOST\n")
+
+		var cfg Config
+		err := Load(&cfg, File("bad.env"), Dirs(dir))
+		if err == nil || !strings.Contains(err.Error(), "line 1") {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+}
+
+func Test_parseDotenv(t *testing.T) {
+	in := `
+# comment
+A=1
+export B = two words  # trailing comment
+C="quoted # not a comment\nnext"
+D='single $literal \n'
+E=
+F=a=b
+G="escaped \" quote" # comment
+`
+	got, err := parseDotenv(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := map[string]string{
+		"A": "1",
+		"B": "two words",
+		"C": "quoted # not a comment\nnext",
+		"D": `single $literal \n`,
+		"E": "",
+		"F": "a=b",
+		"G": `escaped " quote`,
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("\nwant %q\ngot %q", want, got)
+	}
+
+	for _, in := range []string{"NOVALUE", "=value", "A B=c", `A="unterminated`, "A='unterminated"} {
+		t.Run(in, func(t *testing.T) {
+			if _, err := parseDotenv(strings.NewReader(in)); err == nil {
+				t.Fatal("expected err")
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// setEnvValue sets fv to the value val of the env var of the field at
+// path. Structs, maps and slices of composites are set from JSON, see
+// setEnvComposite, SectionDecoders by their Decode method and other values
+// by setValue, with the elements of slices separated by sep.
+func (f *cfg) setEnvValue(fv reflect.Value, val, path, sep string) error {
+	if sd, ok := sectionDecoder(fv); ok {
+		return setSection(sd, val)
+	}
+	if isEnvComposite(fv.Type()) {
+		return f.setEnvComposite(fv, val, path)
+	}
+	return f.setValue(fv, val, sep)
+}
+
+// isEnvComposite reports whether t is set as a whole from a JSON env var:
+// a composite or a struct that is not a scalar, so that a subtree of the
+// config can be set by a single env var.
+func isEnvComposite(t reflect.Type) bool {
+	if isComposite(t) {
+		return true
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !isScalarStruct(t)
+}
+
+// setEnvComposite sets the composite fv to val, which is a JSON document
+// or else a composite literal as used in defaults. val is subject to the
+// max file size, keys and depth, and its values are checked against the
+// type of fv so that the returned error, a fieldErrors, names the path of
+// each invalid value, e.g. `servers[1].port`.
+func (f *cfg) setEnvComposite(fv reflect.Value, val, path string) error {
+	if f.maxFileSize > 0 && int64(len(val)) > f.maxFileSize {
+		return fmt.Errorf("%w: value is larger than the max size", ErrLimitExceeded)
+	}
+
+	tree, err := parseEnvComposite(val)
+	if err != nil {
+		return err
+	}
+	if err := f.checkLimits(tree); err != nil {
+		return err
+	}
+	if err := f.checkStrictTypes(tree, fv.Type(), path); err != nil {
+		return err
+	}
+
+	errs := make(fieldErrors)
+	v := reflect.New(fv.Type()).Elem()
+	f.decodeTree(tree, v, path, errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	fv.Set(v)
+	return nil
+}
+
+// parseEnvComposite parses val as JSON if it is a JSON object or array, or
+// else as a composite literal.
+func parseEnvComposite(val string) (interface{}, error) {
+	s := strings.TrimSpace(val)
+	if !strings.HasPrefix(s, "{") && !strings.HasPrefix(s, "[") {
+		return parseLiteral(s)
+	}
+
+	var tree interface{}
+	jsonErr := json.Unmarshal([]byte(s), &tree)
+	if jsonErr == nil {
+		return tree, nil
+	}
+	// lists and maps of unquoted scalars, e.g. `[a,b]`, are literals.
+	if lit, err := parseLiteral(s); err == nil {
+		return lit, nil
+	}
+	return nil, fmt.Errorf("invalid JSON: %w", jsonErr)
+}
+
+// decodeTree decodes the tree of values val into v, which must be
+// settable, recording the errors of the values that don't fit their
+// type in errs by path.
+func (f *cfg) decodeTree(val interface{}, v reflect.Value, path string, errs fieldErrors) {
+	if val == nil {
+		return
+	}
+
+	switch {
+	case v.Kind() == reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		f.decodeTree(val, elem.Elem(), path, errs)
+		v.Set(elem)
+
+	case v.Kind() == reflect.Struct && !isScalarStruct(v.Type()):
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			errs[path] = fmt.Errorf("expected an object, got %s", jsonType(val))
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			sf := v.Type().Field(i)
+			if sf.PkgPath != "" && !sf.Anonymous {
+				continue
+			}
+			if strings.Contains(sf.Tag.Get(f.tag), ",squash") {
+				f.decodeTree(val, v.Field(i), path, errs)
+				continue
+			}
+			name := parseTag(sf.Tag, f.tag).altName
+			if name == "" {
+				name = sf.Name
+			}
+			if child, ok := lookupKey(m, name); ok {
+				f.decodeTree(child, v.Field(i), joinPath(path, name), errs)
+			}
+		}
+
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		s, ok := val.([]interface{})
+		if !ok {
+			f.decodeLeaf(val, v, path, errs)
+			return
+		}
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), len(s), len(s)))
+		} else if len(s) > v.Len() {
+			errs[path] = fmt.Errorf("expected at most %d elements, got %d", v.Len(), len(s))
+			return
+		}
+		for i, elem := range s {
+			f.decodeTree(elem, v.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+
+	case v.Kind() == reflect.Map:
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			errs[path] = fmt.Errorf("expected an object, got %s", jsonType(val))
+			return
+		}
+		v.Set(reflect.MakeMapWithSize(v.Type(), len(m)))
+		for k, elem := range m {
+			key := reflect.New(v.Type().Key()).Elem()
+			f.decodeLeaf(k, key, joinPath(path, k), errs)
+			value := reflect.New(v.Type().Elem()).Elem()
+			f.decodeTree(elem, value, joinPath(path, k), errs)
+			v.SetMapIndex(key, value)
+		}
+
+	default:
+		f.decodeLeaf(val, v, path, errs)
+	}
+}
+
+// decodeLeaf decodes val into v with the decoder of config files,
+// recording the error in errs at path if it fails.
+func (f *cfg) decodeLeaf(val interface{}, v reflect.Value, path string, errs fieldErrors) {
+	err := f.decodeValue(val, v.Addr().Interface(), nil)
+	if err == nil {
+		return
+	}
+	// errors of the decoder name the decoded value, which has no name here.
+	msg := err.Error()
+	var mErr *mapstructure.Error
+	if errors.As(err, &mErr) && len(mErr.Errors) > 0 {
+		msg = mErr.Errors[0]
+	}
+	msg = strings.TrimPrefix(msg, "error decoding '': ")
+	msg = strings.TrimPrefix(msg, "'' ")
+	err = errors.New(strings.Replace(msg, " '' ", " value ", 1))
+	errs[path] = err
+}
+
+// jsonType returns the JSON type name of a decoded JSON value.
+func jsonType(val interface{}) string {
+	switch val.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return "a string"
+	case float64:
+		return "a number"
+	case bool:
+		return "a bool"
+	default:
+		return fmt.Sprintf("%T", val)
+	}
+}
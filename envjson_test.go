@@ -0,0 +1,158 @@
+package cfg
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_cfg_Load_EnvJSON(t *testing.T) {
+	type Server struct {
+		Host    string        `cfg:"host" validate:"required"`
+		Port    int           `cfg:"port" default:"80"`
+		Timeout time.Duration `cfg:"timeout"`
+	}
+	type Config struct {
+		Servers []Server          `cfg:"servers"`
+		Labels  map[string]string `cfg:"labels"`
+		Limits  map[string]int    `cfg:"limits"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "servers:\n  - host: a\n  - host: b\n    port: 1\n")
+
+	t.Run("valid", func(t *testing.T) {
+		setenv(t, "SERVERS", `[{"host": "b", "timeout": "5s"}, {"host": "c", "port": 8080}]`)
+		setenv(t, "LABELS", `{"team": "core", "tier": "1"}`)
+		setenv(t, "LIMITS", `{cpu: 2, mem: 512}`)
+
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir), UseEnv("")); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		want := Config{
+			Servers: []Server{{Host: "b", Port: 80, Timeout: 5 * time.Second}, {Host: "c", Port: 8080}},
+			Labels:  map[string]string{"team": "core", "tier": "1"},
+			Limits:  map[string]int{"cpu": 2, "mem": 512},
+		}
+		if !reflect.DeepEqual(want, cfg) {
+			t.Errorf("\nwant %+v\ngot  %+v", want, cfg)
+		}
+	})
+
+	for _, tc := range []struct {
+		name    string
+		env     string
+		val     string
+		wantErr []string
+	}{
+		{
+			name:    "invalid types",
+			env:     "SERVERS",
+			val:     `[{"host": "b", "port": "http"}, {"host": "c", "timeout": "5x"}]`,
+			wantErr: []string{`servers[0].port: cannot parse value as int: strconv.ParseInt: parsing "http"`, `servers[1].timeout: time: unknown unit "x"`},
+		},
+		{
+			name:    "required",
+			env:     "SERVERS",
+			val:     `[{"host": "b"}, {"port": 1}]`,
+			wantErr: []string{"servers[1].host: required validation failed"},
+		},
+		{
+			name:    "not an object",
+			env:     "SERVERS",
+			val:     `[{"host": "b"}, "c"]`,
+			wantErr: []string{"servers[1]: expected an object, got a string"},
+		},
+		{
+			name:    "map values",
+			env:     "LIMITS",
+			val:     `{"cpu": 2, "mem": "lots"}`,
+			wantErr: []string{`limits.mem: cannot parse value as int`},
+		},
+		{
+			name:    "invalid JSON",
+			env:     "LABELS",
+			val:     `{"team": "core"`,
+			wantErr: []string{"labels: unable to set from env: invalid JSON"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			setenv(t, tc.env, tc.val)
+
+			var cfg Config
+			err := Load(&cfg, Dirs(dir), UseEnv(""))
+			if err == nil {
+				t.Fatal("expected err")
+			}
+			for _, want := range tc.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("want err containing %q, got %v", want, err)
+				}
+			}
+		})
+	}
+
+	t.Run("structs", func(t *testing.T) {
+		type Config struct {
+			Server  Server  `cfg:"server"`
+			Backup  *Server `cfg:"backup"`
+			Cluster struct {
+				Name  string   `cfg:"name"`
+				Nodes []Server `cfg:"nodes"`
+			} `cfg:"cluster"`
+		}
+
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "config.yaml"), "server:\n  host: a\n  port: 1\ncluster:\n  name: old\n  nodes:\n    - host: n1\n")
+
+		setenv(t, "SERVER", `{"host": "x", "timeout": "5s"}`)
+		setenv(t, "SERVER_TIMEOUT", "10s")
+		setenv(t, "BACKUP", `{"host": "y"}`)
+		setenv(t, "CLUSTER", `{"nodes": [{"host": "n2"}, {"host": "n3", "port": 9}]}`)
+
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir), UseEnv("")); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		if want := (Server{Host: "x", Port: 80, Timeout: 10 * time.Second}); cfg.Server != want {
+			t.Errorf("want server %+v, got %+v", want, cfg.Server)
+		}
+		if want := (Server{Host: "y", Port: 80}); cfg.Backup == nil || *cfg.Backup != want {
+			t.Errorf("want backup %+v, got %+v", want, cfg.Backup)
+		}
+		wantNodes := []Server{{Host: "n2", Port: 80}, {Host: "n3", Port: 9}}
+		if cfg.Cluster.Name != "" || !reflect.DeepEqual(wantNodes, cfg.Cluster.Nodes) {
+			t.Errorf("want cluster replaced with nodes %+v, got %+v", wantNodes, cfg.Cluster)
+		}
+	})
+
+	t.Run("struct errors", func(t *testing.T) {
+		type Config struct {
+			Server Server `cfg:"server"`
+		}
+
+		setenv(t, "SERVER", `{"port": "http"}`)
+
+		var cfg Config
+		err := Load(&cfg, IgnoreFile(), UseEnv(""))
+		if err == nil || !strings.Contains(err.Error(), "server.port: cannot parse value as int") {
+			t.Fatalf("want err naming server.port, got %v", err)
+		}
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		setenv(t, "LABELS", `{"a": "1", "b": "2", "c": "3"}`)
+
+		var cfg Config
+		err := Load(&cfg, Dirs(dir), UseEnv(""), MaxKeys(2))
+		if !errors.Is(err, ErrLimitExceeded) {
+			t.Fatalf("want ErrLimitExceeded, got %v", err)
+		}
+	})
+}
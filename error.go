@@ -14,6 +14,44 @@ var ErrFileNotFound = fmt.Errorf("file not found")
 // env settings are disabled.
 var ErrInvalidSources = fmt.Errorf("must provide files or use env")
 
+// ErrVersionMismatch is returned as a wrapped error by `Load` when a config file
+// declares a schema version other than the one expected by the config struct.
+var ErrVersionMismatch = fmt.Errorf("config version mismatch")
+
+// ErrMutated is returned as a wrapped error by `CheckUnchanged` when fields of a
+// frozen config struct were changed since it was loaded.
+var ErrMutated = fmt.Errorf("config mutated")
+
+// ErrRestartRequired is returned as a wrapped error by `Reload` when a field
+// tagged with `reload:"restart-required"` changed.
+var ErrRestartRequired = fmt.Errorf("restart required")
+
+// ErrReloadDeferred is returned as a wrapped error by `Reload` when changes
+// are not applied because of the schedule set with `ReloadWindows` or
+// `ReloadRateLimit`. The changes are applied once the schedule allows it.
+var ErrReloadDeferred = fmt.Errorf("reload deferred")
+
+// ErrLimitExceeded is returned as a wrapped error by `Load` when a config file
+// exceeds the limits set with `MaxFileSize`, `MaxKeys` or `MaxDepth`.
+var ErrLimitExceeded = fmt.Errorf("config limit exceeded")
+
+// ErrConflict is returned as a wrapped error by the `Put` method of a
+// WritableSource when the value changed since the source was last read.
+var ErrConflict = fmt.Errorf("config changed concurrently")
+
+// ErrTestFailed is returned as a wrapped error by `ApplyPatch` when the
+// value at the path of a test operation differs from that of the operation.
+var ErrTestFailed = fmt.Errorf("patch test failed")
+
+// ErrPolicyViolation is returned as a wrapped error by `Load` when the values
+// of the config files and sources violate a policy.
+var ErrPolicyViolation = fmt.Errorf("config policy violation")
+
+// ErrAmbiguousFiles is returned as a wrapped error by `Load` when several
+// copies or variants of a config file are found, e.g. `config.yaml` and
+// `config.yml`, and `DetectAmbiguity(AmbiguityError)` is set.
+var ErrAmbiguousFiles = fmt.Errorf("ambiguous config files")
+
 // fieldErrors collects errors for fields of config struct.
 type fieldErrors map[string]error
 
@@ -37,3 +75,12 @@ func (fe fieldErrors) Error() string {
 
 	return strings.TrimSuffix(sb.String(), ", ")
 }
+
+// ErrDigestMismatch is returned as a wrapped error by `Load` when the
+// SHA-256 digest of a config file does not match those set with
+// `ExpectSHA256`.
+var ErrDigestMismatch = fmt.Errorf("config digest mismatch")
+
+// ErrUnusedEnv is returned as a wrapped error by `Load` when env vars under
+// the prefix of `UseEnv` set no field and `StrictEnv` is set.
+var ErrUnusedEnv = fmt.Errorf("unused env vars")
@@ -0,0 +1,291 @@
+package cfg
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EtcdSource is a source that reads config values from etcd v3 through its
+// JSON gateway, so that no etcd client dependency is needed.
+//
+// If Prefix is false, the value of Key is decoded as a config file of the
+// given Format. Otherwise every key under the Key prefix is mapped onto a
+// nested path by splitting the rest of the key on `/`, e.g. the value of
+// `/myapp/server/port` sets `server.port` when Key is `/myapp/`. Values
+// that are JSON arrays or objects, such as those written by Put, are
+// decoded as such.
+type EtcdSource struct {
+	Endpoint string       // URL of an etcd endpoint, e.g. `http://127.0.0.1:2379`.
+	Key      string       // key, or key prefix if Prefix is true.
+	Prefix   bool         // true to read all the keys under the Key prefix.
+	Format   string       // format of the value of Key, e.g. `yaml`. Defaults to `yaml`.
+	Client   *http.Client // client used for requests. Defaults to http.DefaultClient.
+	Proxy    string       // URL of the proxy of requests. Defaults to that of the `HTTP_PROXY` env var.
+	TLS      *tls.Config  // TLS config of requests, e.g. with a client certificate and CAs for mTLS.
+
+	mu        sync.Mutex
+	revisions map[string]int64 // mod revisions of the keys of the last read, by key.
+}
+
+// etcdKV is a key value pair of an etcd range response.
+type etcdKV struct {
+	Key         []byte `json:"key"`
+	Value       []byte `json:"value"`
+	ModRevision int64  `json:"mod_revision,string,omitempty"`
+}
+
+// Read returns the values read from etcd.
+func (s *EtcdSource) Read(ctx context.Context) (map[string]interface{}, error) {
+	kvs, err := s.rangeKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make(map[string]int64, len(kvs))
+	for _, kv := range kvs {
+		revisions[string(kv.Key)] = kv.ModRevision
+	}
+	s.mu.Lock()
+	s.revisions = revisions
+	s.mu.Unlock()
+
+	vals := make(map[string]interface{})
+
+	if !s.Prefix {
+		if len(kvs) == 0 {
+			return nil, fmt.Errorf("etcd: key %q not found", s.Key)
+		}
+		format := s.Format
+		if format == "" {
+			format = "yaml"
+		}
+		if !strings.HasPrefix(format, ".") {
+			format = "." + format
+		}
+		decode, err := lookupDecoder(format)
+		if err != nil {
+			return nil, err
+		}
+		if err := decode(bytes.NewReader(kvs[0].Value), vals); err != nil {
+			return nil, fmt.Errorf("etcd: key %q: %w", s.Key, err)
+		}
+		return vals, nil
+	}
+
+	for _, kv := range kvs {
+		path := strings.Trim(strings.TrimPrefix(string(kv.Key), s.Key), "/")
+		if path == "" {
+			continue
+		}
+		if err := setPath(vals, strings.Split(path, "/"), etcdValue(kv.Value)); err != nil {
+			return nil, fmt.Errorf("etcd: %w", err)
+		}
+	}
+	return vals, nil
+}
+
+// Put writes value at the dotted path back to etcd, e.g. for admin tooling
+// that edits live config. The write is a compare-and-swap that fails with
+// an error wrapping ErrConflict if the key changed since the source was
+// last read, or since the last Put.
+//
+// If Prefix is true, value is written to the key of path under the Key
+// prefix, e.g. `server.port` to `/myapp/server/port`, as a string if it is
+// a scalar or as JSON if it is a slice, map or struct. Otherwise the
+// value of Key is changed at path and encoded again, which is supported
+// for the yaml and json formats only and drops the comments of the value.
+func (s *EtcdSource) Put(ctx context.Context, path string, value interface{}) error {
+	s.mu.Lock()
+	read := s.revisions != nil
+	s.mu.Unlock()
+	if !read {
+		if _, err := s.Read(ctx); err != nil {
+			return err
+		}
+	}
+
+	if s.Prefix {
+		key := strings.TrimSuffix(s.Key, "/") + "/" + strings.ReplaceAll(path, ".", "/")
+		data, err := encodeEtcdValue(value)
+		if err != nil {
+			return fmt.Errorf("etcd: key %q: %w", key, err)
+		}
+		return s.compareAndPut(ctx, key, data)
+	}
+
+	kvs, err := s.rangeKeys(ctx)
+	if err != nil {
+		return err
+	}
+	if len(kvs) == 0 {
+		return fmt.Errorf("etcd: key %q: %w", s.Key, ErrConflict)
+	}
+
+	vals := make(map[string]interface{})
+	format := strings.TrimPrefix(s.Format, ".")
+	var encode func(v interface{}) ([]byte, error)
+	switch format {
+	case "", "yaml", "yml":
+		err = yaml.Unmarshal(kvs[0].Value, &vals)
+		encode = yaml.Marshal
+	case "json":
+		err = json.Unmarshal(kvs[0].Value, &vals)
+		encode = func(v interface{}) ([]byte, error) { return json.MarshalIndent(v, "", "  ") }
+	default:
+		return fmt.Errorf("etcd: writing %s values is not supported", format)
+	}
+	if err != nil {
+		return fmt.Errorf("etcd: key %q: %w", s.Key, err)
+	}
+	if err := setPath(vals, strings.Split(path, "."), value); err != nil {
+		return fmt.Errorf("etcd: key %q: %w", s.Key, err)
+	}
+	data, err := encode(vals)
+	if err != nil {
+		return fmt.Errorf("etcd: key %q: %w", s.Key, err)
+	}
+	return s.compareAndPut(ctx, s.Key, data)
+}
+
+// encodeEtcdValue encodes value as the value of a key under the prefix of
+// an EtcdSource: scalars as strings and composites as JSON.
+func encodeEtcdValue(value interface{}) ([]byte, error) {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+		return json.Marshal(value)
+	default:
+		return []byte(fmt.Sprint(v.Interface())), nil
+	}
+}
+
+// etcdValue returns the value of a key under the prefix of an EtcdSource,
+// decoding JSON arrays and objects.
+func etcdValue(data []byte) interface{} {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		var v interface{}
+		if err := json.Unmarshal(trimmed, &v); err == nil {
+			return v
+		}
+	}
+	return string(data)
+}
+
+// compareAndPut puts value at key if the mod revision of key is still the
+// one of the last read.
+func (s *EtcdSource) compareAndPut(ctx context.Context, key string, value []byte) error {
+	s.mu.Lock()
+	rev := s.revisions[key]
+	s.mu.Unlock()
+
+	// keys that don't exist have a mod revision of 0.
+	body, err := json.Marshal(map[string]interface{}{
+		"compare": []interface{}{map[string]interface{}{
+			"key":          []byte(key),
+			"target":       "MOD",
+			"result":       "EQUAL",
+			"mod_revision": strconv.FormatInt(rev, 10),
+		}},
+		"success": []interface{}{map[string]interface{}{
+			"request_put": map[string][]byte{"key": []byte(key), "value": value},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	var out struct {
+		Header struct {
+			Revision int64 `json:"revision,string"`
+		} `json:"header"`
+		Succeeded bool `json:"succeeded"`
+	}
+	if err := s.post(ctx, "/v3/kv/txn", body, &out); err != nil {
+		return err
+	}
+	if !out.Succeeded {
+		return fmt.Errorf("etcd: key %q: %w", key, ErrConflict)
+	}
+
+	s.mu.Lock()
+	s.revisions[key] = out.Header.Revision
+	s.mu.Unlock()
+	return nil
+}
+
+// rangeKeys fetches Key, or all the keys under the Key prefix, from the
+// range endpoint of the etcd JSON gateway.
+func (s *EtcdSource) rangeKeys(ctx context.Context) ([]etcdKV, error) {
+	req := map[string][]byte{"key": []byte(s.Key)}
+	if s.Prefix {
+		req["range_end"] = prefixRangeEnd([]byte(s.Key))
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var out struct {
+		Kvs []etcdKV `json:"kvs"`
+	}
+	if err := s.post(ctx, "/v3/kv/range", body, &out); err != nil {
+		return nil, err
+	}
+	return out.Kvs, nil
+}
+
+// post sends body to the endpoint at path of the etcd JSON gateway and
+// decodes the response into out.
+func (s *EtcdSource) post(ctx context.Context, path string, body []byte, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(s.Endpoint, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client, err := sourceClient(ctx, s.Client, true, s.Proxy, s.TLS)
+	if err != nil {
+		return fmt.Errorf("etcd: %w", err)
+	}
+	if err := doRequest(client, req, out); err != nil {
+		return fmt.Errorf("etcd: %w", err)
+	}
+	return nil
+}
+
+// prefixRangeEnd returns the end of the range of keys with the given
+// prefix, i.e. the prefix with its last byte that is not 0xff incremented.
+// `\x00` is returned to range over all keys if there is no such byte.
+func prefixRangeEnd(prefix []byte) []byte {
+	end := append([]byte(nil), prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return []byte{0}
+}
+
+// String returns the name of the source, e.g. in the Provenance of a
+// Result.
+func (s *EtcdSource) String() string {
+	return "etcd:" + s.Key
+}
@@ -0,0 +1,356 @@
+package cfg
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// etcdServer emulates the range and txn endpoints of the etcd JSON
+// gateway.
+type etcdServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	revision int64
+	kvs      map[string]etcdKV
+}
+
+// newEtcdServer returns a server that emulates the etcd JSON gateway over
+// kvs.
+func newEtcdServer(t *testing.T, kvs map[string]string) *etcdServer {
+	t.Helper()
+	srv := &etcdServer{kvs: make(map[string]etcdKV)}
+	for k, v := range kvs {
+		srv.put(k, v)
+	}
+	srv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+
+		switch r.URL.Path {
+		case "/v3/kv/range":
+			srv.serveRange(w, r)
+		case "/v3/kv/txn":
+			srv.serveTxn(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func (srv *etcdServer) put(key, value string) {
+	srv.revision++
+	srv.kvs[key] = etcdKV{Key: []byte(key), Value: []byte(value), ModRevision: srv.revision}
+}
+
+func (srv *etcdServer) serveRange(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Key      []byte `json:"key"`
+		RangeEnd []byte `json:"range_end"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	keys := make([]string, 0, len(srv.kvs))
+	for k := range srv.kvs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var resp struct {
+		Kvs []etcdKV `json:"kvs,omitempty"`
+	}
+	for _, k := range keys {
+		match := k == string(req.Key)
+		if req.RangeEnd != nil {
+			match = bytes.Compare([]byte(k), req.Key) >= 0 && bytes.Compare([]byte(k), req.RangeEnd) < 0
+		}
+		if match {
+			resp.Kvs = append(resp.Kvs, srv.kvs[k])
+		}
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (srv *etcdServer) serveTxn(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Compare []struct {
+			Key         []byte `json:"key"`
+			Target      string `json:"target"`
+			Result      string `json:"result"`
+			ModRevision int64  `json:"mod_revision,string"`
+		} `json:"compare"`
+		Success []struct {
+			RequestPut struct {
+				Key   []byte `json:"key"`
+				Value []byte `json:"value"`
+			} `json:"request_put"`
+		} `json:"success"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	succeeded := true
+	for _, c := range req.Compare {
+		if c.Target != "MOD" || c.Result != "EQUAL" {
+			http.Error(w, "unsupported compare", http.StatusBadRequest)
+			return
+		}
+		succeeded = succeeded && srv.kvs[string(c.Key)].ModRevision == c.ModRevision
+	}
+	if succeeded {
+		for _, op := range req.Success {
+			srv.put(string(op.RequestPut.Key), string(op.RequestPut.Value))
+		}
+	}
+	fmt.Fprintf(w, `{"header": {"revision": "%d"}, "succeeded": %t}`, srv.revision, succeeded)
+}
+
+func Test_EtcdSource_Read(t *testing.T) {
+	srv := newEtcdServer(t, map[string]string{
+		"/myapp/config.yaml":    "server:\n  host: localhost\n",
+		"/myapp/server/port":    "8080",
+		"/myapp/server/host":    "example.com",
+		"/myapp/log/level":      "debug",
+		"/myappother/log/level": "info",
+	})
+
+	t.Run("key", func(t *testing.T) {
+		src := &EtcdSource{Endpoint: srv.URL, Key: "/myapp/config.yaml"}
+		vals, err := src.Read(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		want := map[string]interface{}{"server": map[string]interface{}{"host": "localhost"}}
+		if !reflect.DeepEqual(want, vals) {
+			t.Errorf("\nwant %v\ngot  %v", want, vals)
+		}
+	})
+
+	t.Run("prefix", func(t *testing.T) {
+		src := &EtcdSource{Endpoint: srv.URL, Key: "/myapp/server/", Prefix: true}
+		vals, err := src.Read(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		want := map[string]interface{}{"host": "example.com", "port": "8080"}
+		if !reflect.DeepEqual(want, vals) {
+			t.Errorf("\nwant %v\ngot  %v", want, vals)
+		}
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		src := &EtcdSource{Endpoint: srv.URL, Key: "/nope"}
+		if _, err := src.Read(context.Background()); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+}
+
+func Test_EtcdSource_TLS(t *testing.T) {
+	cert, key := testCertificate(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM([]byte(cert))
+
+	// an mTLS server with the handler of an etcd server.
+	etcd := newEtcdServer(t, map[string]string{"/myapp/log/level": "debug"})
+	srv := httptest.NewUnstartedServer(etcd.Config.Handler)
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+
+	t.Run("client certificate", func(t *testing.T) {
+		conf, err := (&TLS{Cert: cert, Key: key, CA: string(ca)}).Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		src := &EtcdSource{Endpoint: srv.URL, Key: "/myapp/", Prefix: true, TLS: conf}
+		vals, err := src.Read(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		want := map[string]interface{}{"log": map[string]interface{}{"level": "debug"}}
+		if !reflect.DeepEqual(want, vals) {
+			t.Errorf("\nwant %v\ngot  %v", want, vals)
+		}
+	})
+
+	t.Run("no client certificate", func(t *testing.T) {
+		conf, err := (&TLS{CA: string(ca)}).Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		src := &EtcdSource{Endpoint: srv.URL, Key: "/myapp/", Prefix: true, TLS: conf}
+		if _, err := src.Read(context.Background()); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+
+	t.Run("unknown ca", func(t *testing.T) {
+		src := &EtcdSource{Endpoint: srv.URL, Key: "/myapp/", Prefix: true}
+		if _, err := src.Read(context.Background()); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+}
+
+func Test_cfg_Load_EtcdSource(t *testing.T) {
+	srv := newEtcdServer(t, map[string]string{
+		"/myapp/server/port": "8080",
+		"/myapp/log/level":   "debug",
+	})
+
+	type Config struct {
+		Server struct {
+			Host string `cfg:"host" default:"localhost"`
+			Port int    `cfg:"port"`
+		} `cfg:"server"`
+		Log struct {
+			Level string `cfg:"level"`
+		} `cfg:"log"`
+	}
+
+	setenv(t, "APP_LOG_LEVEL", "warn")
+
+	var cfg Config
+	err := Load(&cfg, IgnoreFile(), UseEnv("app"), WithSources(&EtcdSource{Endpoint: srv.URL, Key: "/myapp/", Prefix: true}))
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if cfg.Server.Host != "localhost" || cfg.Server.Port != 8080 || cfg.Log.Level != "warn" {
+		t.Errorf("unexpected cfg %+v", cfg)
+	}
+}
+
+func Test_prefixRangeEnd(t *testing.T) {
+	for _, tc := range []struct {
+		prefix, want []byte
+	}{
+		{[]byte("/a/"), []byte("/a0")},
+		{[]byte{'a', 0xff}, []byte{'b'}},
+		{[]byte{0xff}, []byte{0}},
+	} {
+		if got := prefixRangeEnd(tc.prefix); !bytes.Equal(tc.want, got) {
+			t.Errorf("%q: want %q, got %q", tc.prefix, tc.want, got)
+		}
+	}
+}
+
+func Test_EtcdSource_Put(t *testing.T) {
+	var _ WritableSource = &EtcdSource{}
+
+	t.Run("prefix", func(t *testing.T) {
+		srv := newEtcdServer(t, map[string]string{"/myapp/server/port": "8080"})
+		src := &EtcdSource{Endpoint: srv.URL, Key: "/myapp/", Prefix: true}
+		ctx := context.Background()
+
+		if err := src.Put(ctx, "server.port", 9090); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if err := src.Put(ctx, "server.host", "example.com"); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if err := src.Put(ctx, "server.port", 9091); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		vals, err := src.Read(ctx)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		want := map[string]interface{}{"server": map[string]interface{}{"host": "example.com", "port": "9091"}}
+		if !reflect.DeepEqual(want, vals) {
+			t.Errorf("\nwant %v\ngot  %v", want, vals)
+		}
+
+		srv.mu.Lock()
+		srv.put("/myapp/server/port", "1")
+		srv.mu.Unlock()
+		if err := src.Put(ctx, "server.port", 2); !errors.Is(err, ErrConflict) {
+			t.Errorf("want ErrConflict, got %v", err)
+		}
+	})
+
+	t.Run("prefix composite values", func(t *testing.T) {
+		type Config struct {
+			Hosts  []string          `cfg:"hosts"`
+			Labels map[string]string `cfg:"labels"`
+		}
+		srv := newEtcdServer(t, map[string]string{})
+		src := &EtcdSource{Endpoint: srv.URL, Key: "/myapp/", Prefix: true}
+		ctx := context.Background()
+
+		// admin tooling writes whole slices and maps of plain values.
+		if err := src.Put(ctx, "hosts", []interface{}{"a", "b,c"}); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if err := src.Put(ctx, "labels", map[string]interface{}{"team": "core"}); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		var cfg Config
+		if err := Load(&cfg, IgnoreFile(), WithSources(src)); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		want := Config{Hosts: []string{"a", "b,c"}, Labels: map[string]string{"team": "core"}}
+		if !reflect.DeepEqual(want, cfg) {
+			t.Errorf("want %+v, got %+v", want, cfg)
+		}
+	})
+
+	t.Run("key", func(t *testing.T) {
+		srv := newEtcdServer(t, map[string]string{"/myapp/config.json": `{"server": {"port": 8080}}`})
+		src := &EtcdSource{Endpoint: srv.URL, Key: "/myapp/config.json", Format: "json"}
+		ctx := context.Background()
+
+		if _, err := src.Read(ctx); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if err := src.Put(ctx, "server.host", "example.com"); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		vals, err := src.Read(ctx)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		want := map[string]interface{}{"server": map[string]interface{}{"host": "example.com", "port": float64(8080)}}
+		if !reflect.DeepEqual(want, vals) {
+			t.Errorf("\nwant %v\ngot  %v", want, vals)
+		}
+
+		srv.mu.Lock()
+		srv.put("/myapp/config.json", `{}`)
+		srv.mu.Unlock()
+		if err := src.Put(ctx, "server.port", 1); !errors.Is(err, ErrConflict) {
+			t.Errorf("want ErrConflict, got %v", err)
+		}
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		srv := newEtcdServer(t, map[string]string{"/myapp/config.toml": "a = 1"})
+		src := &EtcdSource{Endpoint: srv.URL, Key: "/myapp/config.toml", Format: "toml"}
+		if err := src.Put(context.Background(), "a", 2); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+}
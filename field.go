@@ -19,13 +19,37 @@ func flattenCfg(cfg interface{}, tagKey string) []*field {
 	return fs
 }
 
+// lookupField returns the field of cfg at path, or nil if there is none.
+func lookupField(cfg interface{}, path, tagKey string) *field {
+	for _, f := range flattenCfg(cfg, tagKey) {
+		if f.path() == path {
+			return f
+		}
+	}
+	return nil
+}
+
 // flattenField recursively flattens a field into its
 // constituent fields, filling fs as it goes.
 func flattenField(f *field, fs *[]*field, tagKey string) {
-	for (f.v.Kind() == reflect.Ptr || f.v.Kind() == reflect.Interface) && !f.v.IsNil() {
-		f.v = f.v.Elem()
-		f.t = f.v.Type()
+	v := f.v
+	for (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	// pointers to scalars are kept as they are so that they're set, checked
+	// and defaulted the same way whether or not they were allocated by the
+	// config file.
+	switch v.Kind() {
+	case reflect.Struct:
+		if isScalarStruct(v.Type()) {
+			return
+		}
+	case reflect.Slice, reflect.Array:
+	default:
+		return
 	}
+	f.v, f.t = v, v.Type()
 
 	switch f.v.Kind() {
 	case reflect.Struct:
@@ -148,13 +172,62 @@ func parseTag(tag reflect.StructTag, key string) (st structTag) {
 		st.defaultVal = val
 	}
 
+	if val := tag.Get("path"); val == "true" {
+		st.isPath = true
+	}
+
+	if val := tag.Get("reload"); val == ReloadRestartRequired {
+		st.restartRequired = true
+	}
+
+	if val := tag.Get("metric"); val == "true" {
+		st.metric = true
+	}
+
+	if val := tag.Get("secret"); val == "true" {
+		st.secret = true
+	}
+
+	if val := tag.Get("source"); val != "" {
+		for _, kind := range strings.Split(val, ",") {
+			st.sources = append(st.sources, strings.TrimSpace(kind))
+		}
+	}
+
+	if val := tag.Get("env"); val == "-" {
+		st.noEnv = true
+	} else if val != "" {
+		st.envName = val
+	}
+
+	if val := tag.Get("sep"); val != "" {
+		st.sep = val
+	}
+
+	if val := tag.Get("transform"); val != "" {
+		for _, name := range strings.Split(val, ",") {
+			st.transforms = append(st.transforms, strings.TrimSpace(name))
+		}
+	}
+
 	return
 }
 
 // structTag contains information gathered from parsing a field's tags.
 type structTag struct {
-	altName    string // the alt name of the field as defined in the tag.
-	required   bool   // true if the tag contained a required validation key.
-	setDefault bool   // true if tag contained a default key.
-	defaultVal string // the value of the default key.
+	altName    string   // the alt name of the field as defined in the tag.
+	required   bool     // true if the tag contained a required validation key.
+	setDefault bool     // true if tag contained a default key.
+	defaultVal string   // the value of the default key.
+	transforms []string // the names of the transformers in the transform key.
+	isPath     bool     // true if the tag contained a path key set to true.
+
+	restartRequired bool // true if the tag contained a reload key set to restart-required.
+	metric          bool // true if the tag contained a metric key set to true.
+	secret          bool // true if the tag contained a secret key set to true.
+
+	sources []string // the kinds of origins allowed by the source key.
+	envName string   // the name of the env var of the field as defined in the env key.
+	noEnv   bool     // true if the tag contained an env key set to -.
+	sep     string   // the separator of the elements of slices set from strings, as defined in the sep key.
 }
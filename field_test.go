@@ -155,6 +155,46 @@ func Test_parseTag(t *testing.T) {
 			tagVal: `cfg:"c,omitempty"`,
 			want:   structTag{altName: "c"},
 		},
+		{
+			tagVal: `cfg:"d" transform:"trimspace, lower"`,
+			want:   structTag{altName: "d", transforms: []string{"trimspace", "lower"}},
+		},
+		{
+			tagVal: `path:"true"`,
+			want:   structTag{isPath: true},
+		},
+		{
+			tagVal: `reload:"restart-required"`,
+			want:   structTag{restartRequired: true},
+		},
+		{
+			tagVal: `reload:"hot"`,
+			want:   structTag{},
+		},
+		{
+			tagVal: `metric:"true"`,
+			want:   structTag{metric: true},
+		},
+		{
+			tagVal: `secret:"true"`,
+			want:   structTag{secret: true},
+		},
+		{
+			tagVal: `source:"env, source"`,
+			want:   structTag{sources: []string{"env", "source"}},
+		},
+		{
+			tagVal: `cfg:"url" env:"DATABASE_URL"`,
+			want:   structTag{altName: "url", envName: "DATABASE_URL"},
+		},
+		{
+			tagVal: `cfg:"url" env:"-"`,
+			want:   structTag{altName: "url", noEnv: true},
+		},
+		{
+			tagVal: `cfg:"urls" sep:";"`,
+			want:   structTag{altName: "urls", sep: ";"},
+		},
 	} {
 		t.Run(tc.tagVal, func(t *testing.T) {
 			tag := parseTag(reflect.StructTag(tc.tagVal), "cfg")
@@ -0,0 +1,104 @@
+package cfg
+
+import (
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// snapshot holds the hashes of the fields of a frozen config struct, by
+// field path.
+type snapshot struct {
+	tag    string
+	hashes map[string]uint64
+}
+
+var (
+	frozenMu sync.Mutex
+	frozen   = map[interface{}]snapshot{}
+)
+
+// freeze records a snapshot of the fields of cfg to be checked by
+// CheckUnchanged.
+func (f *cfg) freeze(cfg interface{}) {
+	frozenMu.Lock()
+	defer frozenMu.Unlock()
+	frozen[cfg] = snapshot{tag: f.tag, hashes: hashFields(cfg, f.tag)}
+}
+
+// CheckUnchanged reports whether any field of cfg changed since it was
+// loaded with the `Freeze()` option. cfg must be the same pointer that
+// was passed to Load. The returned error wraps ErrMutated and lists the
+// paths of the mutated fields.
+//
+// Use it in tests to catch code that writes into shared config structs:
+//
+//	if err := cfg.CheckUnchanged(&conf); err != nil {
+//	  t.Error(err) // config mutated: server.port
+//	}
+func CheckUnchanged(cfg interface{}) error {
+	frozenMu.Lock()
+	snap, ok := frozen[cfg]
+	frozenMu.Unlock()
+	if !ok {
+		return fmt.Errorf("cfg was not loaded with Freeze()")
+	}
+
+	hashes := hashFields(cfg, snap.tag)
+	var changed []string
+	for path, h := range snap.hashes {
+		if now, ok := hashes[path]; !ok || now != h {
+			changed = append(changed, path)
+		}
+	}
+	for path := range hashes {
+		if _, ok := snap.hashes[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrMutated, strings.Join(innermostPaths(changed), ", "))
+}
+
+// hashFields hashes the value of each field of cfg, by field path.
+func hashFields(cfg interface{}, tag string) map[string]uint64 {
+	hashes := make(map[string]uint64)
+	for _, field := range flattenCfg(cfg, tag) {
+		if !field.v.CanInterface() {
+			continue
+		}
+		h := fnv.New64a()
+		v := reflect.Indirect(field.v)
+		if v.IsValid() {
+			fmt.Fprintf(h, "%#v", v.Interface())
+		}
+		hashes[field.path()] = h.Sum64()
+	}
+	return hashes
+}
+
+// innermostPaths returns the sorted paths that are not the parent of any
+// other path, as the change of a field also changes its parents.
+func innermostPaths(paths []string) []string {
+	sort.Strings(paths)
+	var innermost []string
+	for i, p := range paths {
+		parent := false
+		for _, q := range paths[i+1:] {
+			if strings.HasPrefix(q, p+".") || strings.HasPrefix(q, p+"[") {
+				parent = true
+				break
+			}
+		}
+		if !parent {
+			innermost = append(innermost, p)
+		}
+	}
+	return innermost
+}
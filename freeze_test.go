@@ -0,0 +1,85 @@
+package cfg
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_CheckUnchanged(t *testing.T) {
+	type Server struct {
+		Host string `cfg:"host"`
+		Port int    `cfg:"port"`
+	}
+	type Config struct {
+		Server  Server   `cfg:"server"`
+		Peers   []Server `cfg:"peers"`
+		Tags    []string `cfg:"tags"`
+		Timeout *int     `cfg:"timeout"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), `
+server:
+  host: localhost
+  port: 80
+peers:
+  - host: a
+tags: [x, y]
+timeout: 5
+`)
+
+	load := func(t *testing.T) *Config {
+		t.Helper()
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir), Freeze()); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		return &cfg
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		cfg := load(t)
+		if err := CheckUnchanged(cfg); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+
+	t.Run("mutated", func(t *testing.T) {
+		cfg := load(t)
+		cfg.Server.Port = 8080
+		cfg.Peers[0].Host = "b"
+		cfg.Tags = append(cfg.Tags, "z")
+		*cfg.Timeout = 10
+
+		err := CheckUnchanged(cfg)
+		if !errors.Is(err, ErrMutated) {
+			t.Fatalf("want ErrMutated, got %v", err)
+		}
+		want := "config mutated: peers[0].host, server.port, tags, timeout"
+		if err.Error() != want {
+			t.Errorf("\nwant %s\ngot  %s", want, err)
+		}
+	})
+
+	t.Run("element appended", func(t *testing.T) {
+		cfg := load(t)
+		cfg.Peers = append(cfg.Peers, Server{Host: "c"})
+
+		err := CheckUnchanged(cfg)
+		if err == nil || !strings.Contains(err.Error(), "peers[1].host") {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+
+	t.Run("not frozen", func(t *testing.T) {
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir)); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if err := CheckUnchanged(&cfg); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package cfg
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// open opens the named file from the configured file system, or from the
+// OS if none is configured, or downloads it if it's an object URL.
+// Compressed files are decompressed. Opening fails if the file does not
+// match the expected digests, or if it exceeds the max file size, if any,
+// once decompressed.
+func (f *cfg) open(name string) (io.ReadCloser, error) {
+	var (
+		rc  io.ReadCloser
+		err error
+	)
+	switch {
+	case isObjectURL(name):
+		rc, err = f.openObject(name)
+	case f.fsys == nil:
+		rc, err = os.Open(name)
+	default:
+		rc, err = f.fsys.Open(filepath.ToSlash(name))
+	}
+	if err != nil {
+		return nil, err
+	}
+	verified, err := f.verifyDigest(rc, name)
+	if err != nil {
+		rc.Close()
+		return nil, err
+	}
+	if rc, err = decompress(readCloser{Reader: verified, Closer: rc}, name); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if f.maxFileSize <= 0 {
+		return rc, nil
+	}
+	defer rc.Close()
+
+	r, err := f.limit(rc)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(r), nil
+}
+
+// fileExists returns true if the named file exists in the configured file
+// system, or in the OS if none is configured, and is not a directory, or
+// if it's the URL of an existing object. Failures to check objects are
+// returned as errors.
+func (f *cfg) fileExists(name string) (bool, error) {
+	if isObjectURL(name) {
+		return f.objectExists(name)
+	}
+	return f.localFileExists(name), nil
+}
+
+// localFileExists is fileExists for files that are not objects.
+func (f *cfg) localFileExists(name string) bool {
+	if f.fsys == nil {
+		return fileExists(name)
+	}
+	info, err := fs.Stat(f.fsys, filepath.ToSlash(name))
+	if err != nil {
+		return false
+	}
+	return !info.IsDir()
+}
+
+// isGlob reports whether name is a glob pattern.
+func isGlob(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+// glob returns the files matching pattern in the configured file system,
+// or in the OS if none is configured, in lexical order. Directories are
+// not returned.
+func (f *cfg) glob(pattern string) ([]string, error) {
+	var (
+		matches []string
+		err     error
+	)
+	if f.fsys == nil {
+		matches, err = filepath.Glob(pattern)
+	} else {
+		matches, err = fs.Glob(f.fsys, filepath.ToSlash(pattern))
+		for i := range matches {
+			matches[i] = filepath.FromSlash(matches[i])
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+
+	files := matches[:0]
+	for _, m := range matches {
+		if f.localFileExists(m) {
+			files = append(files, m)
+		}
+	}
+	return files, nil
+}
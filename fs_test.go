@@ -0,0 +1,39 @@
+package cfg
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func Test_cfg_Load_FS(t *testing.T) {
+	type Config struct {
+		Host  string `cfg:"host"`
+		Port  int    `cfg:"port"`
+		Level string `cfg:"level"`
+	}
+
+	fsys := fstest.MapFS{
+		"conf/config.yaml":          {Data: []byte("host: localhost\nport: 80\n")},
+		"conf/.env":                 {Data: []byte("APP_LEVEL=debug\n")},
+		"conf/scopes/tenant-1.yaml": {Data: []byte("port: 8001\n")},
+	}
+
+	t.Run("files", func(t *testing.T) {
+		var cfg Config
+		err := Load(&cfg, FS(fsys), Dirs("conf"), File("config.yaml"), File(".env"), UseEnv("app"), Scope("tenant-1"))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		want := Config{Host: "localhost", Port: 8001, Level: "debug"}
+		if cfg != want {
+			t.Errorf("\nwant %+v\ngot  %+v", want, cfg)
+		}
+	})
+
+	t.Run("file not found", func(t *testing.T) {
+		var cfg Config
+		if err := Load(&cfg, FS(fsys), Dirs(".")); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+}
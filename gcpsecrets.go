@@ -0,0 +1,177 @@
+package cfg
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+// GCPSecretSource is a source that resolves secret versions of Google
+// Secret Manager into config fields, e.g. to keep database credentials
+// out of config files.
+//
+//	&cfg.GCPSecretSource{
+//	  Project: "my-project",
+//	  Secrets: map[string]string{
+//	    "db.password": "db-password",        // latest version
+//	    "api.key":     "api-key/versions/3", // pinned version
+//	  },
+//	}
+//
+// Requests are authorized with Token, or else with a token of the service
+// account obtained from the GCE metadata server, which is how GKE workloads
+// using workload identity are authenticated.
+type GCPSecretSource struct {
+	// Project is the ID of the project of the secrets. Defaults to the
+	// `GOOGLE_CLOUD_PROJECT` env var, or else the project reported by the
+	// metadata server.
+	Project string
+	// Secrets maps paths of config fields to the secret versions they are
+	// set to. A secret is given by its name, optionally followed by
+	// `/versions/<version>`, or by its full resource name. The latest
+	// version is used unless a version is given.
+	Secrets map[string]string
+
+	Token            string       // OAuth2 access token. Defaults to the token of the metadata server.
+	Endpoint         string       // URL of the Secret Manager API. Defaults to https://secretmanager.googleapis.com.
+	MetadataEndpoint string       // URL of the metadata server. Defaults to the `GCE_METADATA_HOST` env var or http://metadata.google.internal.
+	Client           *http.Client // client used for requests. Defaults to http.DefaultClient.
+	Proxy            string       // URL of the proxy of requests to Endpoint. Defaults to that of the `HTTPS_PROXY` env var.
+	TLS              *tls.Config  // TLS config of requests to Endpoint, e.g. with a client certificate and CAs for mTLS.
+}
+
+// Read returns the values of the secrets, nested at the paths of their
+// fields.
+func (s *GCPSecretSource) Read(ctx context.Context) (map[string]interface{}, error) {
+	paths := make([]string, 0, len(s.Secrets))
+	for path := range s.Secrets {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	project, token := s.Project, s.Token
+	if project == "" {
+		project = os.Getenv("GOOGLE_CLOUD_PROJECT")
+	}
+
+	vals := make(map[string]interface{})
+	for _, path := range paths {
+		name := s.Secrets[path]
+		if !strings.HasPrefix(name, "projects/") {
+			if project == "" {
+				p, err := gcpMetadata(ctx, s.Client, s.MetadataEndpoint, "project/project-id")
+				if err != nil {
+					return nil, fmt.Errorf("gcp secret %q: project is required: %w", name, err)
+				}
+				project = p
+			}
+			name = "projects/" + project + "/secrets/" + name
+		}
+		if !strings.Contains(name, "/versions/") {
+			name += "/versions/latest"
+		}
+
+		if token == "" {
+			t, err := gcpAccessToken(ctx, s.Client, s.MetadataEndpoint)
+			if err != nil {
+				return nil, fmt.Errorf("gcp secret %q: %w", name, err)
+			}
+			token = t
+		}
+
+		secret, err := s.accessSecretVersion(ctx, name, token)
+		if err != nil {
+			return nil, fmt.Errorf("gcp secret %q: %w", name, err)
+		}
+		if err := setPath(vals, strings.Split(path, "."), secret); err != nil {
+			return nil, fmt.Errorf("gcp secret %q: %w", name, err)
+		}
+	}
+	return vals, nil
+}
+
+// String returns the name of the source, e.g. in the Provenance of a
+// Result.
+func (s *GCPSecretSource) String() string {
+	return "gcp-secret:" + s.Project
+}
+
+// accessSecretVersion returns the payload of the secret version name.
+func (s *GCPSecretSource) accessSecretVersion(ctx context.Context, name, token string) (string, error) {
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = "https://secretmanager.googleapis.com"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(endpoint, "/")+"/v1/"+name+":access", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var out struct {
+		Payload struct {
+			Data []byte `json:"data"`
+		} `json:"payload"`
+	}
+	client, err := sourceClient(ctx, s.Client, s.Endpoint != "", s.Proxy, s.TLS)
+	if err != nil {
+		return "", err
+	}
+	if err := doRequest(client, req, &out); err != nil {
+		return "", err
+	}
+	return string(out.Payload.Data), nil
+}
+
+// gcpAccessToken returns an access token of the default service account
+// from the metadata server at endpoint, requested with client, if not nil.
+// The client of WithHTTPClient is never used.
+func gcpAccessToken(ctx context.Context, client *http.Client, endpoint string) (string, error) {
+	tok, err := gcpMetadata(ctx, client, endpoint, "instance/service-account/default/token")
+	if err != nil {
+		return "", fmt.Errorf("unable to get token: %w", err)
+	}
+
+	var out struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal([]byte(tok), &out); err != nil {
+		return "", fmt.Errorf("unable to get token: %w", err)
+	}
+	if out.AccessToken == "" {
+		return "", fmt.Errorf("unable to get token: empty token")
+	}
+	return out.AccessToken, nil
+}
+
+// gcpMetadata returns the value at path of the metadata server at
+// endpoint. The endpoint defaults to the host of the `GCE_METADATA_HOST`
+// env var, or else http://metadata.google.internal. The value is requested
+// with client, if not nil, and never with the client of WithHTTPClient.
+func gcpMetadata(ctx context.Context, client *http.Client, endpoint, path string) (string, error) {
+	if endpoint == "" {
+		endpoint = "http://metadata.google.internal"
+		if host := os.Getenv("GCE_METADATA_HOST"); host != "" {
+			endpoint = "http://" + host
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(endpoint, "/")+"/computeMetadata/v1/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	var out bytes.Buffer
+	if err := doRequest(metadataClient(client), req, &out); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out.String()), nil
+}
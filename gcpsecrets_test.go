@@ -0,0 +1,129 @@
+package cfg
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_GCPSecretSource(t *testing.T) {
+	secrets := map[string]string{
+		"projects/proj/secrets/db-password/versions/latest": "hunter2",
+		"projects/proj/secrets/api-key/versions/3":          "key3",
+	}
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			http.Error(w, "unauthenticated", http.StatusUnauthorized)
+			return
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/"), ":access")
+		secret, ok := secrets[name]
+		if !ok {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"name": %q, "payload": {"data": %q}}`, name, base64.StdEncoding.EncodeToString([]byte(secret)))
+	}))
+	t.Cleanup(api.Close)
+
+	metadata := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			http.Error(w, "missing header", http.StatusForbidden)
+			return
+		}
+		if r.Header.Get("X-Api-Key") != "" {
+			http.Error(w, "credentials of the caller", http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/computeMetadata/v1/project/project-id":
+			fmt.Fprint(w, "proj")
+		case "/computeMetadata/v1/instance/service-account/default/token":
+			fmt.Fprint(w, `{"access_token": "tok", "expires_in": 3599, "token_type": "Bearer"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(metadata.Close)
+
+	type Config struct {
+		DB struct {
+			Host     string `cfg:"host" default:"localhost"`
+			Password string `cfg:"password" validate:"required"`
+		} `cfg:"db"`
+		APIKey string `cfg:"api_key"`
+	}
+
+	t.Run("workload identity", func(t *testing.T) {
+		setenv(t, "GOOGLE_CLOUD_PROJECT", "")
+
+		var cfg Config
+		err := Load(&cfg, IgnoreFile(), WithSources(&GCPSecretSource{
+			Secrets: map[string]string{
+				"db.password": "db-password",
+				"api_key":     "projects/proj/secrets/api-key/versions/3",
+			},
+			Endpoint:         api.URL,
+			MetadataEndpoint: metadata.URL,
+		}))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.DB.Host != "localhost" || cfg.DB.Password != "hunter2" || cfg.APIKey != "key3" {
+			t.Errorf("unexpected cfg %+v", cfg)
+		}
+	})
+
+	t.Run("metadata without client of caller", func(t *testing.T) {
+		client, err := (&HTTPClient{Headers: map[string]string{"X-Api-Key": "caller"}}).Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var cfg Config
+		err = Load(&cfg, IgnoreFile(), WithHTTPClient(client), WithSources(&GCPSecretSource{
+			Project:          "proj",
+			Secrets:          map[string]string{"db.password": "db-password"},
+			Endpoint:         api.URL,
+			MetadataEndpoint: metadata.URL,
+		}))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+
+	t.Run("token", func(t *testing.T) {
+		setenv(t, "GOOGLE_CLOUD_PROJECT", "proj")
+
+		src := &GCPSecretSource{
+			Secrets:  map[string]string{"api_key": "api-key/versions/3"},
+			Token:    "tok",
+			Endpoint: api.URL,
+		}
+		vals, err := src.Read(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if vals["api_key"] != "key3" {
+			t.Errorf("unexpected vals %v", vals)
+		}
+	})
+
+	t.Run("missing secret", func(t *testing.T) {
+		src := &GCPSecretSource{
+			Project:  "proj",
+			Secrets:  map[string]string{"db.password": "nope"},
+			Token:    "tok",
+			Endpoint: api.URL,
+		}
+		_, err := src.Read(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "404") {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+}
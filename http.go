@@ -0,0 +1,221 @@
+package cfg
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// HTTPServer is a reusable config section for HTTP server settings.
+//
+//	type Config struct {
+//	  HTTP cfg.HTTPServer `cfg:"http"`
+//	}
+//
+// The section is validated when the config is loaded. Use Apply to
+// configure an *http.Server with it.
+type HTTPServer struct {
+	ReadTimeout       time.Duration `cfg:"read_timeout" default:"30s"`
+	ReadHeaderTimeout time.Duration `cfg:"read_header_timeout" default:"10s"`
+	WriteTimeout      time.Duration `cfg:"write_timeout" default:"30s"`
+	IdleTimeout       time.Duration `cfg:"idle_timeout" default:"2m"`
+	// ShutdownTimeout is how long the server is given to shut down
+	// gracefully. It is not used by Apply.
+	ShutdownTimeout time.Duration `cfg:"shutdown_timeout" default:"30s"`
+	MaxHeaderBytes  int           `cfg:"max_header_bytes" default:"1048576"`
+	// MaxBodyBytes limits the size of request bodies. 0 means no limit.
+	MaxBodyBytes int64 `cfg:"max_body_bytes"`
+	// TLS enables TLS on the server if set.
+	TLS *TLS `cfg:"tls"`
+}
+
+// Validate checks that the settings are valid.
+func (s *HTTPServer) Validate() error {
+	if s.ReadTimeout < 0 || s.ReadHeaderTimeout < 0 || s.WriteTimeout < 0 || s.IdleTimeout < 0 || s.ShutdownTimeout < 0 {
+		return fmt.Errorf("timeouts must not be negative")
+	}
+	if s.MaxHeaderBytes < 0 || s.MaxBodyBytes < 0 {
+		return fmt.Errorf("limits must not be negative")
+	}
+	return nil
+}
+
+// Apply validates the settings and applies them to srv. If MaxBodyBytes is
+// set then srv.Handler, which must be set beforehand, is wrapped to limit
+// the size of request bodies.
+func (s *HTTPServer) Apply(srv *http.Server) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	srv.ReadTimeout = s.ReadTimeout
+	srv.ReadHeaderTimeout = s.ReadHeaderTimeout
+	srv.WriteTimeout = s.WriteTimeout
+	srv.IdleTimeout = s.IdleTimeout
+	srv.MaxHeaderBytes = s.MaxHeaderBytes
+
+	if s.MaxBodyBytes > 0 {
+		if srv.Handler == nil {
+			return fmt.Errorf("max_body_bytes requires the server handler to be set")
+		}
+		srv.Handler = http.MaxBytesHandler(srv.Handler, s.MaxBodyBytes)
+	}
+
+	if s.TLS != nil {
+		conf, err := s.TLS.Build()
+		if err != nil {
+			return fmt.Errorf("tls: %w", err)
+		}
+		srv.TLSConfig = conf
+	}
+
+	return nil
+}
+
+// HTTPClient is a reusable config section for HTTP client settings.
+//
+//	type Config struct {
+//	  Upstream cfg.HTTPClient `cfg:"upstream"`
+//	}
+//
+// The section is validated when the config is loaded. Use Build to create
+// an *http.Client from it.
+type HTTPClient struct {
+	Timeout time.Duration `cfg:"timeout" default:"30s"`
+	// Proxy is the URL of the proxy to use. If empty the proxy is taken
+	// from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+	Proxy string `cfg:"proxy"`
+	// Retries is the number of times that idempotent requests are retried
+	// after a network error or a 429 or 5xx response.
+	Retries int `cfg:"retries"`
+	// RetryBackoff is the delay before the first retry. It doubles with
+	// every subsequent retry.
+	RetryBackoff time.Duration `cfg:"retry_backoff" default:"100ms"`
+	// TLS configures TLS for the client if set.
+	TLS *TLS `cfg:"tls"`
+	// Headers are added to every request that doesn't set them, e.g. an
+	// Authorization header, and so are secret.
+	Headers map[string]string `cfg:"headers" secret:"true"`
+}
+
+// Validate checks that the settings are valid.
+func (c *HTTPClient) Validate() error {
+	if c.Timeout < 0 || c.RetryBackoff < 0 {
+		return fmt.Errorf("durations must not be negative")
+	}
+	if c.Retries < 0 {
+		return fmt.Errorf("retries must not be negative")
+	}
+	if c.Proxy != "" {
+		if _, err := url.Parse(c.Proxy); err != nil {
+			return fmt.Errorf("invalid proxy: %w", err)
+		}
+	}
+	return nil
+}
+
+// Build validates the settings and creates an *http.Client from them.
+func (c *HTTPClient) Build() (*http.Client, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	//nolint:forcetypeassert
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if c.Proxy != "" {
+		proxy, _ := url.Parse(c.Proxy)
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if c.TLS != nil {
+		conf, err := c.TLS.Build()
+		if err != nil {
+			return nil, fmt.Errorf("tls: %w", err)
+		}
+		transport.TLSClientConfig = conf
+	}
+
+	var rt http.RoundTripper = transport
+	if c.Retries > 0 {
+		rt = &retryTransport{next: rt, retries: c.Retries, backoff: c.RetryBackoff}
+	}
+	if len(c.Headers) > 0 {
+		rt = &headerTransport{next: rt, headers: c.Headers}
+	}
+
+	return &http.Client{Timeout: c.Timeout, Transport: rt}, nil
+}
+
+// retryTransport is an http.RoundTripper that retries idempotent requests.
+// Retries are sent as clones of the request, with a new body from GetBody,
+// so that the request of the caller is not modified.
+type retryTransport struct {
+	next    http.RoundTripper
+	retries int
+	backoff time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isIdempotent(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	backoff := t.backoff
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 {
+			r = req.Clone(req.Context())
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				r.Body = body
+			}
+		}
+
+		resp, err := t.next.RoundTrip(r)
+		retry := err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		if !retry || attempt == t.retries {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// headerTransport is an http.RoundTripper that adds headers to requests.
+type headerTransport struct {
+	next    http.RoundTripper
+	headers map[string]string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		if req.Header.Get(k) == "" {
+			req.Header.Set(k, v)
+		}
+	}
+	return t.next.RoundTrip(req)
+}
+
+// isIdempotent reports whether req can safely be retried.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	default:
+		return false
+	}
+}
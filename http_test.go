@@ -0,0 +1,203 @@
+package cfg
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_cfg_Load_HTTP(t *testing.T) {
+	type Config struct {
+		Server HTTPServer `cfg:"server"`
+		Client HTTPClient `cfg:"client"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), `
+server:
+  read_timeout: 5s
+  max_body_bytes: 1024
+client:
+  retries: 2
+`)
+
+	var cfg Config
+	if err := Load(&cfg, Dirs(dir)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	srv := &http.Server{Handler: http.NotFoundHandler()}
+	if err := cfg.Server.Apply(srv); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if srv.ReadTimeout != 5*time.Second || srv.WriteTimeout != 30*time.Second ||
+		srv.IdleTimeout != 2*time.Minute || srv.MaxHeaderBytes != 1<<20 {
+		t.Errorf("unexpected server: %+v", srv)
+	}
+
+	client, err := cfg.Client.Build()
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if client.Timeout != 30*time.Second {
+		t.Errorf("want timeout 30s, got %s", client.Timeout)
+	}
+	if _, ok := client.Transport.(*retryTransport); !ok {
+		t.Errorf("want retry transport, got %T", client.Transport)
+	}
+
+	t.Run("validation error", func(t *testing.T) {
+		writeFile(t, filepath.Join(dir, "config.yaml"), "client:\n  retries: -1\n")
+
+		var cfg Config
+		err := Load(&cfg, Dirs(dir))
+		if err == nil || !strings.Contains(err.Error(), "client: retries must not be negative") {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+}
+
+func TestHTTPServer_Apply(t *testing.T) {
+	t.Run("max body bytes requires handler", func(t *testing.T) {
+		s := HTTPServer{MaxBodyBytes: 1}
+		if err := s.Apply(&http.Server{}); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+
+	t.Run("tls", func(t *testing.T) {
+		cert, key := testCertificate(t)
+		s := HTTPServer{TLS: &TLS{Cert: cert, Key: key}}
+		srv := &http.Server{}
+		if err := s.Apply(srv); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if srv.TLSConfig == nil || len(srv.TLSConfig.Certificates) != 1 {
+			t.Errorf("want tls config to be set, got %+v", srv.TLSConfig)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		s := HTTPServer{IdleTimeout: -1}
+		if err := s.Apply(&http.Server{}); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+}
+
+func TestHTTPClient_Build(t *testing.T) {
+	t.Run("retries", func(t *testing.T) {
+		var calls int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if body, _ := io.ReadAll(r.Body); r.Method == http.MethodPut && string(body) != "x" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if atomic.AddInt32(&calls, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+
+		c := HTTPClient{Retries: 2, RetryBackoff: time.Millisecond}
+		client, err := c.Build()
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		resp.Body.Close()
+		if n := atomic.LoadInt32(&calls); resp.StatusCode != http.StatusOK || n != 3 {
+			t.Errorf("want 200 after 3 calls, got %d after %d", resp.StatusCode, n)
+		}
+
+		atomic.StoreInt32(&calls, 0)
+		req, _ := http.NewRequest(http.MethodPut, srv.URL, strings.NewReader("x"))
+		body := req.Body
+		resp, err = client.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		resp.Body.Close()
+		if n := atomic.LoadInt32(&calls); resp.StatusCode != http.StatusOK || n != 3 {
+			t.Errorf("want 200 after 3 calls, got %d after %d", resp.StatusCode, n)
+		}
+		if req.Body != body {
+			t.Error("request body was replaced")
+		}
+
+		atomic.StoreInt32(&calls, 0)
+		resp, err = client.Post(srv.URL, "text/plain", strings.NewReader("x"))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		resp.Body.Close()
+		if n := atomic.LoadInt32(&calls); n != 1 {
+			t.Errorf("want non-idempotent request to not be retried, got %d calls", n)
+		}
+	})
+
+	t.Run("proxy", func(t *testing.T) {
+		c := HTTPClient{Proxy: "http://proxy.internal:3128"}
+		client, err := c.Build()
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+		proxy, err := client.Transport.(*http.Transport).Proxy(req)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if want, _ := url.Parse(c.Proxy); proxy.String() != want.String() {
+			t.Errorf("want proxy %s, got %s", want, proxy)
+		}
+	})
+
+	t.Run("headers", func(t *testing.T) {
+		var auth, agent string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auth, agent = r.Header.Get("Authorization"), r.Header.Get("User-Agent")
+		}))
+		defer srv.Close()
+
+		c := HTTPClient{Headers: map[string]string{"Authorization": "Bearer t0k3n", "User-Agent": "cfg"}}
+		client, err := c.Build()
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		req.Header.Set("User-Agent", "myapp")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		resp.Body.Close()
+		if auth != "Bearer t0k3n" || agent != "myapp" {
+			t.Errorf("want added auth and request agent, got %q and %q", auth, agent)
+		}
+		if len(req.Header) != 1 {
+			t.Errorf("request headers were modified: %v", req.Header)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, c := range []HTTPClient{{Timeout: -1}, {Retries: -1}, {Proxy: "://"}} {
+			if _, err := c.Build(); err == nil {
+				t.Errorf("%+v: expected err", c)
+			}
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package cfg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"strconv"
+)
+
+// InheritEnv is the env var that passes a config from Inherit to the
+// Inherited option of a child process.
+const InheritEnv = "CFG_INHERITED"
+
+// InheritFDEnv is the env var that names the file descriptor from which the
+// Inherited option of a child process reads the config passed by
+// InheritPipe.
+const InheritFDEnv = "CFG_INHERITED_FD"
+
+// Inherit passes the values of cfg, a pointer to a loaded config struct, to
+// the child process of cmd through the env var InheritEnv, e.g. to re-exec
+// the running binary for a zero-downtime restart. The child loads them with
+// the Inherited option so that it starts with the config of its parent even
+// if the config files have changed since:
+//
+//	cmd := exec.Command(os.Args[0], os.Args[1:]...)
+//	if err := cfg.Inherit(cmd, &conf); err != nil {
+//	  return err
+//	}
+//
+// cmd inherits the env of the current process if its Env is nil. options
+// must be those cfg was loaded with. Fields tagged `secret:"true"` are
+// passed as well, and the env of a process can be read by other processes
+// of the same user: use InheritPipe to keep secrets out of the env.
+func Inherit(cmd *exec.Cmd, cfg interface{}, options ...Option) error {
+	data, err := inheritedData(cfg, options)
+	if err != nil {
+		return err
+	}
+	setCmdEnv(cmd, InheritEnv, string(data))
+	return nil
+}
+
+// InheritPipe passes the values of cfg to the child process of cmd like
+// Inherit, but through a pipe added to cmd.ExtraFiles rather than the env,
+// so that fields tagged `secret:"true"` don't show in the env of the
+// child. Only the number of the file descriptor of the pipe is passed, in
+// the env var InheritFDEnv. It is not supported on Windows.
+//
+// The values are written to the pipe as the child reads them. Close the
+// read end of the pipe, the last file of cmd.ExtraFiles, once cmd has
+// started:
+//
+//	cmd := exec.Command(os.Args[0], os.Args[1:]...)
+//	if err := cfg.InheritPipe(cmd, &conf); err != nil {
+//	  return err
+//	}
+//	err := cmd.Start()
+//	cmd.ExtraFiles[len(cmd.ExtraFiles)-1].Close()
+func InheritPipe(cmd *exec.Cmd, cfg interface{}, options ...Option) error {
+	data, err := inheritedData(cfg, options)
+	if err != nil {
+		return err
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	cmd.ExtraFiles = append(cmd.ExtraFiles, r)
+	// the files of ExtraFiles follow stdin, stdout and stderr in the child.
+	setCmdEnv(cmd, InheritFDEnv, strconv.Itoa(2+len(cmd.ExtraFiles)))
+
+	// the write fails once the read end is closed in both processes, so
+	// that it doesn't block forever if the child doesn't read it.
+	go func() {
+		_, _ = w.Write(data)
+		w.Close()
+	}()
+	return nil
+}
+
+// inheritedData returns the JSON of the values of cfg, including its
+// secrets.
+func inheritedData(cfg interface{}, options []Option) ([]byte, error) {
+	conf := defaultCfg()
+	for _, opt := range options {
+		opt(conf)
+	}
+	if !isStructPtr(cfg) {
+		return nil, fmt.Errorf("cfg must be a pointer to a struct")
+	}
+	return json.Marshal(conf.plain(reflect.ValueOf(cfg), true))
+}
+
+// setCmdEnv adds the env var key to the env of cmd, which inherits that of
+// the current process if its Env is nil.
+func setCmdEnv(cmd *exec.Cmd, key, val string) {
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, key+"="+val)
+}
+
+// loadInherited sets the config passed by Inherit or InheritPipe, if any,
+// as the input of the load in place of the config files, and removes its
+// env var.
+func (f *cfg) loadInherited() error {
+	var data []byte
+	if val, ok := os.LookupEnv(InheritEnv); ok {
+		os.Unsetenv(InheritEnv)
+		data = []byte(val)
+	} else if val, ok := os.LookupEnv(InheritFDEnv); ok {
+		os.Unsetenv(InheritFDEnv)
+		fd, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("%s: invalid file descriptor %q", InheritFDEnv, val)
+		}
+		file := os.NewFile(uintptr(fd), InheritFDEnv)
+		if file == nil {
+			return fmt.Errorf("%s: invalid file descriptor %q", InheritFDEnv, val)
+		}
+		defer file.Close()
+		if data, err = readLimited(file, f.maxFileSize); err != nil {
+			return fmt.Errorf("%s: %w", InheritFDEnv, err)
+		}
+	} else {
+		return nil
+	}
+
+	f.ignoreFile = true
+	f.reader = &readerInput{r: bytes.NewReader(data), format: ".json"}
+	return nil
+}
@@ -0,0 +1,85 @@
+package cfg
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_Inherit(t *testing.T) {
+	type Server struct {
+		Host string `cfg:"host"`
+		Port int    `cfg:"port"`
+	}
+	type Config struct {
+		Server   Server            `cfg:"server"`
+		Timeout  time.Duration     `cfg:"timeout" default:"5s"`
+		Start    time.Time         `cfg:"start"`
+		Labels   map[string]string `cfg:"labels"`
+		Password string            `cfg:"password" secret:"true"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "server:\n  host: localhost\n  port: 80\nstart: 2020-01-02T03:04:05Z\nlabels:\n  team: core\npassword: hunter2\n")
+
+	var cfg Config
+	if err := Load(&cfg, Dirs(dir)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	cmd := exec.Command("child")
+	cmd.Env = []string{"HOME=/home/child"}
+	if err := Inherit(cmd, &cfg); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(cmd.Env) != 2 || !strings.HasPrefix(cmd.Env[1], InheritEnv+"=") {
+		t.Fatalf("want %s appended to env, got %v", InheritEnv, cmd.Env)
+	}
+
+	// the child loads the inherited config even though the file changed.
+	writeFile(t, filepath.Join(dir, "config.yaml"), "server:\n  host: changed\n")
+	setenv(t, InheritEnv, strings.TrimPrefix(cmd.Env[1], InheritEnv+"="))
+
+	// options are applied before the load: the env var is only read by it.
+	opt := Inherited()
+	opt(defaultCfg())
+
+	var child Config
+	if err := Load(&child, Dirs(dir), opt); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if !reflect.DeepEqual(cfg, child) {
+		t.Errorf("want %+v, got %+v", cfg, child)
+	}
+
+	t.Run("env is consumed", func(t *testing.T) {
+		if _, ok := os.LookupEnv(InheritEnv); ok {
+			t.Fatalf("want %s to be unset", InheritEnv)
+		}
+		var reloaded Config
+		if err := Load(&reloaded, Dirs(dir), Inherited()); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if reloaded.Server.Host != "changed" {
+			t.Errorf("want host changed, got %q", reloaded.Server.Host)
+		}
+	})
+
+	t.Run("invalid fd", func(t *testing.T) {
+		setenv(t, InheritFDEnv, "x")
+		var child Config
+		if err := Load(&child, Dirs(dir), Inherited()); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+
+	t.Run("not a struct pointer", func(t *testing.T) {
+		if err := Inherit(exec.Command("child"), cfg); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+}
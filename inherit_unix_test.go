@@ -0,0 +1,63 @@
+//go:build unix
+
+package cfg
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func Test_InheritPipe(t *testing.T) {
+	type Config struct {
+		Host     string `cfg:"host"`
+		Password string `cfg:"password" secret:"true"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "host: localhost\npassword: hunter2\n")
+
+	var cfg Config
+	if err := Load(&cfg, Dirs(dir)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	cmd := exec.Command("child")
+	cmd.Env = []string{}
+	if err := InheritPipe(cmd, &cfg); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(cmd.ExtraFiles) != 1 || len(cmd.Env) != 1 || cmd.Env[0] != InheritFDEnv+"=3" {
+		t.Fatalf("want pipe on fd 3, got files %v and env %v", cmd.ExtraFiles, cmd.Env)
+	}
+	for _, env := range cmd.Env {
+		if strings.Contains(env, "hunter2") {
+			t.Fatalf("want no secret in env, got %v", cmd.Env)
+		}
+	}
+
+	// in process, the read end is duplicated as the child would get it so
+	// that it is closed once by each of its owners.
+	fd, err := syscall.Dup(int(cmd.ExtraFiles[0].Fd()))
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	cmd.ExtraFiles[0].Close()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "host: changed\n")
+	setenv(t, InheritFDEnv, strconv.Itoa(fd))
+
+	var child Config
+	if err := Load(&child, Dirs(dir), Inherited()); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if child != cfg {
+		t.Errorf("want %+v, got %+v", cfg, child)
+	}
+	if _, ok := os.LookupEnv(InheritFDEnv); ok {
+		t.Errorf("want %s to be unset", InheritFDEnv)
+	}
+}
@@ -0,0 +1,258 @@
+package cfg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// decodeJSON5 decodes a JSON5 document into vals. JSON5 extends JSON with
+// comments, trailing commas, single quoted strings, unquoted keys and
+// additional number formats, which makes it convenient for configs that
+// are edited by hand.
+func decodeJSON5(r io.Reader, vals map[string]interface{}) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	js, err := json5ToJSON(data)
+	if err != nil {
+		return err
+	}
+	return json.NewDecoder(bytes.NewReader(js)).Decode(&vals)
+}
+
+// json5ToJSON translates a JSON5 document into plain JSON. The structure of
+// the document is left to be validated by the JSON decoder.
+func json5ToJSON(data []byte) ([]byte, error) {
+	var out bytes.Buffer
+	out.Grow(len(data))
+
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == '/':
+			end, err := skipComment(data, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+		case c == '"' || c == '\'':
+			s, end, err := readJSON5String(data, i)
+			if err != nil {
+				return nil, err
+			}
+			writeJSONString(&out, s)
+			i = end
+		case c == ',':
+			// drop trailing commas.
+			next, err := skipSpace(data, i+1)
+			if err != nil {
+				return nil, err
+			}
+			if next >= len(data) || (data[next] != '}' && data[next] != ']') {
+				out.WriteByte(c)
+			}
+			i++
+		case isIdentStart(c):
+			end := i + 1
+			for end < len(data) && isIdentPart(data[end]) {
+				end++
+			}
+			ident := string(data[i:end])
+			switch ident {
+			case "true", "false", "null":
+				out.WriteString(ident)
+			case "Infinity", "NaN":
+				return nil, fmt.Errorf("json5: %s is not supported", ident)
+			default:
+				next, err := skipSpace(data, end)
+				if err != nil {
+					return nil, err
+				}
+				if next >= len(data) || data[next] != ':' {
+					return nil, fmt.Errorf("json5: unexpected identifier %q at offset %d", ident, i)
+				}
+				writeJSONString(&out, ident)
+			}
+			i = end
+		case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(data) && strings.IndexByte("0123456789abcdefABCDEFxX.+-", data[end]) >= 0 {
+				end++
+			}
+			num, err := normalizeJSON5Number(string(data[i:end]))
+			if err != nil {
+				return nil, err
+			}
+			out.WriteString(num)
+			i = end
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+
+	return out.Bytes(), nil
+}
+
+// skipComment returns the offset following the comment starting at i.
+func skipComment(data []byte, i int) (int, error) {
+	if i+1 >= len(data) {
+		return 0, fmt.Errorf("json5: unexpected '/' at offset %d", i)
+	}
+	switch data[i+1] {
+	case '/':
+		end := bytes.IndexByte(data[i:], '\n')
+		if end < 0 {
+			return len(data), nil
+		}
+		return i + end, nil
+	case '*':
+		end := bytes.Index(data[i+2:], []byte("*/"))
+		if end < 0 {
+			return 0, fmt.Errorf("json5: unterminated comment at offset %d", i)
+		}
+		return i + 2 + end + 2, nil
+	default:
+		return 0, fmt.Errorf("json5: unexpected '/' at offset %d", i)
+	}
+}
+
+// skipSpace returns the offset of the first byte at or after i that is
+// neither whitespace nor part of a comment.
+func skipSpace(data []byte, i int) (int, error) {
+	for i < len(data) {
+		switch data[i] {
+		case ' ', '\t', '\n', '\r':
+			i++
+		case '/':
+			end, err := skipComment(data, i)
+			if err != nil {
+				return 0, err
+			}
+			i = end
+		default:
+			return i, nil
+		}
+	}
+	return i, nil
+}
+
+// readJSON5String reads the single or double quoted string starting at i,
+// returning its unescaped value and the offset following it.
+func readJSON5String(data []byte, i int) (string, int, error) {
+	quote := data[i]
+	var sb strings.Builder
+
+	for j := i + 1; j < len(data); {
+		c := data[j]
+		switch {
+		case c == quote:
+			return sb.String(), j + 1, nil
+		case c == '\n' || c == '\r':
+			return "", 0, fmt.Errorf("json5: unterminated string at offset %d", i)
+		case c != '\\':
+			r, size := utf8.DecodeRune(data[j:])
+			sb.WriteRune(r)
+			j += size
+			continue
+		}
+
+		if j+1 >= len(data) {
+			break
+		}
+		esc := data[j+1]
+		j += 2
+		switch esc {
+		case 'b':
+			sb.WriteByte('\b')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case 'v':
+			sb.WriteByte('\v')
+		case '0':
+			sb.WriteByte(0)
+		case '\n':
+			// line continuation.
+		case '\r':
+			if j < len(data) && data[j] == '\n' {
+				j++
+			}
+		case 'x', 'u':
+			n := 2
+			if esc == 'u' {
+				n = 4
+			}
+			if j+n > len(data) {
+				return "", 0, fmt.Errorf("json5: invalid escape at offset %d", j-2)
+			}
+			r, err := strconv.ParseUint(string(data[j:j+n]), 16, 32)
+			if err != nil {
+				return "", 0, fmt.Errorf("json5: invalid escape at offset %d", j-2)
+			}
+			sb.WriteRune(rune(r))
+			j += n
+		default:
+			sb.WriteByte(esc)
+		}
+	}
+
+	return "", 0, fmt.Errorf("json5: unterminated string at offset %d", i)
+}
+
+// normalizeJSON5Number converts a JSON5 number into a JSON number, e.g.
+// `+.5` into `0.5` and `0x1F` into `31`.
+func normalizeJSON5Number(num string) (string, error) {
+	sign, body := "", num
+	if body[0] == '+' || body[0] == '-' {
+		sign, body = body[:1], body[1:]
+	}
+	if sign == "+" {
+		sign = ""
+	}
+
+	if strings.HasPrefix(body, "0x") || strings.HasPrefix(body, "0X") {
+		n, err := strconv.ParseUint(body[2:], 16, 64)
+		if err != nil {
+			return "", fmt.Errorf("json5: invalid number %q", num)
+		}
+		return sign + strconv.FormatUint(n, 10), nil
+	}
+
+	if strings.HasPrefix(body, ".") {
+		body = "0" + body
+	}
+	body = strings.Replace(body, ".e", ".0e", 1)
+	body = strings.Replace(body, ".E", ".0E", 1)
+	body = strings.TrimSuffix(body, ".")
+
+	if _, err := strconv.ParseFloat(body, 64); err != nil || strings.ContainsAny(body, "xXabcdfABCDF") {
+		return "", fmt.Errorf("json5: invalid number %q", num)
+	}
+	return sign + body, nil
+}
+
+// writeJSONString writes s to out as a JSON string.
+func writeJSONString(out *bytes.Buffer, s string) {
+	b, _ := json.Marshal(s)
+	out.Write(b)
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
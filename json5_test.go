@@ -0,0 +1,57 @@
+package cfg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_decodeJSON5(t *testing.T) {
+	in := `// comment
+{
+  unquoted: 'single "quoted"',
+  "quoted": "tab\tand \x41B \
+continued",
+  $id_1: 0x1F,
+  numbers: [+1, -.5, 5., 1.e2, 2E-1,],
+  nested: { ok: true, none: null, /* inline */ },
+  url: "http://example.com//path",
+}
+`
+	vals := make(map[string]interface{})
+	if err := decodeJSON5(strings.NewReader(in), vals); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"unquoted": `single "quoted"`,
+		"quoted":   "tab\tand AB continued",
+		"$id_1":    float64(31),
+		"numbers":  []interface{}{float64(1), -0.5, float64(5), float64(100), 0.2},
+		"nested":   map[string]interface{}{"ok": true, "none": nil},
+		"url":      "http://example.com//path",
+	}
+	if !reflect.DeepEqual(want, vals) {
+		t.Errorf("\nwant %+v\ngot %+v", want, vals)
+	}
+
+	for _, in := range []string{
+		`{a: 'unterminated}`,
+		`{a: "line
+break"}`,
+		`{a: 1} /* unterminated`,
+		`{a: b}`,
+		`{a: Infinity}`,
+		`{a: 0xZZ}`,
+		`{a: 1.2.3}`,
+		`{a: "\u12"}`,
+		`{a: 1} /`,
+		`{a: 1`,
+	} {
+		t.Run(in, func(t *testing.T) {
+			if err := decodeJSON5(strings.NewReader(in), make(map[string]interface{})); err == nil {
+				t.Fatal("expected err")
+			}
+		})
+	}
+}
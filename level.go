@@ -0,0 +1,83 @@
+package cfg
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SyncLevel sets level to the log level at path of cfg and keeps it in sync
+// each time cfg is reloaded with Reload, so that the verbosity of a running
+// process can be changed by editing its config file. The field at path must
+// be either a string or a Logging section. level is typically a
+// zap.AtomicLevel:
+//
+//	lvl := zap.NewAtomicLevel()
+//	if err := cfg.SyncLevel(&conf, "log.level", &lvl); err != nil {
+//	  return err
+//	}
+//	logger := zap.New(core, zap.IncreaseLevel(lvl))
+//
+// Use LevelVar for log/slog. cfg must be the same pointer that is passed to
+// Reload, and Forget stops the sync. Reloaded levels that are not known are
+// ignored, leaving level unchanged. options configure the tag used to name
+// fields.
+func SyncLevel(cfg interface{}, path string, level encoding.TextUnmarshaler, options ...Option) error {
+	return syncLevel(cfg, path, func(l string) error {
+		if strings.EqualFold(l, "warning") {
+			l = "warn"
+		}
+		return level.UnmarshalText([]byte(strings.ToLower(l)))
+	}, options...)
+}
+
+// syncLevel calls set with the level at path of cfg, now and after each
+// reload of cfg.
+func syncLevel(cfg interface{}, path string, set func(level string) error, options ...Option) error {
+	conf := defaultCfg()
+	for _, opt := range options {
+		opt(conf)
+	}
+	if !isStructPtr(cfg) {
+		return fmt.Errorf("cfg must be a pointer to a struct")
+	}
+
+	level, err := levelAt(cfg, path, conf.tag)
+	if err != nil {
+		return err
+	}
+	if err := set(level); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	onReload(cfg, func() {
+		if level, err := levelAt(cfg, path, conf.tag); err == nil {
+			_ = set(level)
+		}
+	})
+	return nil
+}
+
+// levelAt returns the log level held by the field of cfg at path.
+func levelAt(cfg interface{}, path, tag string) (string, error) {
+	field := lookupField(cfg, path, tag)
+	if field == nil {
+		return "", fmt.Errorf("no field at %s", path)
+	}
+
+	var level string
+	switch v := field.v; {
+	case v.Kind() == reflect.String:
+		level = v.String()
+	case v.Type() == reflect.TypeOf(Logging{}):
+		level = v.Interface().(Logging).Level
+	default:
+		return "", fmt.Errorf("%s: must be a string or a Logging section, got %v", path, field.t)
+	}
+
+	if !isLogLevel(level) {
+		return "", fmt.Errorf("%s: unknown level %q", path, level)
+	}
+	return level, nil
+}
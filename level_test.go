@@ -0,0 +1,67 @@
+package cfg
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// textLevel records the levels it is unmarshaled from.
+type textLevel struct{ levels []string }
+
+func (l *textLevel) UnmarshalText(text []byte) error {
+	l.levels = append(l.levels, string(text))
+	return nil
+}
+
+func Test_SyncLevel(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	writeFile(t, file, "log:\n  level: INFO\n")
+
+	var cfg struct {
+		Log Logging `cfg:"log"`
+	}
+	if err := Load(&cfg, Dirs(dir)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	t.Cleanup(func() { Forget(&cfg) })
+
+	var lvl textLevel
+	if err := SyncLevel(&cfg, "log", &lvl); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	reload := func(contents string) {
+		t.Helper()
+		writeFile(t, file, contents)
+		if _, err := Reload(&cfg, Dirs(dir)); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+	reload("log:\n  level: warning\n")
+	reload("log:\n  level: debug\n  format: json\n")
+
+	want := []string{"info", "warn", "debug"}
+	if len(lvl.levels) != len(want) {
+		t.Fatalf("want %v, got %v", want, lvl.levels)
+	}
+	for i := range want {
+		if lvl.levels[i] != want[i] {
+			t.Fatalf("want %v, got %v", want, lvl.levels)
+		}
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		var cfg struct {
+			Level   string `cfg:"level"`
+			Verbose bool   `cfg:"verbose"`
+		}
+		cfg.Level = "loud"
+
+		for _, path := range []string{"level", "verbose", "missing"} {
+			if err := SyncLevel(&cfg, path, &textLevel{}); err == nil {
+				t.Errorf("%s: expected err", path)
+			}
+		}
+	})
+}
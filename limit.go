@@ -0,0 +1,106 @@
+package cfg
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// limit reads r upfront if a max file size is configured, failing with
+// ErrLimitExceeded if it is larger. r is read upfront as decoders do not
+// wrap the errors of their reader.
+func (f *cfg) limit(r io.Reader) (io.Reader, error) {
+	if f.maxFileSize <= 0 {
+		return r, nil
+	}
+	data, err := io.ReadAll(limitReader(r, f.maxFileSize))
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+// readLimited reads r to the end, failing with ErrLimitExceeded once more
+// than max bytes are read if max is positive.
+func readLimited(r io.Reader, max int64) ([]byte, error) {
+	if max > 0 {
+		r = limitReader(r, max)
+	}
+	return io.ReadAll(r)
+}
+
+// limitReader returns a reader that reads from r and fails with
+// ErrLimitExceeded once more than max bytes are read.
+func limitReader(r io.Reader, max int64) io.Reader {
+	return &maxReader{r: r, n: max}
+}
+
+type maxReader struct {
+	r io.Reader
+	n int64 // bytes left before the limit is exceeded.
+}
+
+func (m *maxReader) Read(p []byte) (int, error) {
+	if m.n < 0 {
+		return 0, m.err()
+	}
+	if int64(len(p)) > m.n+1 {
+		p = p[:m.n+1]
+	}
+	n, err := m.r.Read(p)
+	m.n -= int64(n)
+	if m.n < 0 {
+		return 0, m.err()
+	}
+	return n, err
+}
+
+func (m *maxReader) err() error {
+	return fmt.Errorf("%w: file is larger than the max size", ErrLimitExceeded)
+}
+
+// checkLimits returns an error wrapping ErrLimitExceeded if vals holds
+// more keys or is nested deeper than the configured limits.
+func (f *cfg) checkLimits(vals interface{}) error {
+	if f.maxKeys <= 0 && f.maxDepth <= 0 {
+		return nil
+	}
+
+	keys, depth := countKeys(vals, 1)
+	if f.maxKeys > 0 && keys > f.maxKeys {
+		return fmt.Errorf("%w: %d keys exceed the max of %d", ErrLimitExceeded, keys, f.maxKeys)
+	}
+	if f.maxDepth > 0 && depth > f.maxDepth {
+		return fmt.Errorf("%w: nesting depth %d exceeds the max of %d", ErrLimitExceeded, depth, f.maxDepth)
+	}
+	return nil
+}
+
+// countKeys returns the number of map keys in v, including those of
+// nested maps, and the depth of its deepest map or slice. depth is the
+// depth of v itself.
+func countKeys(v interface{}, depth int) (keys, maxDepth int) {
+	maxDepth = depth
+	var children []interface{}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys = len(v)
+		for _, child := range v {
+			children = append(children, child)
+		}
+	case []interface{}:
+		children = v
+	default:
+		return 0, depth - 1
+	}
+
+	for _, child := range children {
+		k, d := countKeys(child, depth+1)
+		keys += k
+		if d > maxDepth {
+			maxDepth = d
+		}
+	}
+	return keys, maxDepth
+}
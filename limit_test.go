@@ -0,0 +1,89 @@
+package cfg
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_cfg_Load_Limits(t *testing.T) {
+	type Config struct {
+		A struct {
+			B struct {
+				C int `cfg:"c"`
+			} `cfg:"b"`
+		} `cfg:"a"`
+		D []int `cfg:"d"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "a:\n  b:\n    c: 1\nd: [1, 2]\n")
+
+	for _, tc := range []struct {
+		name string
+		opt  Option
+		ok   bool
+	}{
+		{"size within limit", MaxFileSize(1024), true},
+		{"size exceeded", MaxFileSize(10), false},
+		{"keys within limit", MaxKeys(4), true},
+		{"keys exceeded", MaxKeys(3), false},
+		{"depth within limit", MaxDepth(3), true},
+		{"depth exceeded", MaxDepth(2), false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var cfg Config
+			err := Load(&cfg, Dirs(dir), tc.opt)
+			if tc.ok && err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if !tc.ok && !errors.Is(err, ErrLimitExceeded) {
+				t.Fatalf("want ErrLimitExceeded, got %v", err)
+			}
+		})
+	}
+}
+
+func Test_cfg_Load_LoadTimeout(t *testing.T) {
+	type Config struct {
+		Host string `cfg:"host"`
+	}
+
+	slow := SourceFunc(func(ctx context.Context) (map[string]interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	var cfg Config
+	err := Load(&cfg, IgnoreFile(), WithSources(slow), LoadTimeout(10*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func Test_limitReader(t *testing.T) {
+	b, err := io.ReadAll(limitReader(strings.NewReader("12345"), 5))
+	if err != nil || string(b) != "12345" {
+		t.Errorf("want 12345, got %q (err %v)", b, err)
+	}
+
+	_, err = io.ReadAll(limitReader(strings.NewReader("123456"), 5))
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("want ErrLimitExceeded, got %v", err)
+	}
+}
+
+func Test_countKeys(t *testing.T) {
+	vals := map[string]interface{}{
+		"a": map[string]interface{}{"b": map[string]interface{}{"c": 1}},
+		"d": []interface{}{1, map[string]interface{}{"e": 2}},
+	}
+	keys, depth := countKeys(vals, 1)
+	if keys != 5 || depth != 3 {
+		t.Errorf("want 5 keys at depth 3, got %d keys at depth %d", keys, depth)
+	}
+}
@@ -0,0 +1,123 @@
+package cfg
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// lintYears is how far in the past or future of now a time must be for
+// lint to warn about it, e.g. a year typed with one digit too many.
+const lintYears = 100
+
+// lintField warns about the value of field if it looks like a mistake:
+// a duration set from a number without unit, which decodes as nanoseconds,
+// a port out of range, a path, i.e. a value with a slash or of a key
+// ending in `path`, `file` or `dir`, with trailing spaces or a time in the far
+// past or future.
+func (f *cfg) lintField(field *field) {
+	if isZero(field.v) {
+		return
+	}
+	path := field.path()
+	v := field.v
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Type() == reflect.TypeOf(time.Duration(0)):
+		if raw, ok := f.rawValue(path); ok && isNumber(raw) {
+			f.warnf("%s: duration %v has no unit and is read as nanoseconds", path, raw)
+		}
+
+	case v.Type() == reflect.TypeOf(time.Time{}):
+		t := v.Interface().(time.Time) //nolint:forcetypeassert
+		now := f.now()
+		if t.Before(now.AddDate(-lintYears, 0, 0)) || t.After(now.AddDate(lintYears, 0, 0)) {
+			f.warnf("%s: time %s is in the far past or future", path, t.Format(time.RFC3339))
+		}
+
+	case isPortKey(field.name()) && isIntKind(v.Kind()):
+		if port := intValue(v); port < 0 || port > 65535 {
+			f.warnf("%s: port %d is out of range", path, port)
+		}
+
+	case v.Kind() == reflect.String:
+		s := v.String()
+		isPath := isPathKey(field.name()) || strings.ContainsAny(s, `/\`)
+		if isPath && strings.TrimRight(s, " \t") != s {
+			f.warnf("%s: path %q has trailing spaces", path, s)
+		}
+	}
+}
+
+// rawValue returns the raw value of the files and sources at path. Paths
+// into slices have no raw value.
+func (f *cfg) rawValue(path string) (interface{}, bool) {
+	if strings.Contains(path, "[") {
+		return nil, false
+	}
+	var val interface{} = f.policyVals
+	for _, key := range strings.Split(path, ".") {
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if val, ok = lookupKey(m, key); !ok {
+			return nil, false
+		}
+	}
+	return val, true
+}
+
+// isNumber reports whether val is a number rather than a string.
+func isNumber(val interface{}) bool {
+	if val == nil {
+		return false
+	}
+	k := reflect.TypeOf(val).Kind()
+	return isIntKind(k) || k == reflect.Float32 || k == reflect.Float64
+}
+
+func isIntKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	default:
+		return false
+	}
+}
+
+// intValue returns the value of the int or uint v, capping uints that
+// overflow int64.
+func intValue(v reflect.Value) int64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	default:
+		u := v.Uint()
+		if u > uint64(1<<63-1) {
+			return 1<<63 - 1
+		}
+		return int64(u)
+	}
+}
+
+// isPortKey reports whether key names a port, e.g. `port` or `http_port`.
+func isPortKey(key string) bool {
+	return strings.HasSuffix(strings.ToLower(key), "port")
+}
+
+// isPathKey reports whether key names a path, e.g. `path`, `log_file` or
+// `dataDir`.
+func isPathKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, suffix := range []string{"path", "file", "dir"} {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package cfg
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_cfg_Load_Lint(t *testing.T) {
+	type Config struct {
+		Timeout   time.Duration `cfg:"timeout"`
+		Interval  time.Duration `cfg:"interval"`
+		Port      int           `cfg:"port"`
+		AdminPort int           `cfg:"admin_port"`
+		LogFile   string        `cfg:"log_file"`
+		Root      string        `cfg:"root"`
+		Name      string        `cfg:"name"`
+		Since     time.Time     `cfg:"since"`
+		Until     time.Time     `cfg:"until"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), `timeout: 30
+interval: 5s
+port: 70000
+admin_port: 9090
+log_file: "/var/log/app.log "
+root: "/srv "
+name: "app "
+since: 2020-01-01T00:00:00Z
+until: 9999-01-01T00:00:00Z
+`)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("warns", func(t *testing.T) {
+		var cfg Config
+		res, err := LoadResult(&cfg, Dirs(dir), Lint(), TimeLayout(time.RFC3339), Clock(func() time.Time { return now }))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		want := []string{
+			"timeout: duration 30 has no unit and is read as nanoseconds",
+			"port: port 70000 is out of range",
+			`log_file: path "/var/log/app.log " has trailing spaces`,
+			`root: path "/srv " has trailing spaces`,
+			"until: time 9999-01-01T00:00:00Z is in the far past or future",
+		}
+		if !reflect.DeepEqual(want, res.Warnings) {
+			t.Errorf("want warnings %v, got %v", want, res.Warnings)
+		}
+	})
+
+	t.Run("opt-in", func(t *testing.T) {
+		var cfg Config
+		res, err := LoadResult(&cfg, Dirs(dir), TimeLayout(time.RFC3339))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if len(res.Warnings) > 0 {
+			t.Errorf("want no warnings, got %v", res.Warnings)
+		}
+	})
+}
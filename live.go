@@ -0,0 +1,73 @@
+package cfg
+
+import "sync"
+
+// Live holds a loaded config that is changed while the service reads it,
+// by Reload, ApplyPatch or AdminHandler. Changes made through a Live are
+// committed while holding a lock that its readers hold too, so that
+// readers always see a complete config:
+//
+//	live := cfg.NewLive(&conf)
+//	http.Handle("/admin/config", cfg.AdminHandler(live, nil))
+//
+//	// in request handlers
+//	conf := live.Get()
+//
+// Given a plain pointer to a config struct instead, Reload, ApplyPatch and
+// AdminHandler set the struct while other goroutines may read it, which is
+// a data race unless every reader synchronizes with the writer.
+type Live[T any] struct {
+	writeMu sync.Mutex   // serializes the writers of cfg.
+	mu      sync.RWMutex // held by readers, and by writers as they commit.
+	cfg     *T
+}
+
+// NewLive returns a Live that holds cfg, a pointer to a loaded config
+// struct. cfg must not be read nor written directly once it is held.
+func NewLive[T any](cfg *T) *Live[T] {
+	return &Live[T]{cfg: cfg}
+}
+
+// Get returns a copy of the config. Changes replace the maps, slices and
+// pointers of the config rather than modify them, so the copy is not
+// affected by later changes, but they are shared with the config and must
+// not be modified.
+func (l *Live[T]) Get() T {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return *l.cfg
+}
+
+// live returns the config held by l and its locks.
+func (l *Live[T]) live() (cfg interface{}, writeMu *sync.Mutex, mu *sync.RWMutex) {
+	return l.cfg, &l.writeMu, &l.mu
+}
+
+// liveConfig is implemented by *Live.
+type liveConfig interface {
+	live() (cfg interface{}, writeMu *sync.Mutex, mu *sync.RWMutex)
+}
+
+// lockLive returns cfg and options as they are and a no-op unlock if cfg is
+// not a *Live. Otherwise it locks out the other writers of the Live until
+// unlock is called, and returns its config along with options that commit
+// changes under its lock.
+func lockLive(cfg interface{}, options []Option) (interface{}, []Option, func()) {
+	l, ok := cfg.(liveConfig)
+	if !ok {
+		return cfg, options, func() {}
+	}
+	cfg, writeMu, mu := l.live()
+	writeMu.Lock()
+	return cfg, withLiveLocks(options, writeMu, mu), writeMu.Unlock
+}
+
+// withLiveLocks returns options followed by one that sets the locks of a
+// Live: writeMu for the changes applied later by Reload, and mu to commit
+// changes.
+func withLiveLocks(options []Option, writeMu, mu sync.Locker) []Option {
+	return append(options[:len(options):len(options)], func(f *cfg) {
+		f.writeLock = writeMu
+		f.commitLock = mu
+	})
+}
@@ -0,0 +1,72 @@
+package cfg
+
+import "context"
+
+// Loader is a reusable, pre-configured set of options, e.g. shipped by a
+// platform team as a library that sets the dirs, sources and policies
+// shared by the services of an organization. Loaders are immutable and
+// safe for concurrent use.
+//
+//	var Platform = cfg.NewLoader(
+//	  cfg.Dirs("/etc/platform"),
+//	  cfg.WithSources(platformSource),
+//	  cfg.WithPolicy("tls", requireTLS),
+//	)
+//
+//	app := cfg.Chain(Platform, cfg.NewLoader(cfg.File("app.yaml"), cfg.UseEnv("app")))
+//	err := app.Load(&conf)
+type Loader struct {
+	options []Option
+}
+
+// NewLoader returns a Loader with options.
+func NewLoader(options ...Option) *Loader {
+	return &Loader{options: append([]Option(nil), options...)}
+}
+
+// Chain returns a Loader composed of loaders, whose options are applied in
+// order so that later loaders take precedence: options that set a single
+// setting, such as Dirs or Tag, replace those of earlier loaders, while
+// sources, overrides and policies are merged, those of later loaders
+// overriding the values of earlier ones.
+func Chain(loaders ...*Loader) *Loader {
+	var options []Option
+	for _, l := range loaders {
+		options = append(options, l.options...)
+	}
+	return &Loader{options: options}
+}
+
+// With returns a Loader with the options of l followed by options, which
+// take precedence like those of a chained loader.
+func (l *Loader) With(options ...Option) *Loader {
+	return Chain(l, NewLoader(options...))
+}
+
+// Option returns an option that applies the options of l, e.g. to pass a
+// loader to Load along with other options.
+func (l *Loader) Option() Option {
+	return func(f *cfg) {
+		for _, opt := range l.options {
+			opt(f)
+		}
+	}
+}
+
+// Load loads the config into cfg like the Load function with the options
+// of l followed by options.
+func (l *Loader) Load(cfg interface{}, options ...Option) error {
+	return Load(cfg, l.With(options...).options...)
+}
+
+// LoadContext loads the config into cfg like the LoadContext function with
+// the options of l followed by options.
+func (l *Loader) LoadContext(ctx context.Context, cfg interface{}, options ...Option) error {
+	return LoadContext(ctx, cfg, l.With(options...).options...)
+}
+
+// LoadResult loads the config into cfg like the LoadResult function with
+// the options of l followed by options.
+func (l *Loader) LoadResult(cfg interface{}, options ...Option) (*Result, error) {
+	return LoadResult(cfg, l.With(options...).options...)
+}
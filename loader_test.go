@@ -0,0 +1,79 @@
+package cfg
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_Loader(t *testing.T) {
+	type Config struct {
+		Host    string `cfg:"host"`
+		Port    int    `cfg:"port"`
+		Region  string `cfg:"region"`
+		Level   string `cfg:"level" default:"info"`
+		Tracing bool   `cfg:"tracing"`
+	}
+
+	platformDir, appDir := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(platformDir, "config.yaml"), "host: platform\nport: 80\n")
+	writeFile(t, filepath.Join(appDir, "config.yaml"), "host: app\n")
+
+	platform := NewLoader(
+		Dirs(platformDir),
+		WithSources(MapSource{"region": "eu-1", "tracing": true}),
+		Override(map[string]interface{}{"level": "warn"}),
+	)
+	app := NewLoader(
+		Dirs(appDir),
+		WithSources(MapSource{"port": 8080}),
+	)
+
+	t.Run("chain", func(t *testing.T) {
+		var cfg Config
+		res, err := Chain(platform, app).LoadResult(&cfg)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		want := Config{Host: "app", Port: 8080, Region: "eu-1", Level: "warn", Tracing: true}
+		if cfg != want {
+			t.Errorf("want %+v, got %+v", want, cfg)
+		}
+		if want := []string{filepath.Join(appDir, "config.yaml")}; !reflect.DeepEqual(want, res.Files) {
+			t.Errorf("want files %v, got %v", want, res.Files)
+		}
+	})
+
+	t.Run("with", func(t *testing.T) {
+		var cfg Config
+		if err := platform.With(Override(map[string]interface{}{"port": 9090})).Load(&cfg); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		want := Config{Host: "platform", Port: 9090, Region: "eu-1", Level: "warn", Tracing: true}
+		if cfg != want {
+			t.Errorf("want %+v, got %+v", want, cfg)
+		}
+	})
+
+	t.Run("option", func(t *testing.T) {
+		var cfg Config
+		if err := Load(&cfg, platform.Option(), Override(map[string]interface{}{"level": "debug"})); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Host != "platform" || cfg.Level != "debug" {
+			t.Errorf("unexpected cfg %+v", cfg)
+		}
+	})
+
+	t.Run("context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var cfg Config
+		if err := platform.LoadContext(ctx, &cfg); !errors.Is(err, context.Canceled) {
+			t.Fatalf("want context.Canceled, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package cfg
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Logging is a reusable config section for logger settings.
+//
+//	type Config struct {
+//	  Log cfg.Logging `cfg:"log"`
+//	}
+//
+// Levels are validated against the levels known to both log/slog and zap.
+// The section is validated when the config is loaded. Use NewLogger to
+// create a *slog.Logger from it (Go 1.21+).
+type Logging struct {
+	// Level is the minimum level of logged records, one of debug, info,
+	// warn, error, dpanic, panic or fatal.
+	Level string `cfg:"level" default:"info"`
+	// Format is the encoding of records, one of text or json.
+	Format string `cfg:"format" default:"text"`
+	// Output is where records are written to, either stdout, stderr or
+	// the path of a file that records are appended to.
+	Output string `cfg:"output" default:"stderr"`
+	// Sampling limits the rate of repeated records. Sampling is disabled
+	// if it is nil.
+	Sampling *LogSampling `cfg:"sampling"`
+}
+
+// LogSampling configures sampling of log records the same way zap does.
+// Within each second the first Initial records with a given message are
+// logged, after which only every Thereafter-th record with that message
+// is logged.
+type LogSampling struct {
+	Initial    int `cfg:"initial" default:"100"`
+	Thereafter int `cfg:"thereafter" default:"100"`
+}
+
+// logLevels are the accepted log levels.
+var logLevels = []string{"debug", "info", "warn", "warning", "error", "dpanic", "panic", "fatal"}
+
+// Validate checks that the settings are valid.
+func (l *Logging) Validate() error {
+	if !isLogLevel(l.Level) {
+		return fmt.Errorf("unknown level %q", l.Level)
+	}
+	switch strings.ToLower(l.Format) {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("unknown format %q", l.Format)
+	}
+	if l.Sampling != nil && (l.Sampling.Initial < 0 || l.Sampling.Thereafter < 0) {
+		return fmt.Errorf("sampling values must not be negative")
+	}
+	return nil
+}
+
+// Writer opens Output. Files are created if they do not exist and are never
+// closed, as they are expected to be used for the lifetime of the process.
+func (l *Logging) Writer() (io.Writer, error) {
+	switch strings.ToLower(l.Output) {
+	case "", "stderr":
+		return os.Stderr, nil
+	case "stdout":
+		return os.Stdout, nil
+	default:
+		return os.OpenFile(l.Output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	}
+}
+
+// isLogLevel reports whether level is one of logLevels, ignoring case.
+func isLogLevel(level string) bool {
+	for _, l := range logLevels {
+		if strings.EqualFold(l, level) {
+			return true
+		}
+	}
+	return false
+}
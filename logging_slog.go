@@ -0,0 +1,134 @@
+//go:build go1.21
+
+package cfg
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SlogLevel returns the slog.Level of Level. The zap levels dpanic, panic
+// and fatal, which slog has no equivalent of, map to slog.LevelError.
+func (l *Logging) SlogLevel() slog.Level {
+	switch strings.ToLower(l.Level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error", "dpanic", "panic", "fatal":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// NewHandler validates the settings and creates a slog.Handler from them.
+func (l *Logging) NewHandler() (slog.Handler, error) {
+	if err := l.Validate(); err != nil {
+		return nil, err
+	}
+
+	w, err := l.Writer()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &slog.HandlerOptions{Level: l.SlogLevel()}
+
+	var h slog.Handler
+	if strings.EqualFold(l.Format, "json") {
+		h = slog.NewJSONHandler(w, opts)
+	} else {
+		h = slog.NewTextHandler(w, opts)
+	}
+
+	if l.Sampling != nil {
+		h = &samplingHandler{Handler: h, sampling: *l.Sampling, state: &samplingState{}}
+	}
+
+	return h, nil
+}
+
+// NewLogger validates the settings and creates a *slog.Logger from them.
+func (l *Logging) NewLogger() (*slog.Logger, error) {
+	h, err := l.NewHandler()
+	if err != nil {
+		return nil, err
+	}
+	return slog.New(h), nil
+}
+
+// LevelVar returns a *slog.LevelVar set to the log level at path of cfg,
+// which is kept in sync each time cfg is reloaded with Reload. The field at
+// path must be either a string or a Logging section.
+//
+//	lvl, err := cfg.LevelVar(&conf, "log")
+//	if err != nil {
+//	  return err
+//	}
+//	logger := slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: lvl}))
+//
+// See SyncLevel for details.
+func LevelVar(cfg interface{}, path string, options ...Option) (*slog.LevelVar, error) {
+	v := new(slog.LevelVar)
+	err := syncLevel(cfg, path, func(level string) error {
+		v.Set((&Logging{Level: level}).SlogLevel())
+		return nil
+	}, options...)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// samplingHandler is a slog.Handler that drops records according to a
+// LogSampling policy.
+type samplingHandler struct {
+	slog.Handler
+	sampling LogSampling
+	state    *samplingState
+}
+
+// samplingState counts the records seen per message in the current second.
+type samplingState struct {
+	mu     sync.Mutex
+	second int64
+	counts map[string]int
+}
+
+func (h *samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.state.sample(r.Time, r.Message, h.sampling) {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithAttrs(attrs), sampling: h.sampling, state: h.state}
+}
+
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithGroup(name), sampling: h.sampling, state: h.state}
+}
+
+// sample reports whether the n-th record with msg in the second of t should
+// be logged.
+func (s *samplingState) sample(t time.Time, msg string, sampling LogSampling) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sec := t.Unix(); sec != s.second || s.counts == nil {
+		s.second = sec
+		s.counts = make(map[string]int)
+	}
+	s.counts[msg]++
+	n := s.counts[msg]
+
+	if n <= sampling.Initial {
+		return true
+	}
+	return sampling.Thereafter > 0 && (n-sampling.Initial)%sampling.Thereafter == 0
+}
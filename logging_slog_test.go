@@ -0,0 +1,117 @@
+//go:build go1.21
+
+package cfg
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogging_SlogLevel(t *testing.T) {
+	for level, want := range map[string]slog.Level{
+		"debug":   slog.LevelDebug,
+		"INFO":    slog.LevelInfo,
+		"warning": slog.LevelWarn,
+		"error":   slog.LevelError,
+		"fatal":   slog.LevelError,
+	} {
+		l := Logging{Level: level}
+		if got := l.SlogLevel(); got != want {
+			t.Errorf("level %q: want %v, got %v", level, want, got)
+		}
+	}
+}
+
+func TestLogging_NewLogger(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "app.log")
+	l := Logging{Level: "warn", Format: "json", Output: output}
+
+	logger, err := l.NewLogger()
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	logger.Info("dropped")
+	logger.Warn("kept", "key", "value")
+
+	b, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(b)
+	if strings.Contains(got, "dropped") || !strings.Contains(got, `"msg":"kept","key":"value"`) {
+		t.Errorf("unexpected output: %s", got)
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		l := Logging{Level: "loud"}
+		if _, err := l.NewLogger(); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+}
+
+func Test_samplingState_sample(t *testing.T) {
+	var s samplingState
+	sampling := LogSampling{Initial: 2, Thereafter: 3}
+	now := time.Unix(100, 0)
+
+	var got []bool
+	for i := 0; i < 8; i++ {
+		got = append(got, s.sample(now, "msg", sampling))
+	}
+	want := []bool{true, true, false, false, true, false, false, true}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+	}
+
+	if !s.sample(now, "other", sampling) {
+		t.Error("want first record of another message to be logged")
+	}
+	if !s.sample(now.Add(time.Second), "msg", sampling) {
+		t.Error("want counts to reset every second")
+	}
+}
+
+func Test_LevelVar(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	writeFile(t, file, "level: info\n")
+
+	var cfg struct {
+		Level string `cfg:"level"`
+	}
+	if err := Load(&cfg, Dirs(dir)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	t.Cleanup(func() { Forget(&cfg) })
+
+	lvl, err := LevelVar(&cfg, "level")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if lvl.Level() != slog.LevelInfo {
+		t.Errorf("want %v, got %v", slog.LevelInfo, lvl.Level())
+	}
+
+	writeFile(t, file, "level: debug\n")
+	if _, err := Reload(&cfg, Dirs(dir)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if lvl.Level() != slog.LevelDebug {
+		t.Errorf("want %v, got %v", slog.LevelDebug, lvl.Level())
+	}
+
+	writeFile(t, file, "level: loud\n")
+	if _, err := Reload(&cfg, Dirs(dir)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if lvl.Level() != slog.LevelDebug {
+		t.Errorf("want unknown level to be ignored, got %v", lvl.Level())
+	}
+}
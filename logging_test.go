@@ -0,0 +1,96 @@
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_cfg_Load_Logging(t *testing.T) {
+	type Config struct {
+		Log Logging `cfg:"log"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), `
+log:
+  level: debug
+  sampling:
+    initial: 10
+`)
+
+	var cfg Config
+	if err := Load(&cfg, Dirs(dir)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := Logging{
+		Level:    "debug",
+		Format:   "text",
+		Output:   "stderr",
+		Sampling: &LogSampling{Initial: 10, Thereafter: 100},
+	}
+	if !reflect.DeepEqual(want, cfg.Log) {
+		t.Errorf("\nwant %+v\ngot %+v", want, cfg.Log)
+	}
+
+	t.Run("validation error", func(t *testing.T) {
+		writeFile(t, filepath.Join(dir, "config.yaml"), "log:\n  level: verbose\n")
+
+		var cfg Config
+		err := Load(&cfg, Dirs(dir))
+		if err == nil || !strings.Contains(err.Error(), `log: unknown level "verbose"`) {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+}
+
+func TestLogging_Validate(t *testing.T) {
+	for _, tc := range []struct {
+		Name    string
+		Logging Logging
+		Err     string
+	}{
+		{Name: "slog level", Logging: Logging{Level: "WARN", Format: "json"}},
+		{Name: "zap level", Logging: Logging{Level: "dpanic"}},
+		{Name: "unknown level", Logging: Logging{Level: "trace"}, Err: "unknown level"},
+		{Name: "unknown format", Logging: Logging{Level: "info", Format: "xml"}, Err: "unknown format"},
+		{Name: "negative sampling", Logging: Logging{Level: "info", Sampling: &LogSampling{Initial: -1}}, Err: "negative"},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := tc.Logging.Validate()
+			if tc.Err == "" && err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if tc.Err != "" && (err == nil || !strings.Contains(err.Error(), tc.Err)) {
+				t.Fatalf("want err containing %q, got %v", tc.Err, err)
+			}
+		})
+	}
+}
+
+func TestLogging_Writer(t *testing.T) {
+	for output, want := range map[string]*os.File{"": os.Stderr, "stderr": os.Stderr, "STDOUT": os.Stdout} {
+		l := Logging{Output: output}
+		w, err := l.Writer()
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if w != want {
+			t.Errorf("output %q: want %v, got %v", output, want.Name(), w)
+		}
+	}
+
+	l := Logging{Output: filepath.Join(t.TempDir(), "app.log")}
+	w, err := l.Writer()
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	defer w.(*os.File).Close()
+
+	if _, err := os.Stat(l.Output); err != nil {
+		t.Errorf("want log file to be created: %v", err)
+	}
+}
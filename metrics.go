@@ -0,0 +1,119 @@
+package cfg
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MetricName is the name of the gauge that WriteMetrics exports the values
+// of config fields as.
+const MetricName = "config_value"
+
+// WriteMetrics writes the current values of the fields of cfg tagged with
+// `metric:"true"` to w as a gauge in the Prometheus text format, labeled
+// with the path of each field, so that dashboards can correlate changes in
+// behavior with changes of the config.
+//
+//	type Config struct {
+//	  Workers int           `cfg:"workers" metric:"true"`
+//	  Timeout time.Duration `cfg:"timeout" metric:"true"`
+//	}
+//
+//	# TYPE config_value gauge
+//	config_value{path="workers"} 8
+//	config_value{path="timeout"} 30
+//
+// The tag applies to all the nested fields of a struct field. Numbers are
+// exported as is, durations in seconds and bools as 0 or 1. Fields of other
+// types and nil pointers are skipped. options configure the tag used to
+// name fields.
+func WriteMetrics(w io.Writer, cfg interface{}, options ...Option) error {
+	conf := defaultCfg()
+	for _, opt := range options {
+		opt(conf)
+	}
+	if !isStructPtr(cfg) {
+		return fmt.Errorf("cfg must be a pointer to a struct")
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# HELP %s Current value of a config field.\n", MetricName)
+	fmt.Fprintf(bw, "# TYPE %s gauge\n", MetricName)
+
+	for _, field := range flattenCfg(cfg, conf.tag) {
+		if !isMetricField(field) {
+			continue
+		}
+		val, ok := metricValue(field.v)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(bw, "%s{path=\"%s\"} %s\n", MetricName, labelEscaper.Replace(field.path()), val)
+	}
+	return bw.Flush()
+}
+
+// MetricsHandler returns an http.Handler that serves the metrics written
+// by WriteMetrics. Values are read on every request, so that the metrics
+// reflect changes applied by Reload.
+func MetricsHandler(cfg interface{}, options ...Option) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var sb strings.Builder
+		if err := WriteMetrics(&sb, cfg, options...); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = io.WriteString(w, sb.String())
+	})
+}
+
+// labelEscaper escapes label values of the Prometheus text format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// isMetricField reports whether field or any of its ancestors is tagged
+// with `metric:"true"`.
+func isMetricField(field *field) bool {
+	for f := field; f != nil; f = f.parent {
+		if f.metric {
+			return true
+		}
+	}
+	return false
+}
+
+// metricValue formats the value of v as a sample value, reporting false if
+// v is not a number, a duration or a bool.
+func metricValue(v reflect.Value) (string, bool) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return "1", true
+		}
+		return "0", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == reflect.TypeOf(time.Duration(0)) {
+			return strconv.FormatFloat(time.Duration(v.Int()).Seconds(), 'g', -1, 64), true
+		}
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,68 @@
+package cfg
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_WriteMetrics(t *testing.T) {
+	ratio := 0.25
+	type Config struct {
+		Workers int           `cfg:"workers" metric:"true"`
+		Timeout time.Duration `cfg:"timeout" metric:"true"`
+		Name    string        `cfg:"name" metric:"true"`
+		Debug   bool          `cfg:"debug"`
+		Ratio   *float64      `cfg:"ratio" metric:"true"`
+		Limit   *uint         `cfg:"limit" metric:"true"`
+		Cache   struct {
+			Enabled bool    `cfg:"enabled"`
+			Size    uint64  `cfg:"size"`
+			Load    float32 `cfg:"load"`
+		} `cfg:"cache" metric:"true"`
+	}
+
+	var cfg Config
+	cfg.Workers = 8
+	cfg.Timeout = 1500 * time.Millisecond
+	cfg.Name = "app"
+	cfg.Debug = true
+	cfg.Ratio = &ratio
+	cfg.Cache.Enabled = true
+	cfg.Cache.Size = 1024
+	cfg.Cache.Load = 0.5
+
+	var sb strings.Builder
+	if err := WriteMetrics(&sb, &cfg); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := `# HELP config_value Current value of a config field.
+# TYPE config_value gauge
+config_value{path="workers"} 8
+config_value{path="timeout"} 1.5
+config_value{path="ratio"} 0.25
+config_value{path="cache.enabled"} 1
+config_value{path="cache.size"} 1024
+config_value{path="cache.load"} 0.5
+`
+	if got := sb.String(); got != want {
+		t.Errorf("\nwant %s\ngot  %s", want, got)
+	}
+
+	t.Run("handler", func(t *testing.T) {
+		h := MetricsHandler(&cfg)
+		cfg.Workers = 16
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+		body, _ := io.ReadAll(rec.Body)
+		if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") ||
+			!strings.Contains(string(body), `config_value{path="workers"} 16`) {
+			t.Errorf("unexpected response %s", body)
+		}
+	})
+}
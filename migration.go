@@ -0,0 +1,111 @@
+package cfg
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+const (
+	// VersionKey is the key of the config file that holds the version of
+	// its schema.
+	VersionKey = "version"
+	// VersionTag is the struct tag key that annotates the schema version
+	// that a config struct expects.
+	VersionTag = "cfgversion"
+)
+
+// Migration upgrades the raw values of a config file to a newer version of
+// its schema.
+type Migration struct {
+	// Version is the schema version that the migration upgrades to.
+	Version int
+	// Migrate transforms the raw values of the file in place, e.g. by
+	// renaming keys or splitting sections.
+	Migrate func(vals map[string]interface{}) error
+}
+
+// migrate runs the migrations whose version is newer than the version
+// found in vals. migrations run in the order they were given and the
+// version key of vals is bumped after each one.
+func (f *cfg) migrate(vals map[string]interface{}) error {
+	if len(f.migrations) == 0 {
+		return nil
+	}
+
+	version, err := fileVersion(vals)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range f.migrations {
+		if m.Version <= version {
+			continue
+		}
+		if err := m.Migrate(vals); err != nil {
+			return fmt.Errorf("migration to version %d: %w", m.Version, err)
+		}
+		version = m.Version
+		vals[VersionKey] = version
+	}
+
+	return nil
+}
+
+// fileVersion returns the schema version stored in vals. A missing
+// version is reported as 0.
+func fileVersion(vals map[string]interface{}) (int, error) {
+	v, ok := vals[VersionKey]
+	if !ok || v == nil {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(fmt.Sprint(v))
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", VersionKey, v, err)
+	}
+	return version, nil
+}
+
+// checkVersion returns ErrVersionMismatch if vals declares a schema
+// version other than the one expected. files that do not declare a
+// version are not checked.
+func (f *cfg) checkVersion(vals map[string]interface{}) error {
+	if f.schemaVersion == 0 {
+		return nil
+	}
+	if _, ok := vals[VersionKey]; !ok {
+		return nil
+	}
+
+	version, err := fileVersion(vals)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case version < f.schemaVersion:
+		return fmt.Errorf("%w: file is at version %d but version %d is expected, migrate the file", ErrVersionMismatch, version, f.schemaVersion)
+	case version > f.schemaVersion:
+		return fmt.Errorf("%w: file is at version %d but only version %d is supported, upgrade the application", ErrVersionMismatch, version, f.schemaVersion)
+	}
+	return nil
+}
+
+// structVersion returns the schema version annotated in the `cfgversion`
+// tag of any of the top-level fields of the struct pointed to by cfg.
+// 0 is returned if there is no such annotation.
+func structVersion(cfg interface{}) (int, error) {
+	t := reflect.TypeOf(cfg).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		val, ok := t.Field(i).Tag.Lookup(VersionTag)
+		if !ok {
+			continue
+		}
+		version, err := strconv.Atoi(val)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s tag %q: %w", VersionTag, val, err)
+		}
+		return version, nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,205 @@
+package cfg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_cfg_Load_Migrations(t *testing.T) {
+	type Config struct {
+		Version int `cfg:"version"`
+		Logger  struct {
+			Level string `cfg:"level"`
+		} `cfg:"logger"`
+		Host string `cfg:"host"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "loglevel: debug\nhostname: localhost\n")
+
+	renameHost := Migration{Version: 1, Migrate: func(vals map[string]interface{}) error {
+		vals["host"] = vals["hostname"]
+		delete(vals, "hostname")
+		return nil
+	}}
+	nestLogger := Migration{Version: 2, Migrate: func(vals map[string]interface{}) error {
+		vals["logger"] = map[string]interface{}{"level": vals["loglevel"]}
+		delete(vals, "loglevel")
+		return nil
+	}}
+
+	var cfg Config
+	err := Load(&cfg, Dirs(dir), Migrations(renameHost, nestLogger))
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	var want Config
+	want.Version = 2
+	want.Logger.Level = "debug"
+	want.Host = "localhost"
+
+	if !reflect.DeepEqual(want, cfg) {
+		t.Errorf("\nwant %+v\ngot %+v", want, cfg)
+	}
+}
+
+func Test_cfg_migrate(t *testing.T) {
+	var ran []int
+	record := func(version int) Migration {
+		return Migration{Version: version, Migrate: func(map[string]interface{}) error {
+			ran = append(ran, version)
+			return nil
+		}}
+	}
+
+	t.Run("skips migrations up to the file version", func(t *testing.T) {
+		ran = nil
+		conf := defaultCfg()
+		conf.migrations = []Migration{record(1), record(2), record(3)}
+
+		vals := map[string]interface{}{"version": "2"}
+		if err := conf.migrate(vals); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		if !reflect.DeepEqual([]int{3}, ran) {
+			t.Errorf("want migrations [3] to run, got %v", ran)
+		}
+		if vals["version"] != 3 {
+			t.Errorf("want version 3, got %v", vals["version"])
+		}
+	})
+
+	t.Run("invalid version", func(t *testing.T) {
+		conf := defaultCfg()
+		conf.migrations = []Migration{record(1)}
+
+		err := conf.migrate(map[string]interface{}{"version": "two"})
+		if err == nil {
+			t.Fatal("expected err")
+		}
+	})
+
+	t.Run("migration error", func(t *testing.T) {
+		conf := defaultCfg()
+		conf.migrations = []Migration{{Version: 1, Migrate: func(map[string]interface{}) error {
+			return fmt.Errorf("boom")
+		}}}
+
+		err := conf.migrate(map[string]interface{}{})
+		if err == nil || !strings.Contains(err.Error(), "migration to version 1: boom") {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+}
+
+func Test_cfg_Load_SchemaVersion(t *testing.T) {
+	type Config struct {
+		Version int    `cfg:"version" cfgversion:"2"`
+		Host    string `cfg:"host"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "version: 1\nhost: localhost\n")
+
+	t.Run("older file is rejected", func(t *testing.T) {
+		var cfg Config
+		err := Load(&cfg, Dirs(dir))
+		if !errors.Is(err, ErrVersionMismatch) {
+			t.Fatalf("want ErrVersionMismatch, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "migrate") {
+			t.Errorf("want hint to migrate in err, got %v", err)
+		}
+	})
+
+	t.Run("newer file is rejected", func(t *testing.T) {
+		newer := t.TempDir()
+		writeFile(t, filepath.Join(newer, "config.yaml"), "version: 3\nhost: localhost\n")
+
+		var cfg Config
+		err := Load(&cfg, Dirs(newer))
+		if !errors.Is(err, ErrVersionMismatch) {
+			t.Fatalf("want ErrVersionMismatch, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "upgrade") {
+			t.Errorf("want hint to upgrade in err, got %v", err)
+		}
+	})
+
+	t.Run("migrated file is accepted", func(t *testing.T) {
+		var cfg Config
+		err := Load(&cfg, Dirs(dir), Migrations(Migration{Version: 2, Migrate: func(map[string]interface{}) error {
+			return nil
+		}}))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Version != 2 {
+			t.Errorf("want version 2, got %d", cfg.Version)
+		}
+	})
+
+	t.Run("sources raise no warning", func(t *testing.T) {
+		var cfg Config
+		res, err := LoadResult(&cfg, IgnoreFile(), WithSources(MapSource{"host": "localhost"}))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if len(res.Warnings) != 0 {
+			t.Errorf("want no warnings, got %v", res.Warnings)
+		}
+	})
+
+	t.Run("unversioned file raises no warning without an expected version", func(t *testing.T) {
+		unversioned := t.TempDir()
+		writeFile(t, filepath.Join(unversioned, "config.yaml"), "host: localhost\n")
+
+		var cfg struct {
+			Host string `cfg:"host"`
+		}
+		res, err := LoadResult(&cfg, Dirs(unversioned))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if len(res.Warnings) != 0 {
+			t.Errorf("want no warnings, got %v", res.Warnings)
+		}
+	})
+}
+
+func Test_structVersion(t *testing.T) {
+	var valid struct {
+		Version int `cfgversion:"3"`
+	}
+	if v, err := structVersion(&valid); err != nil || v != 3 {
+		t.Errorf("want 3, got %d (err %v)", v, err)
+	}
+
+	var none struct {
+		Version int
+	}
+	if v, err := structVersion(&none); err != nil || v != 0 {
+		t.Errorf("want 0, got %d (err %v)", v, err)
+	}
+
+	var invalid struct {
+		Version int `cfgversion:"x"`
+	}
+	if _, err := structVersion(&invalid); err == nil {
+		t.Error("expected err")
+	}
+}
+
+func writeFile(t *testing.T, name, data string) {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(data), 0o600); err != nil {
+		t.Fatalf("unable to write %s: %v", name, err)
+	}
+}
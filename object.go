@@ -0,0 +1,186 @@
+package cfg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// isObjectURL reports whether name is the URL of an object in S3
+// (`s3://bucket/key`) or Google Cloud Storage (`gs://bucket/object`).
+func isObjectURL(name string) bool {
+	return strings.HasPrefix(name, "s3://") || strings.HasPrefix(name, "gs://")
+}
+
+// dirOf returns the directory of the file or object name.
+func dirOf(name string) string {
+	if isObjectURL(name) {
+		return name[:strings.LastIndex(name, "/")]
+	}
+	return filepath.Dir(name)
+}
+
+// joinFile joins the directory of a file or object with elems.
+func joinFile(dir string, elems ...string) string {
+	if isObjectURL(dir) {
+		return strings.Join(append([]string{dir}, elems...), "/")
+	}
+	return filepath.Join(append([]string{dir}, elems...)...)
+}
+
+// openObject downloads the object at the URL name.
+func (f *cfg) openObject(name string) (io.ReadCloser, error) {
+	resp, err := f.requestObject(http.MethodGet, name)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: unexpected status %s", name, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// objectExists returns true if the object at the URL name exists, or false
+// if it is not found. Other responses, such as 403 Forbidden, and failed
+// requests are returned as errors so that they are not mistaken for a
+// missing object.
+func (f *cfg) objectExists(name string) (bool, error) {
+	resp, err := f.requestObject(http.MethodHead, name)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("%s: unexpected status %s", name, resp.Status)
+	}
+}
+
+// requestObject sends a request for the object at the URL name.
+//
+// S3 requests are signed with the credentials of the `AWS_ACCESS_KEY_ID`,
+// `AWS_SECRET_ACCESS_KEY` and `AWS_SESSION_TOKEN` env vars, if set, and
+// sent to the `AWS_ENDPOINT_URL_S3` or `AWS_ENDPOINT_URL` env var, if set.
+// GCS requests are authorized with a token of the metadata server, unless
+// they're sent to the emulator of the `STORAGE_EMULATOR_HOST` env var.
+// The client of WithHTTPClient is only used with such custom endpoints.
+func (f *cfg) requestObject(method, name string) (*http.Response, error) {
+	ctx := f.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	_, path, _ := strings.Cut(name, "://")
+	bucket, key, _ := strings.Cut(path, "/")
+	if bucket == "" || key == "" {
+		return nil, fmt.Errorf("%s: invalid object url", name)
+	}
+
+	var req *http.Request
+	var err error
+	var custom bool
+	if strings.HasPrefix(name, "s3://") {
+		req, err = newS3Request(ctx, method, bucket, key)
+		custom = firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL") != ""
+	} else {
+		req, err = newGCSRequest(ctx, method, bucket, key)
+		custom = os.Getenv("STORAGE_EMULATOR_HOST") != ""
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	resp, err := endpointClient(ctx, custom).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return resp, nil
+}
+
+// newS3Request creates a request for the object key of an S3 bucket.
+func newS3Request(ctx context.Context, method, bucket, key string) (*http.Request, error) {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	u := "https://" + bucket + ".s3." + region + ".amazonaws.com/" + escapeObjectKey(key)
+	if endpoint := firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"); endpoint != "" {
+		u = strings.TrimSuffix(endpoint, "/") + "/" + bucket + "/" + escapeObjectKey(key)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	accessKey, secretKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey != "" && secretKey != "" {
+		req.Header.Set("X-Amz-Content-Sha256", sha256Hex(nil))
+		if token := os.Getenv("AWS_SESSION_TOKEN"); token != "" {
+			req.Header.Set("X-Amz-Security-Token", token)
+		}
+		signAWSRequest(req, nil, accessKey, secretKey, region, "s3", time.Now().UTC())
+	}
+	return req, nil
+}
+
+// newGCSRequest creates a request for an object of a GCS bucket.
+func newGCSRequest(ctx context.Context, method, bucket, object string) (*http.Request, error) {
+	endpoint := "https://storage.googleapis.com"
+	emulator := os.Getenv("STORAGE_EMULATOR_HOST")
+	if emulator != "" {
+		endpoint = emulator
+		if !strings.Contains(endpoint, "://") {
+			endpoint = "http://" + endpoint
+		}
+	}
+
+	u := strings.TrimSuffix(endpoint, "/") + "/storage/v1/b/" + url.PathEscape(bucket) + "/o/" + url.PathEscape(object)
+	if method == http.MethodGet {
+		u += "?alt=media"
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if emulator == "" {
+		token, err := gcpAccessToken(ctx, nil, "")
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req, nil
+}
+
+// escapeObjectKey escapes each segment of an object key.
+func escapeObjectKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// firstEnv returns the value of the first of keys that is set.
+func firstEnv(keys ...string) string {
+	for _, key := range keys {
+		if val := os.Getenv(key); val != "" {
+			return val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,167 @@
+package cfg
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// objectServer serves objects by escaped path, answering HEAD and GET
+// requests that pass check.
+func objectServer(t *testing.T, objects map[string]string, check func(r *http.Request) bool) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !check(r) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		obj, ok := objects[r.URL.EscapedPath()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(obj))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+type objectConfig struct {
+	Name string `cfg:"name"`
+	Port int    `cfg:"port" default:"80"`
+}
+
+func Test_cfg_Load_S3(t *testing.T) {
+	srv := objectServer(t, map[string]string{
+		"/configs/tenants/tenant-42.yaml":             "name: tenant-42\n",
+		"/configs/tenants/scopes/prod.yaml":           "port: 443\n",
+		"/configs/tenants/tenant%2043/config%3F.yaml": "name: '43'\n",
+	}, func(r *http.Request) bool {
+		return strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") &&
+			r.Header.Get("X-Amz-Content-Sha256") != ""
+	})
+	setenv(t, "AWS_ENDPOINT_URL_S3", srv.URL)
+	setenv(t, "AWS_REGION", "eu-west-1")
+	setenv(t, "AWS_ACCESS_KEY_ID", "AKID")
+	setenv(t, "AWS_SECRET_ACCESS_KEY", "secret")
+	setenv(t, "TENANT", "tenant-42")
+
+	t.Run("object", func(t *testing.T) {
+		var cfg objectConfig
+		res, err := LoadResult(&cfg, File("s3://configs/tenants/${TENANT}.yaml"), Scope("prod"))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Name != "tenant-42" || cfg.Port != 443 {
+			t.Errorf("unexpected cfg %+v", cfg)
+		}
+		want := []string{"s3://configs/tenants/tenant-42.yaml", "s3://configs/tenants/scopes/prod.yaml"}
+		if len(res.Files) != 2 || res.Files[0] != want[0] || res.Files[1] != want[1] {
+			t.Errorf("want files %v, got %v", want, res.Files)
+		}
+	})
+
+	t.Run("escaped key", func(t *testing.T) {
+		var cfg objectConfig
+		if err := Load(&cfg, File("s3://configs/tenants/tenant 43/config?.yaml")); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Name != "43" {
+			t.Errorf("unexpected cfg %+v", cfg)
+		}
+	})
+
+	t.Run("missing object", func(t *testing.T) {
+		var cfg objectConfig
+		err := Load(&cfg, File("s3://configs/missing.yaml"))
+		if !errors.Is(err, ErrFileNotFound) {
+			t.Fatalf("want ErrFileNotFound, got %v", err)
+		}
+	})
+
+	t.Run("forbidden object", func(t *testing.T) {
+		setenv(t, "AWS_ACCESS_KEY_ID", "OTHER")
+
+		var cfg objectConfig
+		err := Load(&cfg, File("s3://configs/tenants/tenant-42.yaml"))
+		if err == nil || errors.Is(err, ErrFileNotFound) || !strings.Contains(err.Error(), "403") {
+			t.Fatalf("want 403 err, got %v", err)
+		}
+	})
+}
+
+func Test_cfg_Load_GCS(t *testing.T) {
+	srv := objectServer(t, map[string]string{
+		"/storage/v1/b/configs/o/tenants%2Ftenant-42.json": `{"name": "tenant-42"}`,
+	}, func(r *http.Request) bool {
+		return r.Method == http.MethodHead || r.URL.Query().Get("alt") == "media"
+	})
+	setenv(t, "STORAGE_EMULATOR_HOST", strings.TrimPrefix(srv.URL, "http://"))
+
+	var cfg objectConfig
+	if err := Load(&cfg, File("gs://configs/tenants/tenant-42.json")); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if cfg.Name != "tenant-42" || cfg.Port != 80 {
+		t.Errorf("unexpected cfg %+v", cfg)
+	}
+}
+
+func Test_joinFile(t *testing.T) {
+	if got := joinFile(dirOf("s3://b/a/config.yaml"), "scopes", "x.yaml"); got != "s3://b/a/scopes/x.yaml" {
+		t.Errorf("unexpected path %s", got)
+	}
+	if got := dirOf("gs://b/config.yaml"); got != "gs://b" {
+		t.Errorf("unexpected dir %s", got)
+	}
+}
+
+func Test_cfg_Load_WithHTTPClient(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v3/kv/range" {
+			_, _ = w.Write([]byte(`{"kvs": [{"key": "L215YXBw", "value": "bmFtZTogZXRjZA=="}]}`))
+			return
+		}
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`{"name": "tls"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	setenv(t, "STORAGE_EMULATOR_HOST", srv.URL)
+
+	t.Run("untrusted certificate", func(t *testing.T) {
+		var cfg objectConfig
+		err := Load(&cfg, File("gs://configs/config.json"))
+		if err == nil || errors.Is(err, ErrFileNotFound) || !strings.Contains(err.Error(), "certificate") {
+			t.Fatalf("want certificate err, got %v", err)
+		}
+	})
+
+	t.Run("client", func(t *testing.T) {
+		var cfg objectConfig
+		if err := Load(&cfg, File("gs://configs/config.json"), WithHTTPClient(srv.Client())); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Name != "tls" {
+			t.Errorf("unexpected cfg %+v", cfg)
+		}
+	})
+
+	t.Run("default client of sources", func(t *testing.T) {
+		type Config struct {
+			Name string `cfg:"name"`
+		}
+		var cfg Config
+		src := &EtcdSource{Endpoint: srv.URL, Key: "/myapp"}
+		if err := Load(&cfg, IgnoreFile(), WithSources(src), WithHTTPClient(srv.Client())); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Name != "etcd" {
+			t.Errorf("unexpected cfg %+v", cfg)
+		}
+	})
+}
@@ -1,16 +1,70 @@
 package cfg
 
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
 // Option configures how cfg loads the configuration.
 type Option func(f *cfg)
 
+var (
+	defaultOptionsMu sync.RWMutex
+	defaultOptions   []Option
+)
+
+// SetDefaultOptions sets options that apply to every subsequent Load, and
+// to the other functions that take options, before their own options,
+// which take precedence. It lets a wrapper package enforce the conventions
+// of an organization once, typically at init time:
+//
+//	func init() {
+//	  cfg.SetDefaultOptions(cfg.Tag("config"), cfg.TimeLayout(time.RFC3339), cfg.UseEnv("acme"))
+//	}
+//
+// Each call replaces the options of the previous one, so calling it without
+// options clears them.
+func SetDefaultOptions(options ...Option) {
+	defaultOptionsMu.Lock()
+	defer defaultOptionsMu.Unlock()
+	defaultOptions = append([]Option(nil), options...)
+}
+
+// applyDefaultOptions applies the options of SetDefaultOptions to f.
+func applyDefaultOptions(f *cfg) {
+	defaultOptionsMu.RLock()
+	options := defaultOptions
+	defaultOptionsMu.RUnlock()
+	for _, opt := range options {
+		opt(f)
+	}
+}
+
 // File returns an option that configures the filename that cfg
 // looks for to provide the config values.
 //
 // The name must include the extension of the file. Supported
-// file types are `yaml`, `yml`, `json` and `toml`.
+// file types are `yaml`, `yml`, `json`, `json5`, `toml`, `xml`, `properties`
+// and `env`. Other file types can be supported with RegisterDecoder.
+// Compressed files, e.g. `routes.yaml.gz`, are decompressed first, see
+// RegisterDecompressor.
+//
+// Variables in `env` (dotenv) files are mapped onto fields using the same
+// rules as `UseEnv`, and are overridden by the environment.
 //
 //	cfg.Load(&cfg, cfg.File("config.toml"))
 //
+// Env vars referenced in name are expanded as they are in Dirs.
+//
+// name may also be the URL of an object in S3 (`s3://bucket/key`) or
+// Google Cloud Storage (`gs://bucket/object`), which is downloaded
+// regardless of Dirs.
+//
 // If this option is not used then cfg looks for a file with name `config.yaml`.
 func File(name string) Option {
 	return func(f *cfg) {
@@ -21,6 +75,72 @@ func File(name string) Option {
 	}
 }
 
+// FileSpec is a config file given to Files, see Required and Optional.
+type FileSpec struct {
+	Name     string // name of the file, as given to File.
+	Required bool   // true if Load fails when the file is not found.
+}
+
+// Required returns the spec of a config file that must be found in one of
+// the dirs. A glob pattern must match at least one file.
+func Required(name string) FileSpec {
+	return FileSpec{Name: name, Required: true}
+}
+
+// Optional returns the spec of a config file that is loaded if it is found
+// and skipped otherwise.
+func Optional(name string) FileSpec {
+	return FileSpec{Name: name}
+}
+
+// Files returns an option that configures the config files to load and
+// replaces the default filenames, so that callers control the order in
+// which files are merged and which missing files are fatal:
+//
+//	cfg.Load(&cfg,
+//	  cfg.Files(cfg.Required("config.yaml"), cfg.Optional("secret.yaml"), cfg.Optional("conf.d/*.yaml")),
+//	  cfg.Dirs("/etc/myapp"),
+//	)
+//
+// Files are loaded in order, each one merged over the previous ones. Names
+// may be glob patterns as understood by filepath.Match that match every
+// file in lexical order, e.g. the drop-in files of a `conf.d` directory,
+// and are otherwise interpreted like the name of File. Patterns are
+// matched in Dirs only, not in object URLs.
+//
+// Load returns an error wrapping ErrFileNotFound if a required file is not
+// found. Unlike with File, it is not an error that no optional file is
+// found.
+func Files(files ...FileSpec) Option {
+	return func(f *cfg) {
+		f.filename = make([]string, 0, len(files))
+		f.required = make(map[string]bool)
+		for _, file := range files {
+			f.filename = append(f.filename, file.Name)
+			if file.Required {
+				f.required[file.Name] = true
+			}
+		}
+	}
+}
+
+// DetectAmbiguity returns an option that configures cfg to detect config
+// files that are copies or variants of one another, e.g. both `config.yaml`
+// and `config.yml`, or `config.yaml` in more than one of the Dirs, which
+// are otherwise silently merged in search order. mode is AmbiguityWarn to
+// load them and report all matched paths as a warning, or AmbiguityError to
+// fail:
+//
+//	cfg.Load(&cfg, cfg.Dirs(".", "/etc/myapp"), cfg.DetectAmbiguity(cfg.AmbiguityError))
+//
+// Files are variants of one another if their names are equal once their
+// extension is removed.
+func DetectAmbiguity(mode AmbiguityMode) Option {
+	return func(f *cfg) {
+		f.ambiguity = mode
+	}
+}
+
 // IgnoreFile returns an option which disables any file lookup.
 //
 // This option effectively renders any `File` and `Dir` options useless. This option
@@ -34,6 +154,34 @@ func IgnoreFile() Option {
 	}
 }
 
+// FileFromEnv returns an option that configures cfg to load the config
+// file at the path in the env var env, if it is set, so that operators can
+// point an app at any config file at runtime:
+//
+//	cfg.Load(&cfg, cfg.FileFromEnv("MYAPP_CONFIG"), cfg.Dirs(".", "/etc/myapp"))
+//
+// A set env var takes precedence over the File, Files and Dirs options:
+// only the named file is loaded and Load returns an error wrapping
+// ErrFileNotFound if it doesn't exist. A leading `~` is expanded to the
+// home directory and object URLs are loaded from their bucket.
+func FileFromEnv(env string) Option {
+	return func(f *cfg) {
+		f.fileEnv = env
+	}
+}
+
+// Inherited returns an option that loads the config passed by the parent
+// process with Inherit or InheritPipe, if any, in place of the config
+// files. Env vars, flags, sources and defaults still apply. The config is
+// taken when the config is loaded, and its env var is then removed so that
+// later loads, reloads and children of the process read the config files
+// again.
+func Inherited() Option {
+	return func(f *cfg) {
+		f.inherited = true
+	}
+}
+
 // Dirs returns an option that configures the directories that cfg searches
 // to find the configuration file.
 //
@@ -43,6 +191,12 @@ func IgnoreFile() Option {
 //
 //	cfg.Load(&cfg, cfg.Dirs(".", "/etc/myapp", "/home/user/myapp"))
 //
+// Env vars referenced as `$VAR` or `${VAR}` are expanded when the config is
+// loaded, e.g. `cfg.Dirs("$HOME/.config/myapp", "${RUNTIME_DIR}")`. A
+// directory that expands to an empty string is skipped. A leading `~` or
+// `~user` is expanded to the home directory of the current user or of user,
+// e.g. `cfg.Dirs("~/.myapp")`.
+//
 // If this option is not used then cfg looks in the directory it is run from.
 func Dirs(dirs ...string) Option {
 	return func(f *cfg) {
@@ -50,6 +204,66 @@ func Dirs(dirs ...string) Option {
 	}
 }
 
+// SearchParents returns an option that configures cfg to search the
+// parents of each dir for the config files when the dir has none, up to
+// the root, like git looks for `.git`. This is useful for CLI tools run
+// from nested directories of a project:
+//
+//	cfg.Load(&cfg, cfg.File(".myapp.yaml"), cfg.SearchParents())
+//
+// The files of the closest dir that has any are loaded. Parents are not
+// searched in the file system of the FS option.
+func SearchParents() Option {
+	return func(f *cfg) {
+		f.searchParents = true
+	}
+}
+
+// UseXDG returns an option that configures cfg to search the config dirs
+// of app according to the XDG base directory spec, so that desktop and CLI
+// tools follow the spec without building the list of dirs by hand:
+//
+//	cfg.Load(&cfg, cfg.UseXDG("myapp"))
+//
+// searches `$XDG_CONFIG_HOME/myapp`, or `~/.config/myapp` if the variable
+// is unset, and each dir of `$XDG_CONFIG_DIRS`, or `/etc/xdg/myapp` if the
+// variable is unset. The XDG dirs are searched before the dirs of the Dirs
+// option, so the files of the latter take precedence, and user dirs take
+// precedence over system dirs.
+func UseXDG(app string) Option {
+	return func(f *cfg) {
+		f.xdgApp = app
+	}
+}
+
+// PlatformDirs returns an option that configures cfg to search the
+// idiomatic config dirs of app on the platform it runs on, so that the same
+// binary finds its config on every platform:
+//
+//	cfg.Load(&cfg, cfg.PlatformDirs("myapp"))
+//
+// searches `%PROGRAMDATA%\myapp` and `%APPDATA%\myapp` on Windows, the
+// latter taking precedence, and the dirs of UseXDG elsewhere. Like those of
+// UseXDG, the dirs are searched before the dirs of the Dirs option.
+func PlatformDirs(app string) Option {
+	return func(f *cfg) {
+		f.platformApp = app
+	}
+}
+
+// Lint returns an option that configures cfg to warn about values that
+// are likely mistakes: durations set from numbers without a unit, which are
+// read as nanoseconds, ports out of range, paths with trailing spaces and
+// times more than 100 years in the past or future. Warnings don't fail the
+// load and are reported by LoadResult.
+//
+//	res, err := cfg.LoadResult(&cfg, cfg.Lint())
+func Lint() Option {
+	return func(f *cfg) {
+		f.lint = true
+	}
+}
+
 // Tag returns an option that configures the tag key that cfg uses
 // when for the alt name struct tag key in fields.
 //
@@ -102,6 +316,20 @@ func TimeLayout(layout string) Option {
 //	MYAPP_BUILD
 //	MYAPP_LOG_LEVEL
 //	MYAPP_SERVER_HOST
+//
+// A field tagged with `env:"NAME"` is set by the env var NAME instead, e.g.
+// `env:"DATABASE_URL"` for a variable with a fixed, well-known name.
+//
+// Structs, maps and slices of structs are set as a whole from JSON, e.g.
+// `MYAPP_SERVER={"host": "x", "port": 9}`.
+//
+// If an env var is unset but the same name suffixed with `_FILE` is set,
+// e.g. `MYAPP_DB_PASSWORD_FILE=/run/secrets/db`, the field is set to the
+// contents of the file it names, without trailing newlines. Setting both is
+// an error.
+//
+// Fields tagged with `env:"-"`, and the fields nested in them, are never
+// set from env vars or dotenv files.
 func UseEnv(prefix string) Option {
 	return func(f *cfg) {
 		f.useEnv = true
@@ -109,6 +337,39 @@ func UseEnv(prefix string) Option {
 	}
 }
 
+// EnvSeparator returns an option that configures the separator that joins
+// the prefix and the names of nested fields in the names of env vars,
+// which is `_` by default. A longer separator tells nesting apart from
+// field names that contain underscores:
+//
+//	cfg.Load(&cfg, cfg.UseEnv("myapp"), cfg.EnvSeparator("__"))
+//
+// With the option above the field `logger.log_level` is set by
+// MYAPP__LOGGER__LOG_LEVEL.
+func EnvSeparator(sep string) Option {
+	return func(f *cfg) {
+		f.envSep = sep
+	}
+}
+
+// EnvKeyFunc returns an option that configures cfg to name the env var of
+// each field with fn, given the field's path, e.g. `server.host` or
+// `loggers[0].level`, in place of the PREFIX_FIELD_PATH format of UseEnv,
+// so that existing env naming conventions can be kept. The prefix of UseEnv
+// is not applied and UseEnv must still be given to read env vars:
+//
+//	cfg.Load(&cfg, cfg.UseEnv(""), cfg.EnvKeyFunc(func(path string) string {
+//	  if path == "port" {
+//	    return "PORT" // set by the platform
+//	  }
+//	  return "myApp." + path
+//	}))
+func EnvKeyFunc(fn func(fieldPath string) string) Option {
+	return func(f *cfg) {
+		f.envKeyFunc = fn
+	}
+}
+
 // UseStrict returns an option that configures cfg to return an error if
 // there exists additional fields in the config file that are not defined
 // in the config struct.
@@ -121,3 +382,492 @@ func UseStrict() Option {
 		f.useStrict = true
 	}
 }
+
+// StrictEnv returns an option that configures cfg to return an error
+// wrapping ErrUnusedEnv if env vars under the prefix of UseEnv, e.g.
+// `MYAPP_`, exist that set no field, such as the typo `MYAPP_SERVER_PRT`,
+// which would otherwise be ignored. With SubCommand, only the env vars of
+// the sub-command are checked.
+//
+//	cfg.Load(&cfg, cfg.UseEnv("myapp"), cfg.StrictEnv())
+func StrictEnv() Option {
+	return func(f *cfg) {
+		f.strictEnv = true
+	}
+}
+
+// Migrations returns an option that configures cfg to upgrade config files
+// written for an older version of the config struct before they are decoded.
+//
+// The version of a file is read from its top-level `version` key. Each
+// migration whose version is newer than the file's version is run in the
+// order given, after which the file's version is bumped to the migration's.
+//
+//	cfg.Load(&cfg, cfg.Migrations(
+//	  cfg.Migration{Version: 2, Migrate: func(vals map[string]interface{}) error {
+//	    vals["log_level"] = vals["loglevel"]
+//	    delete(vals, "loglevel")
+//	    return nil
+//	  }},
+//	))
+//
+// A file without a version key is considered to be at version 0.
+func Migrations(migrations ...Migration) Option {
+	return func(f *cfg) {
+		f.migrations = append(f.migrations, migrations...)
+	}
+}
+
+// SchemaVersion returns an option that configures cfg to return an error
+// wrapping ErrVersionMismatch if a config file declares, in its top-level
+// `version` key, a schema version other than version. The check runs after
+// any `Migrations` have been applied.
+//
+//	cfg.Load(&cfg, cfg.SchemaVersion(2))
+//
+// The expected version can alternatively be annotated on a top-level field
+// of the config struct:
+//
+//	type Config struct {
+//	  Version int `cfg:"version" cfgversion:"2"`
+//	}
+//
+// Files that do not declare a version are not checked, but raise a
+// warning in the Result. The values of sources given with WithSources are
+// only checked if they declare a version, and raise no warning otherwise.
+func SchemaVersion(version int) Option {
+	return func(f *cfg) {
+		f.schemaVersion = version
+	}
+}
+
+// PathFields returns an option that configures cfg to treat the fields at
+// the given paths as if they had a `path:"true"` struct tag. Paths are
+// formed the same way as in field errors, i.e. dot separated names.
+//
+//	cfg.Load(&cfg, cfg.PathFields("server.tls.cert", "data_dir"))
+//
+// Relative paths in these fields are resolved against the directory of the
+// loaded config file rather than the working directory of the process.
+func PathFields(paths ...string) Option {
+	return func(f *cfg) {
+		if f.pathFields == nil {
+			f.pathFields = make(map[string]bool)
+		}
+		for _, p := range paths {
+			f.pathFields[p] = true
+		}
+	}
+}
+
+// Scope returns an option that configures cfg to overlay the values of a
+// named scope, such as a tenant or a region, over the values of each config
+// file.
+//
+// A scope's values are taken from the subtree under the top-level `scopes`
+// key of the file and from a file named after the scope in a `scopes`
+// directory next to the file, in that order:
+//
+//	# config.yaml
+//	server:
+//	  host: 0.0.0.0
+//	  port: 80
+//	scopes:
+//	  tenant-42:
+//	    server:
+//	      port: 8042
+//
+//	# scopes/tenant-42.yaml
+//	server:
+//	  host: 10.0.0.42
+//
+//	cfg.Load(&cfg, cfg.Scope("tenant-42")) // server: {host: 10.0.0.42, port: 8042}
+//
+// Nested sections are merged with the base values while other values
+// replace them. Load a separate struct for every scope to obtain isolated
+// configs that share the same base.
+func Scope(name string) Option {
+	return func(f *cfg) {
+		f.scope = name
+	}
+}
+
+// Profile returns an option that configures cfg to merge the values of a
+// named profile, such as an environment, over the base values of each
+// config file, so that one file can hold the config of every environment.
+//
+// A profile's values are taken from its subtree under the top-level
+// `profiles` key of the file:
+//
+//	# config.yaml
+//	server:
+//	  host: 0.0.0.0
+//	  port: 80
+//	profiles:
+//	  dev:
+//	    server:
+//	      host: localhost
+//
+//	cfg.Load(&cfg, cfg.Profile("dev")) // server: {host: localhost, port: 80}
+//
+// Profiles are merged before scopes, so a profile may set the values of a
+// scope in turn.
+func Profile(name string) Option {
+	return func(f *cfg) {
+		f.profile = name
+	}
+}
+
+// Vars returns an option that configures cfg to parameterize the config
+// with named variables, e.g. to load one config artifact per deployment.
+//
+// Config files are executed as text/template templates with vars as their
+// data, and `${name}` references in the values of config files, env vars
+// and defaults are replaced with the value of the named variable:
+//
+//	# config.yaml
+//	bucket: logs-{{ .region }}
+//	endpoint: https://${region}.example.com
+//
+//	cfg.Load(&cfg, cfg.Vars(map[string]string{"region": "eu-1"}))
+//
+// Referencing an undefined variable is an error.
+func Vars(vars map[string]string) Option {
+	return func(f *cfg) {
+		f.vars = vars
+	}
+}
+
+// RuntimeVars returns an option that configures cfg to define variables
+// describing the running process, in addition to those of Vars:
+//
+//	runtime.hostname   the host name reported by the kernel
+//	runtime.pid        the process id
+//	runtime.goVersion  the Go version the binary was built with
+//	runtime.os         the operating system, e.g. linux
+//	runtime.arch       the architecture, e.g. amd64
+//
+// Fields are bound to them with defaults, e.g. `default:"${runtime.hostname}"`.
+// As with Vars, config files are executed as templates, in which the
+// variables are referenced with the index function, e.g.
+// `{{ index . "runtime.pid" }}`. Variables of Vars with the same name take
+// precedence.
+func RuntimeVars() Option {
+	return func(f *cfg) {
+		f.runtimeVars = true
+	}
+}
+
+// ExpandEnv returns an option that configures cfg to expand references to
+// env vars in the string values of config files, in the form `$NAME` or
+// `${NAME}`, before they're decoded. Unset env vars expand to nothing, and
+// `$$` is a literal `$`:
+//
+//	# config.yaml
+//	path: ${HOME}/data
+//	url: $BASE_URL/api
+//	price: $$5
+//
+// Variables of Vars take precedence over env vars of the same name.
+func ExpandEnv() Option {
+	return func(f *cfg) {
+		f.expandEnv = true
+	}
+}
+
+// Freeze returns an option that configures cfg to record a hash of each
+// field of the config struct once it is loaded. CheckUnchanged then reports
+// the fields that were mutated since. It is meant as a debugging aid in
+// tests. The hashes are kept until Forget is called with the config.
+func Freeze() Option {
+	return func(f *cfg) {
+		f.frozen = true
+	}
+}
+
+// WithSources returns an option that configures cfg to read values from
+// sources in addition to the config files. The values of each source are
+// decoded in the order given, after the config files, so that they
+// override the values of the files and of preceding sources. They then go
+// through the same defaults, env and validation steps.
+//
+// Sources suffice on their own: no error is returned if no config file is
+// found when sources are given.
+func WithSources(sources ...Source) Option {
+	return func(f *cfg) {
+		f.sources = append(f.sources, sources...)
+	}
+}
+
+// CompatCheck returns an option that configures Reload to run check on the
+// current and the reloaded config before applying the latter, e.g. to
+// forbid shrinking a pool below its current usage. T is the type of the
+// config given to Reload. The reloaded config is rejected if check returns
+// an error.
+//
+//	cfg.Reload(&conf, cfg.CompatCheck(func(old, new *Config) error {
+//	  if new.Pool.Size < pool.InUse() {
+//	    return errors.New("pool size below current usage")
+//	  }
+//	  return nil
+//	}))
+func CompatCheck[T any](check func(old, new T) error) Option {
+	return func(f *cfg) {
+		f.compatChecks = append(f.compatChecks, func(old, new interface{}) error {
+			o, ok := old.(T)
+			if !ok {
+				return fmt.Errorf("check expects configs of type %T, got %T", o, old)
+			}
+			return check(o, new.(T))
+		})
+	}
+}
+
+// FS returns an option that configures cfg to search for and read config
+// files from fsys instead of the OS file system, e.g. to load configs
+// embedded with `go:embed`:
+//
+//	//go:embed config
+//	var configFS embed.FS
+//
+//	cfg.Load(&cfg, cfg.FS(configFS), cfg.Dirs("config"))
+//
+// Dirs and File must then be relative, slash separated paths within fsys.
+func FS(fsys fs.FS) Option {
+	return func(f *cfg) {
+		f.fsys = fsys
+	}
+}
+
+// MaxFileSize returns an option that limits the size in bytes of each
+// config file. Larger files fail to load with an error wrapping
+// ErrLimitExceeded. Use it with MaxKeys and MaxDepth to protect against
+// pathological untrusted or generated files.
+func MaxFileSize(size int64) Option {
+	return func(f *cfg) {
+		f.maxFileSize = size
+	}
+}
+
+// ExpectSHA256 returns an option that pins the config files, including
+// object URLs and the input of LoadReader, to the given SHA-256 digests in
+// hex: a file whose digest is not one of them fails to load with an error
+// wrapping ErrDigestMismatch. Compressed files are hashed as they are
+// stored. The archives of ArchiveSource are checked too, but not the
+// values of other sources, which are not files, nor the files of `_FILE`
+// env vars. It is meant for immutable deployments whose config digest is
+// baked into the image or manifest:
+//
+//	cfg.Load(&cfg, cfg.File("config.yaml"), cfg.ExpectSHA256(os.Getenv("CONFIG_SHA256")))
+func ExpectSHA256(digests ...string) Option {
+	return func(f *cfg) {
+		f.digests = make(map[string]bool, len(digests))
+		for _, d := range digests {
+			f.digests[strings.ToLower(d)] = true
+		}
+	}
+}
+
+// MaxKeys returns an option that limits the number of keys, including
+// nested ones, of each config file and source.
+func MaxKeys(n int) Option {
+	return func(f *cfg) {
+		f.maxKeys = n
+	}
+}
+
+// MaxDepth returns an option that limits how deeply the sections and lists
+// of each config file and source may be nested. The top level of a file
+// is at depth 1.
+func MaxDepth(n int) Option {
+	return func(f *cfg) {
+		f.maxDepth = n
+	}
+}
+
+// LoadTimeout returns an option that limits the time spent reading config
+// files and sources. The deadline is passed on to sources through their
+// context. Load returns an error wrapping context.DeadlineExceeded once it
+// is exceeded.
+func LoadTimeout(d time.Duration) Option {
+	return func(f *cfg) {
+		f.timeout = d
+	}
+}
+
+// Clock returns an option that configures the function cfg calls to get
+// the current time, e.g. for time.Time fields with `default:"now"`. Use it
+// along with FS to make tests of loading deterministic and hermetic.
+func Clock(now func() time.Time) Option {
+	return func(f *cfg) {
+		f.clock = now
+	}
+}
+
+// SubCommand returns an option that configures cfg to load the section at
+// the dot separated path of the config files into the config struct, so
+// that the sub-commands of a CLI can each read their own section of one
+// file. Env var keys are prefixed with the path, e.g. `MYAPP_SERVE_PORT`
+// for the field `port` of the section `serve` with UseEnv("myapp").
+//
+//	# config.yaml
+//	serve:
+//	  port: 8080
+//	migrate:
+//	  dir: migrations
+//
+//	cfg.Load(&serveCfg, cfg.SubCommand("serve"))
+func SubCommand(path string) Option {
+	return func(f *cfg) {
+		f.subCommand = path
+	}
+}
+
+// ReloadWindows returns an option that configures Reload to apply changes
+// only during windows, e.g. outside of peak traffic. Changes found outside
+// of the windows are deferred and applied when the next window opens,
+// unless a later call to Reload finds newer values first:
+//
+//	var quiet cfg.Window
+//	_ = quiet.UnmarshalText([]byte("Mon-Fri 22:00-06:00"))
+//	changes, err := cfg.Reload(live, cfg.ReloadWindows(quiet))
+//	if errors.Is(err, cfg.ErrReloadDeferred) {
+//	  // applied when the window opens
+//	}
+//
+// Windows are evaluated with the clock of the Clock option.
+func ReloadWindows(windows ...Window) Option {
+	return func(f *cfg) {
+		f.reloadWindows = windows
+	}
+}
+
+// ReloadRateLimit returns an option that configures Reload to apply
+// changes to a config at most once per interval. Changes found within the
+// interval are deferred like those outside of the windows of
+// ReloadWindows, and applied once the interval has passed.
+func ReloadRateLimit(interval time.Duration) Option {
+	return func(f *cfg) {
+		f.reloadEvery = interval
+	}
+}
+
+// ReloadStagger returns an option that configures Reload to delay applying
+// changes by an offset within window derived from a hash of the host name,
+// so that the instances of a fleet that reload a remote source at the same
+// time don't all apply changes and reconnect at once. Each instance always
+// gets the same offset. Reload blocks for the delay.
+//
+//	changes, err := cfg.Reload(&conf, cfg.WithSources(src), cfg.ReloadStagger(5*time.Minute))
+func ReloadStagger(window time.Duration) Option {
+	return func(f *cfg) {
+		f.reloadStagger = window
+	}
+}
+
+// Flags returns an option that configures cfg to override fields with the
+// flags of fs that were set on the command line. A flag sets the field
+// whose path equals the flag's name, e.g. the flag `-server.port` sets
+// `server.port`. Flags take precedence over env vars, and defaults apply
+// to fields that remain unset. fs must be parsed before the config is
+// loaded.
+func Flags(fs *flag.FlagSet) Option {
+	return func(f *cfg) {
+		f.flags = fs
+	}
+}
+
+// Override returns an option that configures cfg to set fields to the
+// given values above every other source, including env vars and flags,
+// e.g. to apply Helm-style `--set key=value` pairs. Keys are the paths of
+// fields, such as `server.port` or `servers[0].port`. Strings are parsed
+// like env vars and other values are decoded like config file values.
+// Keys that don't match any field raise a warning in the Result.
+//
+//	cfg.Load(&conf, cfg.Override(map[string]interface{}{"server.port": 8443}))
+//
+// The values of successive Override options are merged.
+func Override(vals map[string]interface{}) Option {
+	return func(f *cfg) {
+		if f.overrides == nil {
+			f.overrides = make(map[string]interface{})
+		}
+		for k, v := range vals {
+			f.overrides[k] = v
+		}
+	}
+}
+
+// SourceCache returns an option that configures cfg to persist the values
+// of each successful read of a source given with WithSources to a file in
+// dir, and to fall back to the last persisted values when the source can't
+// be read, e.g. so that services can restart during an outage of a config
+// server. Falling back raises a warning in the Result.
+//
+//	cfg.Load(&conf, cfg.WithSources(src), cfg.SourceCache("/var/cache/myapp"))
+//
+// Cache files are named after the position and name of their source, and
+// are only readable by their owner. A leading `~` in dir is expanded to the
+// home directory.
+func SourceCache(dir string) Option {
+	return func(f *cfg) {
+		f.cacheDir = dir
+	}
+}
+
+// Retry returns an option that configures cfg to read each source given
+// with WithSources up to attempts times until a read succeeds, e.g. so that
+// transient network failures at startup don't fail Load. The first retry
+// waits for backoff, which doubles after each failed attempt. Failed
+// attempts raise a warning in the Result.
+//
+//	cfg.Load(&conf, cfg.WithSources(src), cfg.Retry(5, 100*time.Millisecond))
+//
+// Retries stop once the context of the load is done, e.g. when LoadTimeout
+// elapses.
+func Retry(attempts int, backoff time.Duration) Option {
+	return func(f *cfg) {
+		f.retryAttempts = attempts
+		f.retryBackoff = backoff
+	}
+}
+
+// WithHTTPClient returns an option that configures cfg to fetch config
+// files from object URLs with client, e.g. to reach mTLS-protected
+// endpoints. client is also used by the sources of this package whose own
+// Client is nil.
+//
+// client is only used with the endpoints the caller configured: object
+// stores set by `AWS_ENDPOINT_URL_S3`, `AWS_ENDPOINT_URL` or
+// `STORAGE_EMULATOR_HOST`, the endpoints of sources and the URLs of
+// archives. Requests to the default endpoints of cloud providers and to
+// metadata servers, which hand out credentials, use http.DefaultClient,
+// without proxy for metadata servers, so that the credentials client adds
+// are not sent to them.
+//
+//	client, err := bootstrap.Upstream.Build() // a cfg.HTTPClient with TLS
+//	os.Setenv("AWS_ENDPOINT_URL_S3", "https://minio.internal:9000")
+//	cfg.Load(&conf, cfg.File("s3://my-bucket/config.yaml"), cfg.WithHTTPClient(client))
+func WithHTTPClient(client *http.Client) Option {
+	return func(f *cfg) {
+		f.httpClient = client
+	}
+}
+
+// WithPolicy returns an option that configures cfg to enforce a policy, in
+// addition to those registered with RegisterPolicy. A policy given with the
+// name of a registered one replaces it.
+//
+//	cfg.Load(&conf, cfg.WithPolicy("tls-required", requireTLS))
+//
+// Policies check the raw values of the config files and sources, merged in
+// the order they are loaded, before env vars, flags and defaults apply.
+// Load returns an error wrapping ErrPolicyViolation if any policy fails.
+func WithPolicy(name string, p Policy) Option {
+	return func(f *cfg) {
+		if f.policies == nil {
+			f.policies = make(map[string]Policy)
+		}
+		f.policies[name] = p
+	}
+}
@@ -0,0 +1,71 @@
+package cfg
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ParseSet parses `key=value` pairs, e.g. from repeated `--set` flags of a
+// CLI, into a map of overrides to be passed to Override. Values are kept as
+// strings and are converted to the type of their field when the config is
+// loaded, the same way env vars are: `tags=[a,b]` sets a slice and
+// `labels={team:core}` a map. Later pairs take precedence over earlier ones.
+//
+//	overrides, err := cfg.ParseSet([]string{"server.port=9090", "tags=[a,b]"})
+//	if err != nil {
+//	  return err
+//	}
+//	err = cfg.Load(&conf, cfg.Override(overrides))
+func ParseSet(pairs []string) (map[string]interface{}, error) {
+	vals := make(map[string]interface{}, len(pairs))
+	for _, pair := range pairs {
+		key, val, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid pair %q, expected key=value", pair)
+		}
+		vals[key] = val
+	}
+	return vals, nil
+}
+
+// setOverride sets fv to the override val. Strings are parsed like env
+// vars, or like defaults for maps and other composite types. Other values
+// are decoded like the values of a config file. sep separates the
+// elements of slices.
+func (f *cfg) setOverride(fv reflect.Value, val interface{}, sep string) error {
+	if s, ok := val.(string); ok {
+		if isComposite(fv.Type()) {
+			return f.setComposite(fv, s)
+		}
+		return f.setValue(fv, s, sep)
+	}
+	return f.decodeValue(val, fv.Addr().Interface(), nil)
+}
+
+// warnUnmatchedOverrides raises a warning for each override that does not
+// match the path of any of fields, e.g. because of a typo.
+func (f *cfg) warnUnmatchedOverrides(fields []*field) {
+	if len(f.overrides) == 0 {
+		return
+	}
+
+	paths := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		paths[field.path()] = true
+	}
+
+	var unmatched []string
+	for path := range f.overrides {
+		if !paths[path] {
+			unmatched = append(unmatched, path)
+		}
+	}
+	sort.Strings(unmatched)
+
+	for _, path := range unmatched {
+		f.warnf("override %s does not match any field", path)
+	}
+}
@@ -0,0 +1,115 @@
+package cfg
+
+import (
+	"flag"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_cfg_Load_Override(t *testing.T) {
+	type Server struct {
+		Host string `cfg:"host"`
+		Port int    `cfg:"port"`
+	}
+	type Config struct {
+		Server  Server            `cfg:"server"`
+		Servers []Server          `cfg:"servers"`
+		Timeout time.Duration     `cfg:"timeout" default:"1s"`
+		Labels  map[string]string `cfg:"labels"`
+		Debug   bool              `cfg:"debug"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "server:\n  host: file\n  port: 80\nservers:\n  - host: a\n")
+	setenv(t, "SERVER_PORT", "8080")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("server.host", "", "")
+	if err := fs.Parse([]string{"-server.host=flag"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg Config
+	res, err := LoadResult(&cfg, Dirs(dir), UseEnv(""), Flags(fs),
+		Override(map[string]interface{}{
+			"server.host":     "override",
+			"server.port":     8443,
+			"servers[0].port": "9000",
+		}),
+		Override(map[string]interface{}{
+			"timeout": "5s",
+			"labels":  map[string]interface{}{"team": "core"},
+			"debug":   true,
+			"sever":   "typo",
+		}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := Config{
+		Server:  Server{Host: "override", Port: 8443},
+		Servers: []Server{{Host: "a", Port: 9000}},
+		Timeout: 5 * time.Second,
+		Labels:  map[string]string{"team": "core"},
+		Debug:   true,
+	}
+	if !reflect.DeepEqual(want, cfg) {
+		t.Errorf("\nwant %+v\ngot  %+v", want, cfg)
+	}
+
+	if got := res.Provenance["server.port"]; got != "override" {
+		t.Errorf("want provenance override, got %q", got)
+	}
+	if want := []string{"override sever does not match any field"}; !reflect.DeepEqual(want, res.Warnings) {
+		t.Errorf("want warnings %v, got %v", want, res.Warnings)
+	}
+
+	t.Run("invalid value", func(t *testing.T) {
+		var cfg Config
+		err := Load(&cfg, Dirs(dir), Override(map[string]interface{}{"server.port": "http"}))
+		if err == nil {
+			t.Fatal("expected err")
+		}
+	})
+}
+
+func Test_ParseSet(t *testing.T) {
+	vals, err := ParseSet([]string{"server.port=9090", "tags=[a,b]", "labels={team:core}", "dsn=a=b", "server.port=9091"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	want := map[string]interface{}{
+		"server.port": "9091",
+		"tags":        "[a,b]",
+		"labels":      "{team:core}",
+		"dsn":         "a=b",
+	}
+	if !reflect.DeepEqual(want, vals) {
+		t.Errorf("\nwant %+v\ngot  %+v", want, vals)
+	}
+
+	var cfg struct {
+		Server struct {
+			Port int `cfg:"port"`
+		} `cfg:"server"`
+		Tags   []string          `cfg:"tags"`
+		Labels map[string]string `cfg:"labels"`
+		DSN    string            `cfg:"dsn"`
+	}
+	if err := Load(&cfg, IgnoreFile(), UseEnv("parseset"), Override(vals)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if cfg.Server.Port != 9091 || !reflect.DeepEqual(cfg.Tags, []string{"a", "b"}) ||
+		cfg.Labels["team"] != "core" || cfg.DSN != "a=b" {
+		t.Errorf("unexpected cfg %+v", cfg)
+	}
+
+	for _, pair := range []string{"server.port", "=1"} {
+		if _, err := ParseSet([]string{pair}); err == nil {
+			t.Errorf("%q: expected err", pair)
+		}
+	}
+}
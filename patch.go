@@ -0,0 +1,293 @@
+package cfg
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Operations of a patch.
+const (
+	OpAdd     = "add"     // adds a map entry or inserts a slice element, or sets a field.
+	OpRemove  = "remove"  // removes a map entry or a slice element, or resets a field.
+	OpReplace = "replace" // replaces an existing value.
+	OpTest    = "test"    // checks that a value is equal to the given one.
+)
+
+// Op is an operation of a patch, modeled after those of JSON Patch
+// (RFC 6902) but addressing values by field path.
+type Op struct {
+	Op    string      // one of OpAdd, OpRemove, OpReplace or OpTest.
+	Path  string      // path of the value, e.g. `server.port`, `servers[1]` or `labels.team`.
+	Value interface{} // value of add, replace and test operations.
+}
+
+// ApplyPatch applies the operations of patch, in order, to cfg, a pointer to
+// a loaded config struct, e.g. so that admin APIs can edit a running config,
+// and returns the fields that changed. options must be those cfg was loaded
+// with.
+//
+//	changes, err := cfg.ApplyPatch(&conf, []cfg.Op{
+//	  {Op: cfg.OpTest, Path: "server.port", Value: 8080},
+//	  {Op: cfg.OpReplace, Path: "server.port", Value: 9090},
+//	  {Op: cfg.OpAdd, Path: "servers[2]", Value: map[string]interface{}{"host": "c"}},
+//	})
+//
+// Values are converted to the types of their fields as those of config
+// files are. Removed fields are reset to their defaults. Once patched, the
+// config is processed and validated again, without env vars, flags and
+// overrides, and the changes are applied as they are by Reload, except
+// that they are not deferred by its schedule. If an operation fails, a
+// test does not match (the error wraps ErrTestFailed) or the patched config
+// is invalid, cfg is left untouched. cfg may also be a *Live, whose readers
+// see the patched values once they are committed.
+func ApplyPatch(cfg interface{}, patch []Op, options ...Option) ([]Change, error) {
+	cfg, options, unlock := lockLive(cfg, options)
+	defer unlock()
+	return applyPatch(cfg, patch, options...)
+}
+
+// applyPatch is ApplyPatch for a pointer to a config struct, leaving the
+// locking of a Live to the caller.
+func applyPatch(cfg interface{}, patch []Op, options ...Option) ([]Change, error) {
+	conf := defaultCfg()
+	for _, opt := range options {
+		opt(conf)
+	}
+	if !isStructPtr(cfg) {
+		return nil, fmt.Errorf("cfg must be a pointer to a struct")
+	}
+	conf.useEnv = false
+	conf.flags = nil
+	conf.overrides = nil
+	frozen := conf.frozen
+	conf.frozen = false
+
+	t := reflect.TypeOf(cfg).Elem()
+	vals, _ := conf.plain(reflect.ValueOf(cfg), true).(map[string]interface{})
+	for i, op := range patch {
+		if op.Op == OpTest {
+			if err := conf.testOp(t, vals, op); err != nil {
+				return nil, &opError{index: i, op: op, err: err}
+			}
+			continue
+		}
+		if err := applyOp(vals, op); err != nil {
+			return nil, &opError{index: i, op: op, err: err}
+		}
+	}
+
+	fresh := reflect.New(t)
+	if err := conf.decodeMap(vals, fresh.Interface()); err != nil {
+		return nil, err
+	}
+	if err := conf.processCfg(fresh.Interface()); err != nil {
+		return nil, err
+	}
+
+	changes, err := conf.diff(cfg, fresh.Interface())
+	if err != nil {
+		return nil, err
+	}
+	if err := conf.checkChanges(cfg, fresh.Interface(), changes); err != nil {
+		return changes, err
+	}
+	conf.commit(cfg, fresh, frozen, nil)
+	return changes, nil
+}
+
+// opError is the error of an operation of a patch.
+type opError struct {
+	index int // index of the operation in the patch.
+	op    Op
+	err   error
+}
+
+func (e *opError) Error() string {
+	return fmt.Sprintf("patch op %d (%s %s): %v", e.index, e.op.Op, e.op.Path, e.err)
+}
+
+func (e *opError) Unwrap() error {
+	return e.err
+}
+
+// testOp returns ErrTestFailed if the value at the path of op in vals, the
+// raw values of a config of type t, is not equal to the value of op once
+// both are converted to the type of their field. The error leaves the
+// value out as it may be a secret.
+func (f *cfg) testOp(t reflect.Type, vals map[string]interface{}, op Op) error {
+	cur, err := lookupPatchPath(vals, op.Path)
+	if err != nil {
+		return err
+	}
+
+	equal := reflect.DeepEqual(cur, op.Value)
+	cfg := reflect.New(t).Interface()
+	if err := f.decodeMap(deepCopyMap(vals), cfg); err == nil {
+		if field := lookupField(cfg, op.Path, f.tag); field != nil {
+			want := reflect.New(field.v.Type())
+			if err := f.decodeValue(op.Value, want.Interface(), nil); err != nil {
+				return err
+			}
+			equal = reflect.DeepEqual(field.v.Interface(), want.Elem().Interface())
+		}
+	}
+	if !equal {
+		return ErrTestFailed
+	}
+	return nil
+}
+
+// patchKey is an element of a patch path: the key of a map or the index of
+// a slice.
+type patchKey struct {
+	key   string
+	index int // index of the slice element if key is empty.
+}
+
+// parsePatchPath splits path into its keys and indices, e.g. `a.b[1].c`
+// into a, b, 1 and c.
+func parsePatchPath(path string) ([]patchKey, error) {
+	var keys []patchKey
+	for _, part := range strings.Split(path, ".") {
+		name, rest, _ := strings.Cut(part, "[")
+		if name == "" && (len(keys) == 0 || rest == "") {
+			return nil, fmt.Errorf("invalid path %q", path)
+		}
+		if name != "" {
+			keys = append(keys, patchKey{key: name})
+		}
+		for rest != "" {
+			idx, next, ok := strings.Cut(rest, "]")
+			i, err := strconv.Atoi(idx)
+			if !ok || err != nil || i < 0 {
+				return nil, fmt.Errorf("invalid index in path %q", path)
+			}
+			keys = append(keys, patchKey{index: i})
+			rest = strings.TrimPrefix(next, "[")
+		}
+	}
+	return keys, nil
+}
+
+// lookupPatchPath returns the value at path in vals.
+func lookupPatchPath(vals map[string]interface{}, path string) (interface{}, error) {
+	keys, err := parsePatchPath(path)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{} = vals
+	for _, k := range keys {
+		child, ok := patchChild(v, k)
+		if !ok {
+			return nil, fmt.Errorf("no value at path")
+		}
+		v = child
+	}
+	return v, nil
+}
+
+// applyOp applies the add, remove or replace operation op to vals.
+func applyOp(vals map[string]interface{}, op Op) error {
+	keys, err := parsePatchPath(op.Path)
+	if err != nil {
+		return err
+	}
+	_, err = patchValue(vals, keys, op)
+	return err
+}
+
+// patchValue applies op at the path keys within v and returns the patched
+// value of v.
+func patchValue(v interface{}, keys []patchKey, op Op) (interface{}, error) {
+	k := keys[0]
+	if len(keys) > 1 {
+		child, ok := patchChild(v, k)
+		if !ok {
+			return nil, fmt.Errorf("no value at path")
+		}
+		patched, err := patchValue(child, keys[1:], op)
+		if err != nil {
+			return nil, err
+		}
+		return setPatchChild(v, k, patched)
+	}
+
+	_, exists := patchChild(v, k)
+	switch op.Op {
+	case OpAdd:
+		if k.key == "" {
+			return insertPatchElem(v, k.index, op.Value)
+		}
+		return setPatchChild(v, k, op.Value)
+	case OpReplace:
+		if !exists {
+			return nil, fmt.Errorf("no value at path")
+		}
+		return setPatchChild(v, k, op.Value)
+	case OpRemove:
+		if !exists {
+			return nil, fmt.Errorf("no value at path")
+		}
+		if k.key != "" {
+			delete(v.(map[string]interface{}), k.key)
+			return v, nil
+		}
+		s := v.([]interface{})
+		return append(s[:k.index:k.index], s[k.index+1:]...), nil
+	default:
+		return nil, fmt.Errorf("unsupported operation %q", op.Op)
+	}
+}
+
+// patchChild returns the value of v at k, if any.
+func patchChild(v interface{}, k patchKey) (interface{}, bool) {
+	if k.key != "" {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		child, ok := m[k.key]
+		return child, ok
+	}
+	s, ok := v.([]interface{})
+	if !ok || k.index >= len(s) {
+		return nil, false
+	}
+	return s[k.index], true
+}
+
+// setPatchChild sets the value of v at k to child and returns v.
+func setPatchChild(v interface{}, k patchKey, child interface{}) (interface{}, error) {
+	if k.key != "" {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: value is not a map or struct", k.key)
+		}
+		m[k.key] = child
+		return m, nil
+	}
+	s, ok := v.([]interface{})
+	if !ok || k.index >= len(s) {
+		return nil, fmt.Errorf("index %d out of range", k.index)
+	}
+	s[k.index] = child
+	return s, nil
+}
+
+// insertPatchElem inserts elem into the slice v before the index i, or
+// appends it if i is the length of v. A nil v is an empty slice.
+func insertPatchElem(v interface{}, i int, elem interface{}) (interface{}, error) {
+	s, ok := v.([]interface{})
+	if !ok && v != nil {
+		return nil, fmt.Errorf("value is not a slice")
+	}
+	if i > len(s) {
+		return nil, fmt.Errorf("index %d out of range", i)
+	}
+	s = append(s, nil)
+	copy(s[i+1:], s[i:])
+	s[i] = elem
+	return s, nil
+}
@@ -0,0 +1,128 @@
+package cfg
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_ApplyPatch(t *testing.T) {
+	type Server struct {
+		Host string `cfg:"host" validate:"required"`
+		Port int    `cfg:"port" default:"80"`
+	}
+	type Config struct {
+		Addr     string            `cfg:"addr" reload:"restart-required"`
+		Timeout  time.Duration     `cfg:"timeout" default:"5s"`
+		Servers  []Server          `cfg:"servers"`
+		Labels   map[string]string `cfg:"labels"`
+		Password string            `cfg:"password" secret:"true"`
+	}
+
+	load := func() Config {
+		return Config{
+			Addr:     ":8080",
+			Timeout:  time.Minute,
+			Servers:  []Server{{Host: "a", Port: 1}, {Host: "b", Port: 2}},
+			Labels:   map[string]string{"team": "core"},
+			Password: "hunter2",
+		}
+	}
+
+	t.Run("ops", func(t *testing.T) {
+		cfg := load()
+		changes, err := ApplyPatch(&cfg, []Op{
+			{Op: OpTest, Path: "timeout", Value: "1m"},
+			{Op: OpReplace, Path: "timeout", Value: "2m"},
+			{Op: OpAdd, Path: "servers[1]", Value: map[string]interface{}{"host": "c"}},
+			{Op: OpRemove, Path: "servers[0]"},
+			{Op: OpReplace, Path: "servers[1].port", Value: "9090"},
+			{Op: OpAdd, Path: "labels.tier", Value: "1"},
+			{Op: OpRemove, Path: "labels.team"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		want := Config{
+			Addr:     ":8080",
+			Timeout:  2 * time.Minute,
+			Servers:  []Server{{Host: "c", Port: 80}, {Host: "b", Port: 9090}},
+			Labels:   map[string]string{"tier": "1"},
+			Password: "hunter2",
+		}
+		if !reflect.DeepEqual(want, cfg) {
+			t.Errorf("\nwant %+v\ngot  %+v", want, cfg)
+		}
+		var paths []string
+		for _, c := range changes {
+			paths = append(paths, c.Path)
+		}
+		if want := []string{"labels", "servers[0].host", "servers[0].port", "servers[1].port", "timeout"}; !reflect.DeepEqual(want, paths) {
+			t.Errorf("want changes %v, got %v", want, paths)
+		}
+	})
+
+	t.Run("test mismatch names the path only", func(t *testing.T) {
+		cfg := load()
+		_, err := ApplyPatch(&cfg, []Op{{Op: OpTest, Path: "servers[0]", Value: map[string]interface{}{"host": "x"}}})
+		if want := "patch op 0 (test servers[0]): " + ErrTestFailed.Error(); err == nil || err.Error() != want {
+			t.Errorf("want err %q, got %v", want, err)
+		}
+	})
+
+	t.Run("removed fields are defaulted", func(t *testing.T) {
+		cfg := load()
+		if _, err := ApplyPatch(&cfg, []Op{{Op: OpRemove, Path: "timeout"}}); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Timeout != 5*time.Second {
+			t.Errorf("want timeout 5s, got %v", cfg.Timeout)
+		}
+	})
+
+	for _, tc := range []struct {
+		name  string
+		patch []Op
+		is    error
+	}{
+		{name: "test mismatch", patch: []Op{{Op: OpTest, Path: "servers[0].port", Value: 2}}, is: ErrTestFailed},
+		{name: "invalid", patch: []Op{{Op: OpReplace, Path: "servers[0].host", Value: ""}}},
+		{name: "missing path", patch: []Op{{Op: OpReplace, Path: "servers[5].host", Value: "x"}}},
+		{name: "bad type", patch: []Op{{Op: OpReplace, Path: "timeout", Value: "soon"}}},
+		{name: "restart required", patch: []Op{{Op: OpReplace, Path: "addr", Value: ":9090"}}, is: ErrRestartRequired},
+		{name: "unsupported op", patch: []Op{{Op: "move", Path: "addr"}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := load()
+			_, err := ApplyPatch(&cfg, append([]Op{{Op: OpReplace, Path: "timeout", Value: "2m"}}, tc.patch...))
+			if err == nil {
+				t.Fatal("expected err")
+			}
+			if tc.is != nil && !errors.Is(err, tc.is) {
+				t.Errorf("want err wrapping %v, got %v", tc.is, err)
+			}
+			if !reflect.DeepEqual(load(), cfg) {
+				t.Errorf("want cfg untouched, got %+v", cfg)
+			}
+		})
+	}
+}
+
+func Test_parsePatchPath(t *testing.T) {
+	got, err := parsePatchPath("a.b[1][2].c")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	want := []patchKey{{key: "a"}, {key: "b"}, {index: 1}, {index: 2}, {key: "c"}}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %+v, got %+v", want, got)
+	}
+
+	for _, path := range []string{"", "a..b", "a[x]", "a[1", "[0]", "a[-1]"} {
+		if _, err := parsePatchPath(path); err == nil {
+			t.Errorf("%q: expected err", path)
+		}
+	}
+}
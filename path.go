@@ -0,0 +1,64 @@
+package cfg
+
+import (
+	"errors"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// resolvePath canonicalizes the path p. A leading `~` or `~user` is
+// expanded to the home directory of the current user or of user, and
+// relative paths are resolved against the directory of the loaded config
+// file (if any, and unless it's an object in a bucket). The result is
+// cleaned.
+// An empty path is returned as is.
+func (f *cfg) resolvePath(p string) (string, error) {
+	if p == "" {
+		return p, nil
+	}
+
+	p, err := expandHome(p)
+	if err != nil {
+		return "", err
+	}
+
+	if !filepath.IsAbs(p) && f.fileDir != "" && !isObjectURL(f.fileDir) {
+		p = filepath.Join(f.fileDir, p)
+	}
+
+	return filepath.Clean(p), nil
+}
+
+// expandHome replaces a leading `~` in p with the current user's home
+// directory and a leading `~user` with the home directory of user. p is
+// returned as is if user doesn't exist, as it is then likely a file name
+// that starts with `~`.
+func expandHome(p string) (string, error) {
+	if !strings.HasPrefix(p, "~") {
+		return p, nil
+	}
+	name, rest := p[1:], ""
+	if i := strings.IndexAny(name, "/"+string(filepath.Separator)); i >= 0 {
+		name, rest = name[:i], name[i:]
+	}
+
+	if name == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, rest), nil
+	}
+
+	u, err := user.Lookup(name)
+	if err != nil {
+		var unknown user.UnknownUserError
+		if errors.As(err, &unknown) {
+			return p, nil
+		}
+		return "", err
+	}
+	return filepath.Join(u.HomeDir, rest), nil
+}
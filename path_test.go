@@ -0,0 +1,118 @@
+package cfg
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_cfg_Load_Path(t *testing.T) {
+	type Config struct {
+		Data    string   `cfg:"data" path:"true"`
+		Cache   string   `cfg:"cache" path:"true" default:"cache/../tmp"`
+		Plugins []string `cfg:"plugins" path:"true"`
+		Log     *string  `cfg:"log" path:"true"`
+		Raw     string   `cfg:"raw"`
+	}
+
+	home := t.TempDir()
+	setenv(t, "HOME", home)
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), `
+data: ./data/
+plugins: [/opt/plugins, ~/plugins]
+log: ~
+raw: ./raw
+`)
+
+	var cfg Config
+	if err := Load(&cfg, Dirs(dir)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := Config{
+		Data:    filepath.Join(dir, "data"),
+		Cache:   filepath.Join(dir, "tmp"),
+		Plugins: []string{"/opt/plugins", filepath.Join(home, "plugins")},
+		Raw:     "./raw",
+	}
+	if !reflect.DeepEqual(want, cfg) {
+		t.Errorf("\nwant %+v\ngot %+v", want, cfg)
+	}
+}
+
+func Test_cfg_resolvePath(t *testing.T) {
+	home := t.TempDir()
+	setenv(t, "HOME", home)
+
+	current, err := user.Current()
+	if err != nil {
+		t.Skipf("unable to look up current user: %v", err)
+	}
+
+	for _, tc := range []struct {
+		Name    string
+		FileDir string
+		In      string
+		Want    string
+	}{
+		{Name: "empty", FileDir: "/etc/app", In: "", Want: ""},
+		{Name: "absolute", FileDir: "/etc/app", In: "/var//lib/", Want: "/var/lib"},
+		{Name: "relative to file", FileDir: "/etc/app", In: "certs/../tls.pem", Want: "/etc/app/tls.pem"},
+		{Name: "relative without file", FileDir: "", In: "./data/", Want: "data"},
+		{Name: "home", FileDir: "/etc/app", In: "~", Want: home},
+		{Name: "home subdir", FileDir: "/etc/app", In: "~/app", Want: filepath.Join(home, "app")},
+		{Name: "tilde in name", FileDir: "/etc/app", In: "~app", Want: "/etc/app/~app"},
+		{Name: "user", FileDir: "/etc/app", In: "~" + current.Username + "/app", Want: filepath.Join(current.HomeDir, "app")},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			conf := defaultCfg()
+			conf.fileDir = tc.FileDir
+
+			got, err := conf.resolvePath(tc.In)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if got != tc.Want {
+				t.Errorf("want %q, got %q", tc.Want, got)
+			}
+		})
+	}
+
+	t.Run("no home dir", func(t *testing.T) {
+		os.Clearenv()
+
+		_, err := expandHome("~/app")
+		if err == nil {
+			t.Fatal("expected err")
+		}
+	})
+}
+
+func Test_cfg_Load_DirsHome(t *testing.T) {
+	type Config struct {
+		Host string `cfg:"host"`
+	}
+
+	home := t.TempDir()
+	setenv(t, "HOME", home)
+	if err := os.Mkdir(filepath.Join(home, ".myapp"), 0o700); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	writeFile(t, filepath.Join(home, ".myapp", "config.yaml"), "host: localhost\n")
+
+	var cfg Config
+	res, err := LoadResult(&cfg, Dirs("~/.myapp"))
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if cfg.Host != "localhost" {
+		t.Errorf("want host localhost, got %q", cfg.Host)
+	}
+	if want := filepath.Join(home, ".myapp"); res.Dir != want {
+		t.Errorf("want dir %s, got %s", want, res.Dir)
+	}
+}
@@ -0,0 +1,86 @@
+package cfg
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// Percent is a fraction between 0 and 1 inclusive, such as a threshold or a
+// sampling rate. It can be written either as a percentage (`85%`) or as a
+// fraction (`0.85`), both of which result in the value 0.85.
+type Percent float64
+
+// UnmarshalText parses a percentage or a fraction and checks that it is
+// within range.
+func (p *Percent) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+
+	var v float64
+	var err error
+	if num, ok := strings.CutSuffix(s, "%"); ok {
+		v, err = strconv.ParseFloat(strings.TrimSpace(num), 64)
+		v /= 100
+	} else {
+		v, err = strconv.ParseFloat(s, 64)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid percent %q", text)
+	}
+
+	return p.set(v)
+}
+
+// MarshalText formats the value as a percentage.
+func (p Percent) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// String formats the value as a percentage, e.g. `85%`.
+func (p Percent) String() string {
+	// round away the noise introduced by scaling binary fractions.
+	v := math.Round(float64(p)*100*1e9) / 1e9
+	return strconv.FormatFloat(v, 'f', -1, 64) + "%"
+}
+
+// set sets p to v if it is within range.
+func (p *Percent) set(v float64) error {
+	if math.IsNaN(v) || v < 0 || v > 1 {
+		return fmt.Errorf("percent %v out of range [0%%, 100%%]", Percent(v))
+	}
+	*p = Percent(v)
+	return nil
+}
+
+// numberToPercentHookFunc returns a DecodeHookFunc that range checks numbers
+// decoded into a Percent. strings are handled by Percent.UnmarshalText.
+func numberToPercentHookFunc() mapstructure.DecodeHookFunc {
+	return func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{}) (interface{}, error) {
+		if t != reflect.TypeOf(Percent(0)) {
+			return data, nil
+		}
+		var v float64
+		switch f.Kind() {
+		case reflect.Float32, reflect.Float64:
+			v = reflect.ValueOf(data).Float()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			v = float64(reflect.ValueOf(data).Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			v = float64(reflect.ValueOf(data).Uint())
+		default:
+			return data, nil
+		}
+		var p Percent
+		if err := p.set(v); err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+}
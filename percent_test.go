@@ -0,0 +1,94 @@
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_cfg_Load_Percent(t *testing.T) {
+	type Config struct {
+		Threshold Percent   `cfg:"threshold"`
+		Sampling  Percent   `cfg:"sampling"`
+		Ratio     *Percent  `cfg:"ratio"`
+		Fallback  Percent   `cfg:"fallback" default:"5%"`
+		Steps     []Percent `cfg:"steps"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), `
+threshold: 85%
+sampling: 0.25
+steps: [0, 1, "50%"]
+`)
+
+	os.Clearenv()
+	setenv(t, "APP_RATIO", "10%")
+
+	var cfg Config
+	if err := Load(&cfg, Dirs(dir), UseEnv("app")); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	ratio := Percent(0.1)
+	want := Config{
+		Threshold: 0.85,
+		Sampling:  0.25,
+		Ratio:     &ratio,
+		Fallback:  0.05,
+		Steps:     []Percent{0, 1, 0.5},
+	}
+	if !reflect.DeepEqual(want, cfg) {
+		t.Errorf("\nwant %+v\ngot %+v", want, cfg)
+	}
+
+	for _, in := range []string{"threshold: 150%", "sampling: 1.5", "sampling: -1", "steps: [2]"} {
+		t.Run(in, func(t *testing.T) {
+			writeFile(t, filepath.Join(dir, "config.yaml"), in)
+
+			var cfg Config
+			err := Load(&cfg, Dirs(dir))
+			if err == nil || !strings.Contains(err.Error(), "out of range") {
+				t.Fatalf("want out of range err, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPercent(t *testing.T) {
+	for _, tc := range []struct {
+		In     string
+		Want   Percent
+		String string
+	}{
+		{In: "85%", Want: 0.85, String: "85%"},
+		{In: " 12.5 % ", Want: 0.125, String: "12.5%"},
+		{In: "0.3", Want: 0.3, String: "30%"},
+		{In: "1", Want: 1, String: "100%"},
+		{In: "0%", Want: 0, String: "0%"},
+	} {
+		t.Run(tc.In, func(t *testing.T) {
+			var p Percent
+			if err := p.UnmarshalText([]byte(tc.In)); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if p != tc.Want {
+				t.Errorf("want %v, got %v", float64(tc.Want), float64(p))
+			}
+			if p.String() != tc.String {
+				t.Errorf("want %s, got %s", tc.String, p.String())
+			}
+		})
+	}
+
+	for _, in := range []string{"", "%", "abc", "101%", "-0.1", "NaN", "NaN%"} {
+		t.Run("invalid "+in, func(t *testing.T) {
+			var p Percent
+			if err := p.UnmarshalText([]byte(in)); err == nil {
+				t.Fatal("expected err")
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// windowsDirs returns the config dirs of app on Windows in order of
+// increasing precedence: `%PROGRAMDATA%\app`, shared by all users, then
+// `%APPDATA%\app`, the roaming dir of the user. Dirs whose variable is
+// unset are left out.
+func windowsDirs(app string) []string {
+	var dirs []string
+	for _, env := range []string{"PROGRAMDATA", "APPDATA"} {
+		if dir := os.Getenv(env); dir != "" {
+			dirs = append(dirs, filepath.Join(dir, app))
+		}
+	}
+	return dirs
+}
+
+// platformDirs returns the idiomatic config dirs of app on the platform
+// goos: those of windowsDirs on Windows and of xdgDirs elsewhere.
+func platformDirs(goos, app string) []string {
+	if goos == "windows" {
+		return windowsDirs(app)
+	}
+	return xdgDirs(app)
+}
+
+// searchDirs returns the dirs that are searched for config files: the
+// dirs of the UseXDG and PlatformDirs options, if any, followed by the
+// configured dirs.
+func (f *cfg) searchDirs() []string {
+	var dirs []string
+	if f.xdgApp != "" {
+		dirs = append(dirs, xdgDirs(f.xdgApp)...)
+	}
+	if f.platformApp != "" {
+		dirs = append(dirs, platformDirs(runtime.GOOS, f.platformApp)...)
+	}
+	return append(dirs, f.dirs...)
+}
@@ -0,0 +1,73 @@
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func Test_platformDirs(t *testing.T) {
+	setenv(t, "XDG_CONFIG_HOME", "/home/user/.config")
+	setenv(t, "XDG_CONFIG_DIRS", "/etc/xdg")
+	setenv(t, "APPDATA", filepath.Join("C:", "Users", "user", "AppData", "Roaming"))
+	setenv(t, "PROGRAMDATA", filepath.Join("C:", "ProgramData"))
+
+	t.Run("windows", func(t *testing.T) {
+		want := []string{
+			filepath.Join("C:", "ProgramData", "myapp"),
+			filepath.Join("C:", "Users", "user", "AppData", "Roaming", "myapp"),
+		}
+		if got := platformDirs("windows", "myapp"); !reflect.DeepEqual(want, got) {
+			t.Errorf("want %v, got %v", want, got)
+		}
+	})
+
+	t.Run("windows without env", func(t *testing.T) {
+		setenv(t, "PROGRAMDATA", "")
+		want := []string{filepath.Join("C:", "Users", "user", "AppData", "Roaming", "myapp")}
+		if got := platformDirs("windows", "myapp"); !reflect.DeepEqual(want, got) {
+			t.Errorf("want %v, got %v", want, got)
+		}
+	})
+
+	t.Run("linux", func(t *testing.T) {
+		want := []string{"/etc/xdg/myapp", "/home/user/.config/myapp"}
+		if got := platformDirs("linux", "myapp"); !reflect.DeepEqual(want, got) {
+			t.Errorf("want %v, got %v", want, got)
+		}
+	})
+}
+
+func Test_cfg_Load_PlatformDirs(t *testing.T) {
+	type Config struct {
+		Host string `cfg:"host"`
+		Port int    `cfg:"port"`
+	}
+
+	system, user := t.TempDir(), t.TempDir()
+	if runtime.GOOS == "windows" {
+		setenv(t, "PROGRAMDATA", system)
+		setenv(t, "APPDATA", user)
+	} else {
+		setenv(t, "XDG_CONFIG_DIRS", system)
+		setenv(t, "XDG_CONFIG_HOME", user)
+	}
+	for _, dir := range []string{system, user} {
+		if err := os.Mkdir(filepath.Join(dir, "myapp"), 0o700); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+	writeFile(t, filepath.Join(system, "myapp", "config.yaml"), "host: system\nport: 80\n")
+	writeFile(t, filepath.Join(user, "myapp", "config.yaml"), "port: 8080\n")
+
+	var cfg Config
+	if err := Load(&cfg, PlatformDirs("myapp")); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	want := Config{Host: "system", Port: 8080}
+	if cfg != want {
+		t.Errorf("want %+v, got %+v", want, cfg)
+	}
+}
@@ -0,0 +1,92 @@
+package cfg
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Policy checks the raw values of the config files and sources, merged in
+// the order they are loaded, e.g. to enforce organization-wide rules such
+// as requiring TLS. vals must not be modified.
+type Policy func(vals map[string]interface{}) error
+
+var (
+	policiesMu sync.RWMutex
+	policies   = map[string]Policy{}
+)
+
+// RegisterPolicy registers a policy that every subsequent Load enforces,
+// typically at init time of a package shared by the services of an
+// organization. Registering a name that is already registered replaces the
+// previous policy.
+//
+//	func init() {
+//	  cfg.RegisterPolicy("no-wildcard-bind", func(vals map[string]interface{}) error {
+//	    if addr, _ := vals["addr"].(string); strings.HasPrefix(addr, "0.0.0.0:") {
+//	      return errors.New("addr must not bind to all interfaces")
+//	    }
+//	    return nil
+//	  })
+//	}
+func RegisterPolicy(name string, p Policy) {
+	policiesMu.Lock()
+	defer policiesMu.Unlock()
+	policies[name] = p
+}
+
+// recordPolicyVals merges vals over the values checked by the policies, if
+// there are any, and by lint.
+func (f *cfg) recordPolicyVals(vals map[string]interface{}) {
+	if len(f.policies) == 0 && !hasRegisteredPolicies() && !f.lint {
+		return
+	}
+	if f.policyVals == nil {
+		f.policyVals = make(map[string]interface{})
+	}
+	mergeMaps(f.policyVals, deepCopyMap(vals))
+}
+
+// checkPolicies runs the registered policies and those of the WithPolicy
+// option, in order of name, and returns an error wrapping
+// ErrPolicyViolation that joins the errors of the failed policies.
+func (f *cfg) checkPolicies() error {
+	all := make(map[string]Policy)
+	policiesMu.RLock()
+	for name, p := range policies {
+		all[name] = p
+	}
+	policiesMu.RUnlock()
+	for name, p := range f.policies {
+		all[name] = p
+	}
+
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	vals := f.policyVals
+	if vals == nil {
+		vals = make(map[string]interface{})
+	}
+
+	var errs []error
+	for _, name := range names {
+		if err := all[name](vals); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrPolicyViolation, errors.Join(errs...))
+	}
+	return nil
+}
+
+func hasRegisteredPolicies() bool {
+	policiesMu.RLock()
+	defer policiesMu.RUnlock()
+	return len(policies) > 0
+}
@@ -0,0 +1,78 @@
+package cfg
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_cfg_Load_Policy(t *testing.T) {
+	type Config struct {
+		Addr string `cfg:"addr" default:"0.0.0.0:80"`
+		TLS  struct {
+			Enabled bool `cfg:"enabled"`
+		} `cfg:"tls"`
+	}
+
+	noWildcard := func(vals map[string]interface{}) error {
+		if addr, _ := vals["addr"].(string); strings.HasPrefix(addr, "0.0.0.0:") {
+			return errors.New("addr must not bind to all interfaces")
+		}
+		return nil
+	}
+	requireTLS := func(vals map[string]interface{}) error {
+		tls, _ := vals["tls"].(map[string]interface{})
+		if enabled, _ := tls["enabled"].(bool); !enabled {
+			return errors.New("tls must be enabled")
+		}
+		return nil
+	}
+
+	RegisterPolicy("no-wildcard-bind", noWildcard)
+	t.Cleanup(func() {
+		policiesMu.Lock()
+		delete(policies, "no-wildcard-bind")
+		policiesMu.Unlock()
+	})
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "addr: 0.0.0.0:443\ntls:\n  enabled: false\n")
+	src := MapSource{"tls": map[string]interface{}{"enabled": true}}
+
+	t.Run("violations", func(t *testing.T) {
+		var cfg Config
+		err := Load(&cfg, Dirs(dir), WithPolicy("tls-required", requireTLS))
+		if !errors.Is(err, ErrPolicyViolation) {
+			t.Fatalf("want ErrPolicyViolation, got %v", err)
+		}
+		want := "config policy violation: no-wildcard-bind: addr must not bind to all interfaces\ntls-required: tls must be enabled"
+		if err.Error() != want {
+			t.Errorf("want err %q, got %q", want, err)
+		}
+	})
+
+	t.Run("merged values", func(t *testing.T) {
+		var cfg Config
+		err := Load(&cfg, Dirs(dir), WithSources(src), WithPolicy("tls-required", requireTLS),
+			WithPolicy("no-wildcard-bind", func(map[string]interface{}) error { return nil }))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Addr != "0.0.0.0:443" || !cfg.TLS.Enabled {
+			t.Errorf("unexpected cfg %+v", cfg)
+		}
+	})
+
+	t.Run("defaults are not checked", func(t *testing.T) {
+		var cfg Config
+		err := Load(&cfg, IgnoreFile(), WithSources(src), WithPolicy("tls-required", requireTLS))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Addr != "0.0.0.0:80" {
+			t.Errorf("unexpected cfg %+v", cfg)
+		}
+	})
+
+}
@@ -0,0 +1,40 @@
+package cfg
+
+import "fmt"
+
+// ProfilesKey is the top-level key of a config file that holds the subtrees
+// of each profile.
+const ProfilesKey = "profiles"
+
+// applyProfile merges the subtree of the configured profile in vals over
+// the base values of vals.
+//
+// The profiles subtree is removed from vals so that it is not decoded,
+// whether or not a profile is configured.
+func (f *cfg) applyProfile(vals map[string]interface{}) error {
+	profiles, ok := vals[ProfilesKey]
+	if !ok {
+		return nil
+	}
+	delete(vals, ProfilesKey)
+	if f.profile == "" {
+		return nil
+	}
+
+	m, ok := profiles.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s must be a map of profile names to values", ProfilesKey)
+	}
+	subtree, ok := m[f.profile]
+	if !ok {
+		return nil
+	}
+	overlay, ok := subtree.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s.%s must be a map", ProfilesKey, f.profile)
+	}
+	mergeMaps(vals, overlay)
+	f.profileFound = true
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package cfg
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_cfg_Load_Profile(t *testing.T) {
+	type Config struct {
+		Server struct {
+			Host string `cfg:"host"`
+			Port int    `cfg:"port"`
+		} `cfg:"server"`
+		Name string `cfg:"name"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), `
+name: base
+server:
+  host: 0.0.0.0
+  port: 80
+profiles:
+  dev:
+    server:
+      host: localhost
+  prod:
+    name: prod
+    scopes:
+      tenant-42:
+        server:
+          port: 8042
+`)
+
+	t.Run("profile merged over base", func(t *testing.T) {
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir), Profile("dev"), UseStrict()); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		var want Config
+		want.Name = "base"
+		want.Server.Host = "localhost"
+		want.Server.Port = 80
+		if !reflect.DeepEqual(want, cfg) {
+			t.Errorf("\nwant %+v\ngot  %+v", want, cfg)
+		}
+	})
+
+	t.Run("profile with scopes", func(t *testing.T) {
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir), Profile("prod"), Scope("tenant-42")); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Name != "prod" || cfg.Server.Host != "0.0.0.0" || cfg.Server.Port != 8042 {
+			t.Errorf("unexpected cfg %+v", cfg)
+		}
+	})
+
+	t.Run("unknown profile uses base", func(t *testing.T) {
+		var cfg Config
+		res, err := LoadResult(&cfg, Dirs(dir), Profile("staging"), UseStrict())
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Name != "base" || cfg.Server.Host != "0.0.0.0" {
+			t.Errorf("unexpected cfg %+v", cfg)
+		}
+		if len(res.Warnings) != 1 || !strings.Contains(res.Warnings[0], `profile "staging" not found`) {
+			t.Errorf("unexpected warnings %v", res.Warnings)
+		}
+	})
+
+	t.Run("no profile", func(t *testing.T) {
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir), UseStrict()); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Name != "base" || cfg.Server.Host != "0.0.0.0" {
+			t.Errorf("unexpected cfg %+v", cfg)
+		}
+	})
+
+	t.Run("invalid profiles", func(t *testing.T) {
+		bad := t.TempDir()
+		writeFile(t, filepath.Join(bad, "config.yaml"), "profiles:\n  dev: [a, b]\n")
+
+		var cfg Config
+		if err := Load(&cfg, Dirs(bad), Profile("dev")); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package cfg
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// decodeProperties parses a Java properties file and expands its dotted
+// keys (e.g. `server.port`) into nested maps in vals.
+func decodeProperties(r io.Reader, vals map[string]interface{}) error {
+	props, err := parseProperties(r)
+	if err != nil {
+		return err
+	}
+	for key, val := range props {
+		if err := setPath(vals, strings.Split(key, "."), val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseProperties parses the `key=value`, `key: value` and `key value`
+// lines of a Java properties file. Lines starting with `#` or `!` are
+// comments, lines ending in a backslash are continued on the next line and
+// escape sequences (including `\uXXXX`) are interpreted.
+func parseProperties(r io.Reader) (map[string]string, error) {
+	props := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	var logical string
+	for scanner.Scan() {
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+		if logical == "" && (line == "" || line[0] == '#' || line[0] == '!') {
+			continue
+		}
+		if trailingBackslashes(line)%2 == 1 {
+			logical += line[:len(line)-1]
+			continue
+		}
+		logical += line
+
+		key, val, err := splitProperty(logical)
+		if err != nil {
+			return nil, err
+		}
+		props[key] = val
+		logical = ""
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if logical != "" {
+		return nil, fmt.Errorf("unterminated line continuation")
+	}
+
+	return props, nil
+}
+
+// splitProperty splits a logical line into its unescaped key and value.
+// the key ends at the first unescaped `=`, `:` or whitespace.
+func splitProperty(line string) (string, string, error) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+
+	key, err := unescapeProperty(line[:end])
+	if err != nil {
+		return "", "", err
+	}
+
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	val, err := unescapeProperty(rest)
+	if err != nil {
+		return "", "", err
+	}
+
+	return key, val, nil
+}
+
+// unescapeProperty interprets the escape sequences of a properties key or
+// value. unknown escapes resolve to the escaped character itself.
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			sb.WriteByte('\t')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'u':
+			if i+4 >= len(s) {
+				return "", fmt.Errorf("invalid unicode escape in %q", s)
+			}
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("invalid unicode escape in %q", s)
+			}
+			sb.WriteRune(rune(r))
+			i += 4
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String(), nil
+}
+
+// trailingBackslashes returns the number of backslashes at the end of s.
+func trailingBackslashes(s string) int {
+	return len(s) - len(strings.TrimRight(s, `\`))
+}
@@ -0,0 +1,74 @@
+package cfg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_parseProperties(t *testing.T) {
+	in := `# comment
+! another comment
+a=1
+b : 2
+c 3
+  d.e = spaced value  
+f = multi \
+    line
+g = C:\\path\\to
+h\ key = \u00e9t\u00e9
+i
+`
+	got, err := parseProperties(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := map[string]string{
+		"a":     "1",
+		"b":     "2",
+		"c":     "3",
+		"d.e":   "spaced value  ",
+		"f":     "multi line",
+		"g":     `C:\path\to`,
+		"h key": "été",
+		"i":     "",
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("\nwant %q\ngot %q", want, got)
+	}
+
+	for _, in := range []string{`a=\u12`, `a=\uZZZZ`, "a=b\\"} {
+		t.Run(in, func(t *testing.T) {
+			if _, err := parseProperties(strings.NewReader(in)); err == nil {
+				t.Fatal("expected err")
+			}
+		})
+	}
+}
+
+func Test_decodeProperties(t *testing.T) {
+	vals := make(map[string]interface{})
+	err := decodeProperties(strings.NewReader("server.port=8080\nserver.tls.enabled=true\nname=app\n"), vals)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"name": "app",
+		"server": map[string]interface{}{
+			"port": "8080",
+			"tls":  map[string]interface{}{"enabled": "true"},
+		},
+	}
+	if !reflect.DeepEqual(want, vals) {
+		t.Errorf("\nwant %+v\ngot %+v", want, vals)
+	}
+
+	t.Run("conflicting keys", func(t *testing.T) {
+		err := decodeProperties(strings.NewReader("a=1\na.b=2\n"), make(map[string]interface{}))
+		if err == nil {
+			t.Fatal("expected err")
+		}
+	})
+}
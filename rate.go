@@ -0,0 +1,109 @@
+package cfg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rate is a rate of events with an optional burst, such as a rate limit,
+// written as a number of events per unit of time: `100/s`, `5/m burst=20`
+// or `300/10s`. The units are `ms`, `s`, `m`, `h` and `d`, or any duration.
+//
+// Use it with a limiter such as that of golang.org/x/time/rate:
+//
+//	limiter := rate.NewLimiter(rate.Limit(conf.Limit.PerSecond()), conf.Limit.Burst)
+type Rate struct {
+	Events int           // number of events per period.
+	Per    time.Duration // period of time.
+	Burst  int           // max number of events at once, 0 if unset.
+}
+
+// rateUnits are the units of time that a rate may be written per.
+var rateUnits = map[string]time.Duration{
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  day,
+}
+
+// UnmarshalText parses a rate in the form `events/unit [burst=n]`.
+func (r *Rate) UnmarshalText(text []byte) error {
+	fields := strings.Fields(string(text))
+	if len(fields) == 0 {
+		return fmt.Errorf("invalid rate %q: empty", text)
+	}
+
+	events, unit, ok := strings.Cut(fields[0], "/")
+	if !ok {
+		return fmt.Errorf("invalid rate %q: missing '/'", text)
+	}
+	n, err := strconv.Atoi(events)
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid rate %q: invalid number of events %q", text, events)
+	}
+	per, ok := rateUnits[unit]
+	if !ok {
+		per, err = time.ParseDuration(unit)
+		if err != nil || per <= 0 {
+			return fmt.Errorf("invalid rate %q: invalid unit %q", text, unit)
+		}
+	}
+
+	burst := 0
+	for _, opt := range fields[1:] {
+		val, ok := strings.CutPrefix(opt, "burst=")
+		if !ok {
+			return fmt.Errorf("invalid rate %q: unknown option %q", text, opt)
+		}
+		burst, err = strconv.Atoi(val)
+		if err != nil || burst < 0 {
+			return fmt.Errorf("invalid rate %q: invalid burst %q", text, val)
+		}
+	}
+
+	r.Events, r.Per, r.Burst = n, per, burst
+	return nil
+}
+
+// MarshalText formats the rate in the form `events/unit [burst=n]`.
+func (r Rate) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// String formats the rate in the form `events/unit [burst=n]`, e.g.
+// `5/m burst=20`, or returns "" if its period is unset.
+func (r Rate) String() string {
+	if r.Per <= 0 {
+		return ""
+	}
+	unit := r.Per.String()
+	for u, d := range rateUnits {
+		if d == r.Per {
+			unit = u
+		}
+	}
+	s := strconv.Itoa(r.Events) + "/" + unit
+	if r.Burst > 0 {
+		s += " burst=" + strconv.Itoa(r.Burst)
+	}
+	return s
+}
+
+// PerSecond returns the number of events per second.
+func (r Rate) PerSecond() float64 {
+	if r.Per <= 0 {
+		return 0
+	}
+	return float64(r.Events) / r.Per.Seconds()
+}
+
+// Interval returns the time between two events, or 0 if there are none.
+func (r Rate) Interval() time.Duration {
+	if r.Events <= 0 {
+		return 0
+	}
+	return r.Per / time.Duration(r.Events)
+}
@@ -0,0 +1,86 @@
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_cfg_Load_Rate(t *testing.T) {
+	type Config struct {
+		Requests Rate   `cfg:"requests"`
+		Jobs     Rate   `cfg:"jobs" default:"5/m burst=20"`
+		Retries  *Rate  `cfg:"retries"`
+		Tiers    []Rate `cfg:"tiers"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), `
+requests: 100/s
+tiers: [10/s, "1000/h burst=50"]
+`)
+
+	os.Clearenv()
+	setenv(t, "APP_RETRIES", "3/10s")
+
+	var cfg Config
+	if err := Load(&cfg, Dirs(dir), UseEnv("app")); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := Config{
+		Requests: Rate{Events: 100, Per: time.Second},
+		Jobs:     Rate{Events: 5, Per: time.Minute, Burst: 20},
+		Retries:  &Rate{Events: 3, Per: 10 * time.Second},
+		Tiers:    []Rate{{Events: 10, Per: time.Second}, {Events: 1000, Per: time.Hour, Burst: 50}},
+	}
+	if !reflect.DeepEqual(want, cfg) {
+		t.Errorf("\nwant %+v\ngot %+v", want, cfg)
+	}
+}
+
+func TestRate(t *testing.T) {
+	for _, tc := range []struct {
+		In        string
+		Want      Rate
+		String    string
+		PerSecond float64
+		Interval  time.Duration
+	}{
+		{In: "100/s", Want: Rate{Events: 100, Per: time.Second}, String: "100/s", PerSecond: 100, Interval: 10 * time.Millisecond},
+		{In: " 5/m  burst=20 ", Want: Rate{Events: 5, Per: time.Minute, Burst: 20}, String: "5/m burst=20", PerSecond: 5.0 / 60, Interval: 12 * time.Second},
+		{In: "300/10s", Want: Rate{Events: 300, Per: 10 * time.Second}, String: "300/10s", PerSecond: 30, Interval: time.Second / 30},
+		{In: "1/d", Want: Rate{Events: 1, Per: day}, String: "1/d", PerSecond: 1.0 / 86400, Interval: day},
+		{In: "0/s", Want: Rate{Per: time.Second}, String: "0/s"},
+	} {
+		t.Run(tc.In, func(t *testing.T) {
+			var r Rate
+			if err := r.UnmarshalText([]byte(tc.In)); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if r != tc.Want {
+				t.Errorf("want %+v, got %+v", tc.Want, r)
+			}
+			if r.String() != tc.String {
+				t.Errorf("want %s, got %s", tc.String, r.String())
+			}
+			if r.PerSecond() != tc.PerSecond {
+				t.Errorf("want %v per second, got %v", tc.PerSecond, r.PerSecond())
+			}
+			if r.Interval() != tc.Interval {
+				t.Errorf("want interval %v, got %v", tc.Interval, r.Interval())
+			}
+		})
+	}
+
+	for _, in := range []string{"", "100", "x/s", "-1/s", "1/y", "1/0s", "1/s burst=x", "1/s limit=2"} {
+		t.Run("invalid "+in, func(t *testing.T) {
+			var r Rate
+			if err := r.UnmarshalText([]byte(in)); err == nil {
+				t.Fatal("expected err")
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package cfg
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// readerInput is a config read from a stream rather than a file.
+type readerInput struct {
+	r      io.Reader
+	format string // extension of the files of the same format, e.g. `.yaml`.
+}
+
+// LoadReader loads the config read from r into cfg, e.g. from stdin, a
+// network response or a test buffer. format is the extension of files of
+// the same format, with or without the leading dot (e.g. `yaml` or `.json`).
+//
+// Config files are not searched for. Options otherwise apply as they do to
+// Load.
+func LoadReader(cfg interface{}, r io.Reader, format string, options ...Option) error {
+	conf := defaultCfg()
+
+	for _, opt := range options {
+		opt(conf)
+	}
+
+	if !strings.HasPrefix(format, ".") {
+		format = "." + format
+	}
+	conf.ignoreFile = true
+	conf.reader = &readerInput{r: r, format: format}
+
+	return conf.Load(cfg)
+}
+
+// loadReader decodes the config read from the reader input into cfg.
+func (f *cfg) loadReader(cfg interface{}) error {
+	f.sourceNames = append(f.sourceNames, "reader")
+
+	r, err := f.verifyDigest(f.reader.r, "reader")
+	if err != nil {
+		return err
+	}
+	if r, err = f.limit(r); err != nil {
+		return err
+	}
+
+	if f.reader.format == ".env" {
+		return f.addDotenv(r)
+	}
+
+	vals := make(map[string]interface{})
+	if err := f.decodeReader(vals, r, "reader"+f.reader.format); err != nil {
+		return err
+	}
+
+	if err := f.prepareVals(vals, "reader", true); err != nil {
+		return fmt.Errorf("reader: %w", err)
+	}
+
+	return f.decodeMap(vals, cfg)
+}
+
+// hasOtherSources reports whether values are provided by other means than
+// config files.
+func (f *cfg) hasOtherSources() bool {
+	return f.useEnv || len(f.sources) > 0 || f.reader != nil
+}
+
+// LoadBytes loads the config held in data into cfg, e.g. a config fetched
+// from a secret store. format is handled as it is by LoadReader.
+func LoadBytes(cfg interface{}, data []byte, format string, options ...Option) error {
+	return LoadReader(cfg, bytes.NewReader(data), format, options...)
+}
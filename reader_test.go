@@ -0,0 +1,79 @@
+package cfg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_LoadReader(t *testing.T) {
+	type Config struct {
+		Host  string `cfg:"host"`
+		Port  int    `cfg:"port" default:"80"`
+		Level string `cfg:"level"`
+	}
+
+	for _, tc := range []struct {
+		format string
+		data   string
+	}{
+		{"yaml", "host: localhost\nlevel: debug\n"},
+		{".json", `{"host": "localhost", "level": "debug"}`},
+		{"toml", "host = \"localhost\"\nlevel = \"debug\"\n"},
+		{"properties", "host=localhost\nlevel=debug\n"},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			var cfg Config
+			if err := LoadReader(&cfg, strings.NewReader(tc.data), tc.format); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			want := Config{Host: "localhost", Port: 80, Level: "debug"}
+			if cfg != want {
+				t.Errorf("\nwant %+v\ngot  %+v", want, cfg)
+			}
+		})
+	}
+
+	t.Run("env", func(t *testing.T) {
+		var cfg Config
+		if err := LoadReader(&cfg, strings.NewReader("APP_HOST=localhost\n"), "env", UseEnv("app")); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Host != "localhost" {
+			t.Errorf("want host localhost, got %q", cfg.Host)
+		}
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		var cfg Config
+		if err := LoadReader(&cfg, strings.NewReader(""), "hcl"); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+
+	t.Run("size limit", func(t *testing.T) {
+		var cfg Config
+		err := LoadReader(&cfg, strings.NewReader("host: localhost\n"), "yaml", MaxFileSize(4))
+		if !errors.Is(err, ErrLimitExceeded) {
+			t.Fatalf("want ErrLimitExceeded, got %v", err)
+		}
+	})
+}
+
+func Test_LoadBytes(t *testing.T) {
+	type Config struct {
+		Password string `cfg:"password" validate:"required"`
+	}
+
+	var cfg Config
+	if err := LoadBytes(&cfg, []byte(`{"password": "hunter2"}`), "json"); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if cfg.Password != "hunter2" {
+		t.Errorf("want password hunter2, got %q", cfg.Password)
+	}
+
+	if err := LoadBytes(&Config{}, []byte(`{}`), "json"); err == nil {
+		t.Fatal("expected required validation err")
+	}
+}
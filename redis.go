@@ -0,0 +1,175 @@
+package cfg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// redisMaxBulkLen is the max length of Redis strings.
+const redisMaxBulkLen = 512 << 20
+
+// RedisSource is a source that reads config values from a Redis key holding
+// a config file, e.g. runtime settings shared by a fleet of services. It
+// speaks the Redis protocol itself, so that no Redis client dependency is
+// needed.
+type RedisSource struct {
+	Addr     string      // address of the Redis server, e.g. `127.0.0.1:6379`.
+	Key      string      // key holding the config.
+	Format   string      // format of the value of Key, e.g. `json`. Defaults to `yaml`.
+	Username string      // username of the ACL user, if any.
+	Password string      // password, if any.
+	DB       int         // index of the database of Key.
+	TLS      *tls.Config // TLS config, if the server requires TLS.
+}
+
+// Read returns the values decoded from the value of Key. The connection is
+// closed once ctx is done, and values larger than the size of the
+// MaxFileSize option, if any, fail with an error wrapping ErrLimitExceeded.
+func (s *RedisSource) Read(ctx context.Context) (map[string]interface{}, error) {
+	val, err := s.get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+
+	format := s.Format
+	if format == "" {
+		format = "yaml"
+	}
+	if !strings.HasPrefix(format, ".") {
+		format = "." + format
+	}
+	decode, err := lookupDecoder(format)
+	if err != nil {
+		return nil, err
+	}
+
+	vals := make(map[string]interface{})
+	if err := decode(bytes.NewReader(val), vals); err != nil {
+		return nil, fmt.Errorf("redis: key %q: %w", s.Key, err)
+	}
+	return vals, nil
+}
+
+// String returns the name of the source, e.g. in the Provenance of a
+// Result.
+func (s *RedisSource) String() string {
+	return "redis:" + s.Key
+}
+
+// get returns the value of Key.
+func (s *RedisSource) get(ctx context.Context) ([]byte, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", s.Addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	// blocked reads and writes are interrupted by closing the connection.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	if s.TLS != nil {
+		conn = tls.Client(conn, s.TLS)
+	}
+
+	val, err := s.exchange(conn, contextMaxFileSize(ctx))
+	if err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return val, err
+}
+
+// exchange authenticates and selects the database over conn, if needed,
+// and returns the value of Key, which must be at most max bytes if max is
+// positive.
+func (s *RedisSource) exchange(conn net.Conn, max int64) ([]byte, error) {
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+
+	if s.Password != "" {
+		args := []string{"AUTH", s.Password}
+		if s.Username != "" {
+			args = []string{"AUTH", s.Username, s.Password}
+		}
+		if _, err := redisCommand(rw, max, args...); err != nil {
+			return nil, fmt.Errorf("auth: %w", err)
+		}
+	}
+	if s.DB != 0 {
+		if _, err := redisCommand(rw, max, "SELECT", strconv.Itoa(s.DB)); err != nil {
+			return nil, fmt.Errorf("select: %w", err)
+		}
+	}
+
+	val, err := redisCommand(rw, max, "GET", s.Key)
+	if err != nil {
+		return nil, err
+	}
+	if val == nil {
+		return nil, fmt.Errorf("key %q not found", s.Key)
+	}
+	return val, nil
+}
+
+// redisCommand sends a command and returns its reply. Replies that are nil
+// bulk strings are returned as nil. Bulk strings longer than max bytes, if
+// max is positive, or than the max length of Redis strings fail with an
+// error wrapping ErrLimitExceeded.
+func redisCommand(rw *bufio.ReadWriter, max int64, args ...string) ([]byte, error) {
+	fmt.Fprintf(rw, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(rw, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if err := rw.Flush(); err != nil {
+		return nil, err
+	}
+
+	line, err := rw.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("empty reply")
+	}
+
+	switch line[0] {
+	case '+', ':':
+		return []byte(line[1:]), nil
+	case '-':
+		return nil, fmt.Errorf("%s", line[1:])
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid reply %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		if (max > 0 && int64(n) > max) || n > redisMaxBulkLen {
+			return nil, fmt.Errorf("%w: value of %d bytes is larger than the max size", ErrLimitExceeded, n)
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(rw, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	default:
+		return nil, fmt.Errorf("unexpected reply %q", line)
+	}
+}
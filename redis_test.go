@@ -0,0 +1,175 @@
+package cfg
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newRedisServer starts a server that answers the AUTH, SELECT and GET
+// commands of the Redis protocol, requiring password if not empty.
+func newRedisServer(t *testing.T, password string, dbs map[int]map[string]string) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveRedis(conn, password, dbs)
+		}
+	}()
+	return l.Addr().String()
+}
+
+func serveRedis(conn net.Conn, password string, dbs map[int]map[string]string) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	authed, db := password == "", 0
+
+	for {
+		args, err := readRedisCommand(r)
+		if err != nil {
+			return
+		}
+		switch strings.ToUpper(args[0]) {
+		case "AUTH":
+			if args[len(args)-1] != password {
+				fmt.Fprint(conn, "-WRONGPASS invalid username-password pair\r\n")
+				continue
+			}
+			authed = true
+			fmt.Fprint(conn, "+OK\r\n")
+		case "SELECT":
+			db, _ = strconv.Atoi(args[1])
+			fmt.Fprint(conn, "+OK\r\n")
+		case "GET":
+			if !authed {
+				fmt.Fprint(conn, "-NOAUTH Authentication required.\r\n")
+				continue
+			}
+			val, ok := dbs[db][args[1]]
+			if !ok {
+				fmt.Fprint(conn, "$-1\r\n")
+				continue
+			}
+			fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(val), val)
+		default:
+			fmt.Fprintf(conn, "-ERR unknown command '%s'\r\n", args[0])
+		}
+	}
+}
+
+func readRedisCommand(r *bufio.Reader) ([]string, error) {
+	var n int
+	if _, err := fmt.Fscanf(r, "*%d\r\n", &n); err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		var size int
+		if _, err := fmt.Fscanf(r, "$%d\r\n", &size); err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func Test_RedisSource(t *testing.T) {
+	addr := newRedisServer(t, "secret", map[int]map[string]string{
+		0: {"myapp:config": "server:\n  port: 8080\n"},
+		2: {"myapp:config": `{"server": {"port": 9090}}`},
+	})
+
+	type Config struct {
+		Server struct {
+			Host string `cfg:"host" default:"localhost"`
+			Port int    `cfg:"port"`
+		} `cfg:"server"`
+	}
+
+	t.Run("yaml", func(t *testing.T) {
+		var cfg Config
+		err := Load(&cfg, IgnoreFile(), WithSources(&RedisSource{Addr: addr, Key: "myapp:config", Password: "secret"}))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Server.Host != "localhost" || cfg.Server.Port != 8080 {
+			t.Errorf("unexpected cfg %+v", cfg)
+		}
+	})
+
+	t.Run("json in db", func(t *testing.T) {
+		src := &RedisSource{Addr: addr, Key: "myapp:config", Format: "json", Username: "app", Password: "secret", DB: 2}
+		vals, err := src.Read(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if port := vals["server"].(map[string]interface{})["port"]; port != float64(9090) {
+			t.Errorf("unexpected vals %v", vals)
+		}
+	})
+
+	t.Run("max file size", func(t *testing.T) {
+		var cfg Config
+		err := Load(&cfg, IgnoreFile(), MaxFileSize(8), WithSources(&RedisSource{Addr: addr, Key: "myapp:config", Password: "secret"}))
+		if !errors.Is(err, ErrLimitExceeded) {
+			t.Fatalf("want ErrLimitExceeded, got %v", err)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		// a server that accepts connections but never replies.
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { l.Close() })
+		go func() {
+			for {
+				conn, err := l.Accept()
+				if err != nil {
+					return
+				}
+				t.Cleanup(func() { conn.Close() })
+			}
+		}()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+		_, err = (&RedisSource{Addr: l.Addr().String(), Key: "myapp:config"}).Read(ctx)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("want context.Canceled, got %v", err)
+		}
+	})
+
+	for name, src := range map[string]*RedisSource{
+		"missing key":    {Addr: addr, Key: "nope", Password: "secret"},
+		"wrong password": {Addr: addr, Key: "myapp:config", Password: "wrong"},
+		"no password":    {Addr: addr, Key: "myapp:config"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := src.Read(context.Background()); err == nil {
+				t.Fatal("expected err")
+			}
+		})
+	}
+}
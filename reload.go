@@ -0,0 +1,402 @@
+package cfg
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReloadRestartRequired is the value of the `reload` struct tag that marks a
+// field whose changes only take effect after a restart, such as a listen
+// address or the size of a pool.
+//
+//	type Config struct {
+//	  Addr string `cfg:"addr" reload:"restart-required"`
+//	}
+//
+// The tag applies to all the nested fields of a struct field.
+const ReloadRestartRequired = "restart-required"
+
+var (
+	reloadHooksMu sync.RWMutex
+	reloadHooks   = map[interface{}][]func(){}
+
+	lastReloadsMu sync.Mutex
+	lastReloads   = map[interface{}]time.Time{} // time Reload last applied changes to each config.
+)
+
+// onReload registers fn to be called each time Reload applies new values
+// to cfg.
+func onReload(cfg interface{}, fn func()) {
+	reloadHooksMu.Lock()
+	defer reloadHooksMu.Unlock()
+	reloadHooks[cfg] = append(reloadHooks[cfg], fn)
+}
+
+// Forget releases the state kept for cfg by the package: the snapshot of
+// the Freeze option, the hooks of SyncLevel and LevelVar, the time of the
+// last reload of ReloadRateLimit and the changes deferred by Reload, which
+// are then never applied. The state is kept by pointer for the
+// life of the process, so call Forget once cfg is no longer used, e.g. for
+// configs loaded per tenant or per request:
+//
+//	defer cfg.Forget(&conf)
+//
+// CheckUnchanged then fails, and reloads no longer update levels, for cfg.
+func Forget(cfg interface{}) {
+	if l, ok := cfg.(liveConfig); ok {
+		cfg, _, _ = l.live()
+	}
+	dropPendingReload(cfg)
+
+	frozenMu.Lock()
+	delete(frozen, cfg)
+	frozenMu.Unlock()
+
+	reloadHooksMu.Lock()
+	delete(reloadHooks, cfg)
+	reloadHooksMu.Unlock()
+
+	lastReloadsMu.Lock()
+	delete(lastReloads, cfg)
+	lastReloadsMu.Unlock()
+}
+
+// Change is a field whose value differs between two configs.
+type Change struct {
+	Path            string // path of the field, e.g. `server.port`.
+	RestartRequired bool   // true if the field is tagged `reload:"restart-required"`.
+}
+
+// Diff returns the fields whose values differ between old and new, which
+// must be pointers to structs of the same type, sorted by path. Only the
+// innermost changed fields are reported, not their parents. options
+// configure the tag used to name fields.
+func Diff(old, new interface{}, options ...Option) ([]Change, error) {
+	conf := defaultCfg()
+	for _, opt := range options {
+		opt(conf)
+	}
+	return conf.diff(old, new)
+}
+
+func (f *cfg) diff(old, new interface{}) ([]Change, error) {
+	if !isStructPtr(old) || !isStructPtr(new) {
+		return nil, fmt.Errorf("configs must be pointers to structs")
+	}
+	if reflect.TypeOf(old) != reflect.TypeOf(new) {
+		return nil, fmt.Errorf("configs must be of the same type, got %T and %T", old, new)
+	}
+
+	oldHashes, newHashes := hashFields(old, f.tag), hashFields(new, f.tag)
+	var changed []string
+	for path, h := range oldHashes {
+		if now, ok := newHashes[path]; !ok || now != h {
+			changed = append(changed, path)
+		}
+	}
+	for path := range newHashes {
+		if _, ok := oldHashes[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+
+	restart := restartRequiredFields(old, f.tag)
+	for path, ok := range restartRequiredFields(new, f.tag) {
+		restart[path] = restart[path] || ok
+	}
+
+	changes := make([]Change, 0, len(changed))
+	for _, path := range innermostPaths(changed) {
+		changes = append(changes, Change{Path: path, RestartRequired: restart[path]})
+	}
+	return changes, nil
+}
+
+// Reload loads the config into a new struct of the same type as cfg and
+// reports the fields that changed. The new values are applied to cfg
+// unless a field tagged `reload:"restart-required"` changed, in which case
+// cfg is left untouched and an error wrapping ErrRestartRequired is
+// returned along with the changes. Use Load and Diff to apply such changes
+// regardless. Likewise, cfg is left untouched if any of the checks given
+// with CompatCheck fails.
+//
+// Changes are deferred outside of the windows of ReloadWindows and within
+// the interval of ReloadRateLimit: cfg is left untouched and an error
+// wrapping ErrReloadDeferred is returned along with the changes. The new
+// values are kept pending and applied from another goroutine as soon as
+// the schedule allows it, e.g. when the next window opens, unless a later
+// call to Reload or Forget drops them first: hold cfg in a Live for its
+// readers to synchronize with that write. With ReloadStagger, changes are
+// applied after the delay of the instance.
+//
+// Registered sections are loaded into new structs too, checked along with
+// cfg, and set to their new values when those of cfg are. Their changes
+// are reported under the name of the section, e.g. `metrics.addr`.
+func Reload(cfg interface{}, options ...Option) ([]Change, error) {
+	return ReloadContext(context.Background(), cfg, options...)
+}
+
+// ReloadContext reloads the config like Reload, under ctx as LoadContext
+// loads it. cfg may also be a *Live, whose readers see the new values once
+// they are committed. The wait of ReloadStagger ends with an error wrapping the
+// context's error, and cfg left untouched, once ctx is done.
+func ReloadContext(ctx context.Context, cfg interface{}, options ...Option) ([]Change, error) {
+	cfg, options, unlock := lockLive(cfg, options)
+	defer unlock()
+
+	conf := defaultCfg()
+	for _, opt := range options {
+		opt(conf)
+	}
+	if !isStructPtr(cfg) {
+		return nil, fmt.Errorf("cfg must be a pointer to a struct")
+	}
+
+	frozen := conf.frozen
+	conf.frozen = false
+
+	sections := registeredSections()
+	conf.sections = sectionCopies(sections)
+
+	fresh := reflect.New(reflect.TypeOf(cfg).Elem())
+	if err := conf.loadContext(ctx, fresh.Interface()); err != nil {
+		return nil, err
+	}
+
+	changes, err := conf.diff(cfg, fresh.Interface())
+	if err != nil {
+		return nil, err
+	}
+	for name, section := range sections {
+		sectionChanges, err := conf.diff(section, conf.sections[name])
+		if err != nil {
+			return nil, fmt.Errorf("section %q: %w", name, err)
+		}
+		for _, c := range sectionChanges {
+			c.Path = name + "." + c.Path
+			changes = append(changes, c)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+
+	// the latest config supersedes the changes deferred before.
+	dropPendingReload(cfg)
+	if err := conf.checkChanges(cfg, fresh.Interface(), changes); err != nil {
+		return changes, err
+	}
+
+	if len(changes) > 0 {
+		if err := conf.checkReloadSchedule(cfg); err != nil {
+			conf.deferReload(cfg, fresh, frozen, sections)
+			return changes, err
+		}
+		if conf.reloadStagger > 0 {
+			timer := time.NewTimer(staggerOffset(hostname(), conf.reloadStagger))
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return changes, fmt.Errorf("reload stagger: %w", ctx.Err())
+			}
+		}
+	}
+
+	conf.commit(cfg, fresh, frozen, sections)
+	if len(changes) > 0 {
+		conf.recordReload(cfg)
+	}
+	return changes, nil
+}
+
+// checkChanges returns an error if the changes from cfg to fresh require a
+// restart or fail the compat checks.
+func (f *cfg) checkChanges(cfg, fresh interface{}, changes []Change) error {
+	var restart []string
+	for _, c := range changes {
+		if c.RestartRequired {
+			restart = append(restart, c.Path)
+		}
+	}
+	if len(restart) > 0 {
+		return fmt.Errorf("%w: %s", ErrRestartRequired, strings.Join(restart, ", "))
+	}
+
+	for _, check := range f.compatChecks {
+		if err := check(cfg, fresh); err != nil {
+			return fmt.Errorf("incompatible change: %w", err)
+		}
+	}
+	return nil
+}
+
+// commit sets cfg to the value fresh points to, freezing it again if it
+// was frozen, sets each of sections to the copy it was loaded into, and
+// calls the reload hooks of cfg.
+func (f *cfg) commit(cfg interface{}, fresh reflect.Value, frozen bool, sections map[string]interface{}) {
+	if f.commitLock != nil {
+		f.commitLock.Lock()
+	}
+	reflect.ValueOf(cfg).Elem().Set(fresh.Elem())
+	for name, section := range sections {
+		reflect.ValueOf(section).Elem().Set(reflect.ValueOf(f.sections[name]).Elem())
+	}
+	if f.commitLock != nil {
+		f.commitLock.Unlock()
+	}
+	if frozen {
+		f.freeze(cfg)
+	}
+
+	reloadHooksMu.RLock()
+	hooks := reloadHooks[cfg]
+	reloadHooksMu.RUnlock()
+	for _, fn := range hooks {
+		fn()
+	}
+}
+
+// restartRequiredFields returns the paths of the fields of cfg that are
+// tagged `reload:"restart-required"`, or whose ancestors are.
+func restartRequiredFields(cfg interface{}, tag string) map[string]bool {
+	fields := make(map[string]bool)
+	for _, field := range flattenCfg(cfg, tag) {
+		for f := field; f != nil; f = f.parent {
+			if f.restartRequired {
+				fields[field.path()] = true
+				break
+			}
+		}
+	}
+	return fields
+}
+
+// checkReloadSchedule returns an error wrapping ErrReloadDeferred if changes
+// to cfg must not be applied now.
+func (f *cfg) checkReloadSchedule(cfg interface{}) error {
+	now := f.now()
+	if len(f.reloadWindows) > 0 {
+		open := false
+		for _, w := range f.reloadWindows {
+			open = open || w.Contains(now)
+		}
+		if !open {
+			return fmt.Errorf("%w: outside of reload windows", ErrReloadDeferred)
+		}
+	}
+
+	lastReloadsMu.Lock()
+	defer lastReloadsMu.Unlock()
+	if last, ok := lastReloads[cfg]; ok && f.reloadEvery > 0 && now.Sub(last) < f.reloadEvery {
+		return fmt.Errorf("%w: last reload at %s", ErrReloadDeferred, last.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// pendingReload is a commit of changes deferred by the schedule of Reload.
+type pendingReload struct {
+	timer *time.Timer
+}
+
+var (
+	pendingReloadsMu sync.Mutex
+	pendingReloads   = map[interface{}]*pendingReload{} // changes deferred by Reload, by config.
+)
+
+// deferReload schedules the commit of fresh to cfg, as commit does, for
+// when the schedule of Reload next allows it, replacing the changes
+// deferred before. Nothing is scheduled if no window opens within a week.
+func (f *cfg) deferReload(cfg interface{}, fresh reflect.Value, frozen bool, sections map[string]interface{}) {
+	next, ok := f.nextReload(cfg)
+	if !ok {
+		return
+	}
+	delay := next.Sub(f.now())
+	if f.reloadStagger > 0 {
+		delay += staggerOffset(hostname(), f.reloadStagger)
+	}
+
+	p := &pendingReload{}
+	pendingReloadsMu.Lock()
+	defer pendingReloadsMu.Unlock()
+	if old, ok := pendingReloads[cfg]; ok {
+		old.timer.Stop()
+	}
+	pendingReloads[cfg] = p
+	p.timer = time.AfterFunc(delay, func() {
+		if f.writeLock != nil {
+			f.writeLock.Lock()
+			defer f.writeLock.Unlock()
+		}
+		// a reload or Forget may have dropped p as its timer fired.
+		pendingReloadsMu.Lock()
+		current := pendingReloads[cfg] == p
+		if current {
+			delete(pendingReloads, cfg)
+		}
+		pendingReloadsMu.Unlock()
+		if !current {
+			return
+		}
+		f.commit(cfg, fresh, frozen, sections)
+		f.recordReload(cfg)
+	})
+}
+
+// dropPendingReload cancels the changes to cfg deferred by Reload, if any.
+func dropPendingReload(cfg interface{}) {
+	pendingReloadsMu.Lock()
+	defer pendingReloadsMu.Unlock()
+	if p, ok := pendingReloads[cfg]; ok {
+		p.timer.Stop()
+		delete(pendingReloads, cfg)
+	}
+}
+
+// nextReload returns the earliest time from now on at which the schedule
+// of Reload allows changes to cfg, and false if there is none within a
+// week.
+func (f *cfg) nextReload(cfg interface{}) (time.Time, bool) {
+	next := f.now()
+	lastReloadsMu.Lock()
+	last, ok := lastReloads[cfg]
+	lastReloadsMu.Unlock()
+	if ok && f.reloadEvery > 0 && last.Add(f.reloadEvery).After(next) {
+		next = last.Add(f.reloadEvery)
+	}
+	if len(f.reloadWindows) == 0 {
+		return next, true
+	}
+	return nextInWindows(f.reloadWindows, next)
+}
+
+// recordReload records that changes were applied to cfg, starting the
+// interval of ReloadRateLimit. Reloads that are deferred, canceled or fail
+// are not recorded.
+func (f *cfg) recordReload(cfg interface{}) {
+	lastReloadsMu.Lock()
+	defer lastReloadsMu.Unlock()
+	lastReloads[cfg] = f.now()
+}
+
+// staggerOffset returns the delay within window of the instance id, which
+// is the same on each call so that the instances of a fleet apply changes
+// spread across window rather than all at once.
+func staggerOffset(id string, window time.Duration) time.Duration {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(id))
+	return time.Duration(h.Sum64() % uint64(window))
+}
+
+// hostname returns the host name reported by the kernel, or "" if it is
+// unknown.
+func hostname() string {
+	name, _ := os.Hostname()
+	return name
+}
@@ -0,0 +1,461 @@
+package cfg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type reloadConfig struct {
+	Server struct {
+		Addr    string `cfg:"addr" reload:"restart-required"`
+		Timeout string `cfg:"timeout"`
+	} `cfg:"server"`
+	Pool struct {
+		Size int `cfg:"size"`
+	} `cfg:"pool" reload:"restart-required"`
+	Level string `cfg:"level"`
+}
+
+func Test_Diff(t *testing.T) {
+	var old, new reloadConfig
+	old.Server.Addr, new.Server.Addr = ":80", ":8080"
+	old.Pool.Size, new.Pool.Size = 1, 2
+	old.Level, new.Level = "info", "debug"
+	old.Server.Timeout, new.Server.Timeout = "1s", "1s"
+
+	changes, err := Diff(&old, &new)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := []Change{
+		{Path: "level"},
+		{Path: "pool.size", RestartRequired: true},
+		{Path: "server.addr", RestartRequired: true},
+	}
+	if !reflect.DeepEqual(want, changes) {
+		t.Errorf("\nwant %+v\ngot  %+v", want, changes)
+	}
+
+	if _, err := Diff(&old, &struct{}{}); err == nil {
+		t.Error("expected err for mismatched types")
+	}
+}
+
+func Test_Reload(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	writeFile(t, file, "server:\n  addr: ':80'\n  timeout: 1s\npool:\n  size: 1\nlevel: info\n")
+
+	var cfg reloadConfig
+	if err := Load(&cfg, Dirs(dir)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	t.Run("hot change is applied", func(t *testing.T) {
+		writeFile(t, file, "server:\n  addr: ':80'\n  timeout: 2s\npool:\n  size: 1\nlevel: debug\n")
+
+		changes, err := Reload(&cfg, Dirs(dir))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		want := []Change{{Path: "level"}, {Path: "server.timeout"}}
+		if !reflect.DeepEqual(want, changes) {
+			t.Errorf("\nwant %+v\ngot  %+v", want, changes)
+		}
+		if cfg.Level != "debug" || cfg.Server.Timeout != "2s" {
+			t.Errorf("changes not applied: %+v", cfg)
+		}
+	})
+
+	t.Run("restart required change is refused", func(t *testing.T) {
+		writeFile(t, file, "server:\n  addr: ':8080'\n  timeout: 2s\npool:\n  size: 1\nlevel: warn\n")
+
+		changes, err := Reload(&cfg, Dirs(dir))
+		if !errors.Is(err, ErrRestartRequired) {
+			t.Fatalf("want ErrRestartRequired, got %v", err)
+		}
+		want := []Change{{Path: "level"}, {Path: "server.addr", RestartRequired: true}}
+		if !reflect.DeepEqual(want, changes) {
+			t.Errorf("\nwant %+v\ngot  %+v", want, changes)
+		}
+		if cfg.Level != "debug" || cfg.Server.Addr != ":80" {
+			t.Errorf("changes applied: %+v", cfg)
+		}
+	})
+}
+
+func Test_Reload_Live(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	writeFile(t, file, "server:\n  addr: ':80'\n  timeout: 1s\npool:\n  size: 1\nlevel: info\n")
+
+	var cfg reloadConfig
+	if err := Load(&cfg, Dirs(dir)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	live := NewLive(&cfg)
+
+	writeFile(t, file, "server:\n  addr: ':80'\n  timeout: 1s\npool:\n  size: 1\nlevel: debug\n")
+	changes, err := Reload(live, Dirs(dir))
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if want := []Change{{Path: "level"}}; !reflect.DeepEqual(want, changes) {
+		t.Errorf("\nwant %+v\ngot  %+v", want, changes)
+	}
+	if got := live.Get(); got.Level != "debug" {
+		t.Errorf("changes not applied: %+v", got)
+	}
+}
+
+func Test_Reload_Sections(t *testing.T) {
+	type Config struct {
+		Host string `cfg:"host" reload:"restart-required"`
+	}
+
+	var metrics metricsSection
+	registerTestSection(t, "metrics", &metrics)
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	writeFile(t, file, "host: a\nmetrics:\n  path: /metrics\n")
+
+	var cfg Config
+	if err := Load(&cfg, Dirs(dir)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	t.Run("refused change leaves section untouched", func(t *testing.T) {
+		writeFile(t, file, "host: b\nmetrics:\n  path: /stats\n")
+
+		changes, err := Reload(&cfg, Dirs(dir))
+		if !errors.Is(err, ErrRestartRequired) {
+			t.Fatalf("want ErrRestartRequired, got %v", err)
+		}
+		want := []Change{{Path: "host", RestartRequired: true}, {Path: "metrics.path"}}
+		if !reflect.DeepEqual(want, changes) {
+			t.Errorf("\nwant %+v\ngot  %+v", want, changes)
+		}
+		if metrics.Path != "/metrics" {
+			t.Errorf("want path /metrics, got %q", metrics.Path)
+		}
+	})
+
+	t.Run("section change is applied", func(t *testing.T) {
+		writeFile(t, file, "host: a\nmetrics:\n  path: /stats\n")
+
+		changes, err := Reload(&cfg, Dirs(dir))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if want := []Change{{Path: "metrics.path"}}; !reflect.DeepEqual(want, changes) {
+			t.Errorf("\nwant %+v\ngot  %+v", want, changes)
+		}
+		if want := (metricsSection{Addr: ":9090", Path: "/stats"}); metrics != want {
+			t.Errorf("\nwant %+v\ngot  %+v", want, metrics)
+		}
+	})
+}
+
+func Test_Reload_CompatCheck(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	writeFile(t, file, "level: info\n")
+
+	var cfg reloadConfig
+	if err := Load(&cfg, Dirs(dir)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	noDowngrade := CompatCheck(func(old, new *reloadConfig) error {
+		if old.Level == "info" && new.Level == "error" {
+			return errors.New("level cannot skip warn")
+		}
+		return nil
+	})
+
+	writeFile(t, file, "level: error\n")
+	_, err := Reload(&cfg, Dirs(dir), noDowngrade)
+	if err == nil || err.Error() != "incompatible change: level cannot skip warn" {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if cfg.Level != "info" {
+		t.Errorf("change applied: %+v", cfg)
+	}
+
+	writeFile(t, file, "level: warn\n")
+	if _, err := Reload(&cfg, Dirs(dir), noDowngrade); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if cfg.Level != "warn" {
+		t.Errorf("change not applied: %+v", cfg)
+	}
+
+	wrongType := CompatCheck(func(old, new *struct{}) error { return nil })
+	if _, err := Reload(&cfg, Dirs(dir), wrongType); err == nil {
+		t.Error("expected err for mismatched check type")
+	}
+}
+
+func Test_Reload_Schedule(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	writeFile(t, file, "level: info\n")
+
+	var cfg reloadConfig
+	if err := Load(&cfg, Dirs(dir)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	t.Cleanup(func() { Forget(&cfg) })
+
+	t.Run("windows", func(t *testing.T) {
+		var quiet Window
+		if err := quiet.UnmarshalText([]byte("Sat,Sun 02:00-04:00")); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		saturday := time.Date(2026, 1, 3, 12, 0, 0, 0, time.UTC)
+
+		writeFile(t, file, "level: warn\n")
+		changes, err := Reload(&cfg, Dirs(dir), ReloadWindows(quiet), Clock(func() time.Time { return saturday }))
+		if !errors.Is(err, ErrReloadDeferred) {
+			t.Fatalf("want ErrReloadDeferred, got %v", err)
+		}
+		if want := []Change{{Path: "level"}}; !reflect.DeepEqual(want, changes) {
+			t.Errorf("want changes %+v, got %+v", want, changes)
+		}
+		if cfg.Level != "info" {
+			t.Errorf("change applied outside of window: %+v", cfg)
+		}
+
+		writeFile(t, file, "level: error\n")
+		night := saturday.Add(-9 * time.Hour)
+		if _, err := Reload(&cfg, Dirs(dir), ReloadWindows(quiet), Clock(func() time.Time { return night })); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Level != "error" {
+			t.Errorf("latest change not applied within window: %+v", cfg)
+		}
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		now := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+		clock := Clock(func() time.Time { return now })
+		limit := ReloadRateLimit(time.Minute)
+
+		writeFile(t, file, "level: info\n")
+		if _, err := Reload(&cfg, Dirs(dir), limit, clock); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		writeFile(t, file, "level: warn\n")
+		now = now.Add(30 * time.Second)
+		if _, err := Reload(&cfg, Dirs(dir), limit, clock); !errors.Is(err, ErrReloadDeferred) {
+			t.Fatalf("want ErrReloadDeferred, got %v", err)
+		}
+		if cfg.Level != "info" {
+			t.Errorf("change applied within interval: %+v", cfg)
+		}
+
+		now = now.Add(30 * time.Second)
+		if _, err := Reload(&cfg, Dirs(dir), limit, clock); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Level != "warn" {
+			t.Errorf("change not applied after interval: %+v", cfg)
+		}
+
+		// the time of the last reload is forgotten along with cfg.
+		Forget(&cfg)
+		writeFile(t, file, "level: error\n")
+		if _, err := Reload(&cfg, Dirs(dir), limit, clock); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+}
+
+func Test_Forget(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "log:\n  level: info\n")
+
+	var cfg struct {
+		Log Logging `cfg:"log"`
+	}
+	if err := Load(&cfg, Dirs(dir), Freeze()); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	var lvl textLevel
+	if err := SyncLevel(&cfg, "log", &lvl); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	Forget(&cfg)
+	if err := CheckUnchanged(&cfg); err == nil {
+		t.Error("want err for forgotten cfg")
+	}
+	reloadHooksMu.RLock()
+	_, ok := reloadHooks[&cfg]
+	reloadHooksMu.RUnlock()
+	if ok {
+		t.Error("want reload hooks removed")
+	}
+}
+
+func Test_Reload_Pending(t *testing.T) {
+	var quiet Window
+	if err := quiet.UnmarshalText([]byte("Mon 02:00-04:00")); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	// the window opens 50ms after the time of the clock.
+	clock := Clock(func() time.Time { return time.Date(2026, 1, 5, 1, 59, 59, 950e6, time.UTC) })
+
+	load := func(t *testing.T) (*Live[reloadConfig], string) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "config.yaml"), "level: info\n")
+		var cfg reloadConfig
+		if err := Load(&cfg, Dirs(dir)); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		t.Cleanup(func() { Forget(&cfg) })
+		writeFile(t, filepath.Join(dir, "config.yaml"), "level: warn\n")
+		return NewLive(&cfg), dir
+	}
+
+	t.Run("applied when the window opens", func(t *testing.T) {
+		live, dir := load(t)
+		if _, err := Reload(live, Dirs(dir), ReloadWindows(quiet), clock); !errors.Is(err, ErrReloadDeferred) {
+			t.Fatalf("want ErrReloadDeferred, got %v", err)
+		}
+		if got := live.Get(); got.Level != "info" {
+			t.Fatalf("change applied before the window: %+v", got)
+		}
+
+		deadline := time.Now().Add(5 * time.Second)
+		for live.Get().Level != "warn" {
+			if time.Now().After(deadline) {
+				t.Fatal("change not applied when the window opened")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+
+	t.Run("dropped by forget", func(t *testing.T) {
+		live, dir := load(t)
+		if _, err := Reload(live, Dirs(dir), ReloadWindows(quiet), clock); !errors.Is(err, ErrReloadDeferred) {
+			t.Fatalf("want ErrReloadDeferred, got %v", err)
+		}
+		Forget(live)
+
+		time.Sleep(200 * time.Millisecond)
+		if got := live.Get(); got.Level != "info" {
+			t.Errorf("forgotten change applied: %+v", got)
+		}
+	})
+}
+
+func Test_nextInWindows(t *testing.T) {
+	var nights Window
+	if err := nights.UnmarshalText([]byte("Mon-Fri 22:00-06:00")); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	monday := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		from, want time.Time
+	}{
+		{from: monday.Add(12 * time.Hour), want: monday.Add(22 * time.Hour)},
+		{from: monday.Add(23 * time.Hour), want: monday.Add(23 * time.Hour)},
+		{from: monday.Add(time.Hour), want: monday.Add(22 * time.Hour)},
+		{from: monday.AddDate(0, 0, 5).Add(12 * time.Hour), want: monday.AddDate(0, 0, 7).Add(22 * time.Hour)},
+	} {
+		if got, ok := nextInWindows([]Window{nights}, tc.from); !ok || !got.Equal(tc.want) {
+			t.Errorf("from %s: want %s, got %s", tc.from, tc.want, got)
+		}
+	}
+
+	if _, ok := nextInWindows([]Window{{}}, monday); ok {
+		t.Error("want no time for a window without days")
+	}
+}
+
+func Test_staggerOffset(t *testing.T) {
+	window := 5 * time.Minute
+	offsets := make(map[time.Duration]bool)
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprintf("host-%d", i)
+		offset := staggerOffset(id, window)
+		if offset < 0 || offset >= window {
+			t.Fatalf("offset %v of %s out of window", offset, id)
+		}
+		if again := staggerOffset(id, window); again != offset {
+			t.Fatalf("want stable offset %v for %s, got %v", offset, id, again)
+		}
+		offsets[offset] = true
+	}
+	if len(offsets) < 90 {
+		t.Errorf("want offsets spread across the window, got %d distinct of 100", len(offsets))
+	}
+}
+
+func Test_Reload_Stagger(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	writeFile(t, file, "level: info\n")
+
+	var cfg reloadConfig
+	if err := Load(&cfg, Dirs(dir)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	writeFile(t, file, "level: warn\n")
+	window := 50 * time.Millisecond
+	start := time.Now()
+	if _, err := Reload(&cfg, Dirs(dir), ReloadStagger(window)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if elapsed, want := time.Since(start), staggerOffset(hostname(), window); elapsed < want {
+		t.Errorf("want a delay of at least %v, got %v", want, elapsed)
+	}
+	if cfg.Level != "warn" {
+		t.Errorf("change not applied: %+v", cfg)
+	}
+
+	t.Run("canceled", func(t *testing.T) {
+		writeFile(t, file, "level: error\n")
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := ReloadContext(ctx, &cfg, Dirs(dir), ReloadStagger(24*time.Hour))
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("want context.DeadlineExceeded, got %v", err)
+		}
+		if cfg.Level != "warn" {
+			t.Errorf("change applied: %+v", cfg)
+		}
+	})
+
+	t.Run("canceled reload leaves rate limit", func(t *testing.T) {
+		// the reloads above applied changes.
+		Forget(&cfg)
+		t.Cleanup(func() { Forget(&cfg) })
+		limit := ReloadRateLimit(time.Hour)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		writeFile(t, file, "level: debug\n")
+		if _, err := ReloadContext(ctx, &cfg, Dirs(dir), limit, ReloadStagger(24*time.Hour)); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("want context.DeadlineExceeded, got %v", err)
+		}
+		if _, err := Reload(&cfg, Dirs(dir), limit); err != nil {
+			t.Fatalf("want reload not rate limited, got %v", err)
+		}
+		if cfg.Level != "debug" {
+			t.Errorf("change not applied: %+v", cfg)
+		}
+	})
+}
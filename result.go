@@ -0,0 +1,160 @@
+package cfg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+)
+
+// Result describes how a config struct was loaded by `LoadResult`.
+type Result struct {
+	// Files are the paths of the config files that were loaded, in the
+	// order that they were loaded.
+	Files []string
+	// Dir is the directory of the first loaded config file, against which
+	// relative paths of path fields are resolved. It is empty if no
+	// config file was loaded.
+	Dir string
+	// Sources are the names of the sources other than files that were
+	// consulted, in the order that they were read: `reader` for LoadReader
+	// and LoadBytes, the name of each source given with WithSources and
+	// `env` if UseEnv is set.
+	Sources []string
+	// Unused are the sorted keys of the files and sources that did not
+	// match any field, e.g. `server.hots`.
+	Unused []string
+	// Warnings are problems that did not prevent the config from loading,
+	// such as a file that does not declare the expected schema version.
+	Warnings []string
+	// Provenance maps the path of each field that was set to the origin of
+	// its value: the path of a config file, the name of a source, `dotenv`,
+	// `env`, `flag`, `override` or `default`.
+	Provenance map[string]string
+	// Hash is a hex encoded hash of the values of the config struct, which
+	// changes whenever any of them does.
+	Hash string
+}
+
+// LoadResult behaves like `Load` but additionally returns a Result describing
+// where the configuration was loaded from.
+//
+//	res, err := cfg.LoadResult(&conf)
+//	if err != nil {
+//	  // handle err
+//	}
+//	log.Printf("loaded config %s from %v", res.Hash, res.Files)
+func LoadResult(cfg interface{}, options ...Option) (*Result, error) {
+	conf := defaultCfg()
+
+	for _, opt := range options {
+		opt(conf)
+	}
+
+	if err := conf.Load(cfg); err != nil {
+		return nil, err
+	}
+
+	return conf.result(cfg), nil
+}
+
+// Paths returns the sorted paths of the fields whose value came from
+// origin as recorded in Provenance, e.g. `default` for the fields that were
+// defaulted or `env` for those set by env vars:
+//
+//	log.Printf("defaulted: %v, from env: %v", res.Paths("default"), res.Paths("env"))
+func (r *Result) Paths(origin string) []string {
+	var paths []string
+	for path, o := range r.Provenance {
+		if o == origin {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// result returns the Result of the last call to Load with cfg.
+func (f *cfg) result(cfg interface{}) *Result {
+	unused := make(map[string]bool, len(f.unused))
+	for _, key := range f.unused {
+		unused[key] = true
+	}
+	keys := make([]string, 0, len(unused))
+	for key := range unused {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	provenance := make(map[string]string, len(f.origins))
+	for path, origin := range f.origins {
+		if !unused[path] {
+			provenance[path] = origin
+		}
+	}
+
+	return &Result{
+		Files:      f.files,
+		Dir:        f.fileDir,
+		Sources:    f.sourceNames,
+		Unused:     keys,
+		Warnings:   f.warnings,
+		Provenance: provenance,
+		Hash:       configHash(cfg, f.tag),
+	}
+}
+
+// warnf records a warning to be reported in the Result.
+func (f *cfg) warnf(format string, args ...interface{}) {
+	f.warnings = append(f.warnings, fmt.Sprintf(format, args...))
+}
+
+// setOrigin records origin as the origin of the value at path.
+func (f *cfg) setOrigin(path, origin string) {
+	if f.origins == nil {
+		f.origins = make(map[string]string)
+	}
+	f.origins[path] = origin
+	f.recordKind(path, origin)
+}
+
+// recordOrigin records origin as the origin of the values of each of the
+// leaf keys of vals.
+func (f *cfg) recordOrigin(vals map[string]interface{}, origin string) {
+	var walk func(prefix string, m map[string]interface{})
+	walk = func(prefix string, m map[string]interface{}) {
+		for k, v := range m {
+			if child, ok := v.(map[string]interface{}); ok {
+				walk(prefix+k+".", child)
+				continue
+			}
+			f.setOrigin(prefix+k, origin)
+		}
+	}
+	walk("", vals)
+}
+
+// sourceName returns the name of src, which is its String method if it
+// has one, or else its type.
+func sourceName(src Source) string {
+	if s, ok := src.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", src)
+}
+
+// configHash returns a hex encoded hash of the values of the fields of cfg.
+func configHash(cfg interface{}, tag string) string {
+	hashes := hashFields(cfg, tag)
+	paths := make([]string, 0, len(hashes))
+	for path := range hashes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	h := sha256.New()
+	for _, path := range paths {
+		fmt.Fprintf(h, "%s=%x\n", path, hashes[path])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
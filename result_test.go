@@ -0,0 +1,112 @@
+package cfg
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_LoadResult(t *testing.T) {
+	type Config struct {
+		Host   string `cfg:"host"`
+		Logger struct {
+			LogLevel string `cfg:"log_level" path:"true"`
+		} `cfg:"logger"`
+	}
+
+	dir := filepath.Join("testdata", "valid")
+
+	var cfg Config
+	res, err := LoadResult(&cfg, File("server.yaml"), Dirs(dir), PathFields("host"))
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if want := []string{filepath.Join(dir, "server.yaml")}; !reflect.DeepEqual(want, res.Files) {
+		t.Errorf("want files %v, got %v", want, res.Files)
+	}
+	if res.Dir != dir {
+		t.Errorf("want dir %s, got %s", dir, res.Dir)
+	}
+
+	if want := filepath.Join(dir, "0.0.0.0"); cfg.Host != want {
+		t.Errorf("cfg.Host: want %s, got %s", want, cfg.Host)
+	}
+	if want := filepath.Join(dir, "debug"); cfg.Logger.LogLevel != want {
+		t.Errorf("cfg.Logger.LogLevel: want %s, got %s", want, cfg.Logger.LogLevel)
+	}
+
+	t.Run("error", func(t *testing.T) {
+		var cfg Config
+		res, err := LoadResult(&cfg, File("nope.yaml"), Dirs(dir))
+		if err == nil {
+			t.Fatal("expected err")
+		}
+		if res != nil {
+			t.Errorf("want nil result, got %+v", res)
+		}
+	})
+}
+
+func Test_LoadResult_Metadata(t *testing.T) {
+	type Config struct {
+		Version int `cfg:"version" cfgversion:"1"`
+		Server  struct {
+			Host string `cfg:"host"`
+			Port int    `cfg:"port" default:"80"`
+		} `cfg:"server"`
+		Level string `cfg:"level"`
+		Name  string `cfg:"name"`
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	writeFile(t, file, "server:\n  host: localhost\n  hots: typo\nlevel: info\nextra: 1\n")
+
+	setenv(t, "APP_LEVEL", "debug")
+
+	var cfg Config
+	res, err := LoadResult(&cfg, Dirs(dir), UseEnv("app"), WithSources(MapSource{"name": "app"}))
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if want := []string{"cfg.MapSource", "env"}; !reflect.DeepEqual(want, res.Sources) {
+		t.Errorf("want sources %v, got %v", want, res.Sources)
+	}
+	if want := []string{"extra", "server.hots"}; !reflect.DeepEqual(want, res.Unused) {
+		t.Errorf("want unused %v, got %v", want, res.Unused)
+	}
+	if want := []string{file + ": no version declared, expected version 1"}; !reflect.DeepEqual(want, res.Warnings) {
+		t.Errorf("want warnings %v, got %v", want, res.Warnings)
+	}
+
+	wantProvenance := map[string]string{
+		"server.host": file,
+		"server.port": "default",
+		"level":       "env",
+		"name":        "cfg.MapSource",
+	}
+	if !reflect.DeepEqual(wantProvenance, res.Provenance) {
+		t.Errorf("\nwant provenance %v\ngot  %v", wantProvenance, res.Provenance)
+	}
+	if want := []string{"server.port"}; !reflect.DeepEqual(want, res.Paths("default")) {
+		t.Errorf("want defaulted %v, got %v", want, res.Paths("default"))
+	}
+	if want := []string{"level"}; !reflect.DeepEqual(want, res.Paths("env")) {
+		t.Errorf("want from env %v, got %v", want, res.Paths("env"))
+	}
+	if got := res.Paths("flag"); got != nil {
+		t.Errorf("want no paths from flags, got %v", got)
+	}
+
+	if len(res.Hash) != 64 {
+		t.Errorf("want a sha256 hex hash, got %q", res.Hash)
+	}
+	if other, _ := LoadResult(&Config{}, Dirs(dir), UseEnv("app"), WithSources(MapSource{"name": "app"})); other.Hash != res.Hash {
+		t.Errorf("want equal hashes for equal configs, got %s and %s", res.Hash, other.Hash)
+	}
+	if other, _ := LoadResult(&Config{}, Dirs(dir)); other.Hash == res.Hash {
+		t.Error("want different hashes for different configs")
+	}
+}
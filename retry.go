@@ -0,0 +1,28 @@
+package cfg
+
+import (
+	"context"
+	"time"
+)
+
+// readRetry reads the values of src, retrying failed reads as configured
+// by the Retry option. Each failed attempt raises a warning.
+func (f *cfg) readRetry(ctx context.Context, src Source, name string) (map[string]interface{}, error) {
+	backoff := f.retryBackoff
+	for attempt := 1; ; attempt++ {
+		vals, err := src.Read(ctx)
+		if err == nil || attempt >= f.retryAttempts || ctx.Err() != nil {
+			return vals, err
+		}
+		f.warnf("%s: attempt %d of %d failed: %v", name, attempt, f.retryAttempts, err)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,83 @@
+package cfg
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_cfg_Load_Retry(t *testing.T) {
+	type Config struct {
+		Host string `cfg:"host"`
+	}
+
+	flaky := func(failures int) (Source, *int) {
+		calls := 0
+		return SourceFunc(func(ctx context.Context) (map[string]interface{}, error) {
+			calls++
+			if calls <= failures {
+				return nil, errors.New("connection refused")
+			}
+			return map[string]interface{}{"host": "localhost"}, nil
+		}), &calls
+	}
+
+	t.Run("succeeds after retries", func(t *testing.T) {
+		src, calls := flaky(2)
+
+		var cfg Config
+		res, err := LoadResult(&cfg, IgnoreFile(), WithSources(src), Retry(3, time.Millisecond))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Host != "localhost" {
+			t.Errorf("want host localhost, got %q", cfg.Host)
+		}
+		if *calls != 3 {
+			t.Errorf("want 3 calls, got %d", *calls)
+		}
+		if len(res.Warnings) != 2 || !strings.Contains(res.Warnings[0], "attempt 1 of 3 failed: connection refused") {
+			t.Errorf("unexpected warnings %v", res.Warnings)
+		}
+	})
+
+	t.Run("fails after attempts", func(t *testing.T) {
+		src, calls := flaky(3)
+
+		var cfg Config
+		err := Load(&cfg, IgnoreFile(), WithSources(src), Retry(3, time.Millisecond))
+		if err == nil || !strings.Contains(err.Error(), "connection refused") {
+			t.Fatalf("want connection refused err, got %v", err)
+		}
+		if *calls != 3 {
+			t.Errorf("want 3 calls, got %d", *calls)
+		}
+	})
+
+	t.Run("no retry by default", func(t *testing.T) {
+		src, calls := flaky(1)
+
+		var cfg Config
+		if err := Load(&cfg, IgnoreFile(), WithSources(src)); err == nil {
+			t.Fatal("expected err")
+		}
+		if *calls != 1 {
+			t.Errorf("want 1 call, got %d", *calls)
+		}
+	})
+
+	t.Run("stops at timeout", func(t *testing.T) {
+		src, calls := flaky(100)
+
+		var cfg Config
+		err := Load(&cfg, IgnoreFile(), WithSources(src), Retry(100, 20*time.Millisecond), LoadTimeout(30*time.Millisecond))
+		if err == nil {
+			t.Fatal("expected err")
+		}
+		if *calls != 2 {
+			t.Errorf("want 2 calls, got %d", *calls)
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package cfg
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// rolloutKey is the key of the rollout stanza of the values of the source
+// of a RolloutSource.
+const rolloutKey = "rollout"
+
+// RolloutSource is a source that applies the values of a remote source to
+// a fraction of the instances of a fleet only, e.g. to canary a new config
+// version, as given by the `rollout` stanza of the values:
+//
+//	rollout:
+//	  percent: 10%          # instances that apply these values, or 0.1
+//	  hosts: [web-1, web-2] # instances that apply them regardless of percent
+//	  seed: v42             # picks another set of instances, e.g. per version
+//	server:
+//	  port: 8080
+//
+// An instance is picked by a hash of its ID and the seed, so that it is
+// picked on every read while the seed is unchanged, and raising the percent
+// only adds instances. The values of a source without the stanza apply to
+// every instance, and so do those of the first read, as there are no
+// previous values to keep.
+//
+// The instances that are not picked keep the values of their last read that
+// applied, so that Reload reports no changes for them. The stanza is
+// removed from the values.
+//
+//	src := &cfg.RolloutSource{Source: &cfg.EtcdSource{Endpoint: endpoint, Key: "/myapp/config.yaml"}}
+//	changes, err := cfg.Reload(&conf, cfg.WithSources(src))
+type RolloutSource struct {
+	Source Source // source of the values.
+	ID     string // ID of the instance, e.g. a pod name. Defaults to the host name.
+
+	mu   sync.Mutex
+	prev map[string]interface{} // values of the last read that applied.
+}
+
+// rolloutStanza is the rollout stanza of the values of a RolloutSource.
+type rolloutStanza struct {
+	Percent *Percent `mapstructure:"percent"`
+	Hosts   []string `mapstructure:"hosts"`
+	Seed    string   `mapstructure:"seed"`
+}
+
+// Read returns the values of Source if they apply to the instance, or else
+// the values of the last read that applied.
+func (s *RolloutSource) Read(ctx context.Context) (map[string]interface{}, error) {
+	vals, err := s.Source.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var stanza *rolloutStanza
+	if raw, ok := vals[rolloutKey]; ok {
+		stanza, err = decodeRolloutStanza(raw)
+		if err != nil {
+			return nil, fmt.Errorf("rollout: %w", err)
+		}
+		delete(vals, rolloutKey)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.prev != nil && stanza != nil && !stanza.picks(s.id()) {
+		return deepCopyMap(s.prev), nil
+	}
+	s.prev = deepCopyMap(vals)
+	return vals, nil
+}
+
+// String returns the name of the source, e.g. in the Provenance of a
+// Result.
+func (s *RolloutSource) String() string {
+	return "rollout:" + sourceName(s.Source)
+}
+
+// id returns the ID of the instance.
+func (s *RolloutSource) id() string {
+	if s.ID != "" {
+		return s.ID
+	}
+	return hostname()
+}
+
+// decodeRolloutStanza decodes the rollout stanza raw.
+func decodeRolloutStanza(raw interface{}) (*rolloutStanza, error) {
+	var stanza rolloutStanza
+	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.TextUnmarshallerHookFunc(),
+			numberToPercentHookFunc(),
+		),
+		ErrorUnused:      true,
+		WeaklyTypedInput: true,
+		Result:           &stanza,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := dec.Decode(raw); err != nil {
+		return nil, err
+	}
+	return &stanza, nil
+}
+
+// picks reports whether the values of the stanza apply to the instance id.
+// Instances are picked by a bucket within [0, 1) derived from a hash of the
+// seed and id. Without a percent or hosts, every instance is picked.
+func (r *rolloutStanza) picks(id string) bool {
+	if r.Percent == nil && r.Hosts == nil {
+		return true
+	}
+	for _, host := range r.Hosts {
+		if host == id {
+			return true
+		}
+	}
+	if r.Percent == nil {
+		return false
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(r.Seed + "/" + id))
+	bucket := float64(h.Sum64()%10000) / 10000
+	return bucket < float64(*r.Percent)
+}
@@ -0,0 +1,121 @@
+package cfg
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func Test_rolloutStanza_picks(t *testing.T) {
+	percent := func(p Percent) *Percent { return &p }
+
+	t.Run("everyone", func(t *testing.T) {
+		if !(&rolloutStanza{}).picks("web-1") || !(&rolloutStanza{Percent: percent(1)}).picks("web-1") {
+			t.Errorf("want web-1 picked")
+		}
+		if (&rolloutStanza{Percent: percent(0)}).picks("web-1") {
+			t.Errorf("want web-1 not picked")
+		}
+	})
+
+	t.Run("hosts", func(t *testing.T) {
+		r := &rolloutStanza{Hosts: []string{"web-1"}}
+		if !r.picks("web-1") || r.picks("web-2") {
+			t.Errorf("want only web-1 picked")
+		}
+	})
+
+	t.Run("percent", func(t *testing.T) {
+		canary := &rolloutStanza{Percent: percent(0.1), Seed: "v42"}
+		wider := &rolloutStanza{Percent: percent(0.5), Seed: "v42"}
+		var picked int
+		for i := 0; i < 1000; i++ {
+			id := fmt.Sprintf("web-%d", i)
+			if canary.picks(id) {
+				picked++
+				if !wider.picks(id) {
+					t.Errorf("%s picked at 10%% but not at 50%%", id)
+				}
+			}
+		}
+		if picked < 50 || picked > 150 {
+			t.Errorf("want about 100 instances picked, got %d", picked)
+		}
+	})
+}
+
+func Test_RolloutSource(t *testing.T) {
+	type Config struct {
+		Level string `cfg:"level"`
+	}
+
+	var vals map[string]interface{}
+	src := &RolloutSource{
+		Source: SourceFunc(func(context.Context) (map[string]interface{}, error) {
+			return deepCopyMap(vals), nil
+		}),
+		ID: "web-1",
+	}
+
+	// the values of the first read apply, as there are no previous values.
+	vals = map[string]interface{}{"level": "info", "rollout": map[string]interface{}{"percent": "0%"}}
+	var cfg Config
+	if err := Load(&cfg, IgnoreFile(), WithSources(src)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if cfg.Level != "info" {
+		t.Fatalf("want level info, got %q", cfg.Level)
+	}
+
+	t.Run("not picked", func(t *testing.T) {
+		vals = map[string]interface{}{"level": "debug", "rollout": map[string]interface{}{"percent": 0.0, "hosts": []interface{}{"web-2"}}}
+		changes, err := Reload(&cfg, IgnoreFile(), WithSources(src))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if len(changes) != 0 || cfg.Level != "info" {
+			t.Errorf("want no changes, got %+v and level %q", changes, cfg.Level)
+		}
+	})
+
+	t.Run("picked", func(t *testing.T) {
+		vals = map[string]interface{}{"level": "debug", "rollout": map[string]interface{}{"hosts": []interface{}{"web-1"}}}
+		changes, err := Reload(&cfg, IgnoreFile(), WithSources(src))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if want := []Change{{Path: "level"}}; !reflect.DeepEqual(want, changes) {
+			t.Errorf("\nwant %+v\ngot  %+v", want, changes)
+		}
+		if cfg.Level != "debug" {
+			t.Errorf("want level debug, got %q", cfg.Level)
+		}
+	})
+
+	t.Run("no stanza", func(t *testing.T) {
+		vals = map[string]interface{}{"level": "warn"}
+		if _, err := Reload(&cfg, IgnoreFile(), WithSources(src)); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Level != "warn" {
+			t.Errorf("want level warn, got %q", cfg.Level)
+		}
+	})
+
+	t.Run("invalid stanza", func(t *testing.T) {
+		for _, stanza := range []interface{}{
+			map[string]interface{}{"percent": "150%"},
+			map[string]interface{}{"percent": 10},
+			map[string]interface{}{"share": "10%"},
+		} {
+			vals = map[string]interface{}{"level": "error", "rollout": stanza}
+			if _, err := Reload(&cfg, IgnoreFile(), WithSources(src)); err == nil {
+				t.Errorf("%v: expected err", stanza)
+			}
+		}
+		if cfg.Level != "warn" {
+			t.Errorf("want level warn, got %q", cfg.Level)
+		}
+	})
+}
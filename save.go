@@ -0,0 +1,249 @@
+package cfg
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml"
+	"gopkg.in/yaml.v3"
+)
+
+// SaveOverrides writes the fields of cfg whose values differ from those of
+// the config files to the file at path, e.g. so that interactive tools can
+// persist the adjustments a user made through env vars, flags or
+// overrides. options must be those cfg was loaded with.
+//
+// The config files other than path are loaded again without env vars,
+// flags, overrides and sources to find the values cfg is compared with, so
+// that the values saved to path earlier are kept unless they changed. Fields tagged
+// `secret:"true"`, or whose ancestors are, are never written. The format
+// of the file is that of its extension, one of `.yaml`, `.yml`, `.json`
+// or `.toml`, and the file is replaced if it exists. The comments and the
+// order of the keys of an existing YAML file are kept.
+func SaveOverrides(cfg interface{}, path string, options ...Option) error {
+	conf := defaultCfg()
+	for _, opt := range options {
+		opt(conf)
+	}
+	if !isStructPtr(cfg) {
+		return fmt.Errorf("cfg must be a pointer to a struct")
+	}
+
+	ext := filepath.Ext(path)
+	encode, err := lookupEncoder(ext)
+	if err != nil {
+		return err
+	}
+
+	// path itself may be one of the config files: it must not be part of
+	// the values cfg is compared with for its values to be saved again.
+	if conf.skipFile, err = filepath.Abs(path); err != nil {
+		return err
+	}
+	base, err := conf.loadFiles(reflect.TypeOf(cfg).Elem())
+	if err != nil {
+		return err
+	}
+
+	changes, err := conf.diff(base, cfg)
+	if err != nil {
+		return err
+	}
+
+	vals := make(map[string]interface{})
+	for _, c := range changes {
+		// the elements of slices are written along with their slice.
+		p := c.Path
+		if i := strings.Index(p, "["); i >= 0 {
+			p = p[:i]
+		}
+		field := lookupField(cfg, p, conf.tag)
+		if field == nil || isSecretField(field) {
+			continue
+		}
+		if err := setPath(vals, strings.Split(p, "."), conf.plainValue(field.v)); err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+	}
+
+	// comments and the order of keys of existing yaml files are kept.
+	if old, err := os.ReadFile(path); err == nil && (ext == ".yaml" || ext == ".yml") {
+		encode = func(v interface{}) ([]byte, error) { return marshalYAMLOver(old, v) }
+	}
+
+	data, err := encode(vals)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0o600)
+}
+
+// loadFiles returns a new value of type t loaded from the config files
+// alone, with its defaults set. Fields that fail to process are left as
+// they were decoded.
+func (f *cfg) loadFiles(t reflect.Type) (interface{}, error) {
+	base := *f
+	base.useEnv = false
+	base.flags = nil
+	base.overrides = nil
+	base.sources = nil
+	base.reader = nil
+	base.inherited = false
+	base.ignoreFile = false
+	base.frozen = false
+	base.sections = sectionCopies(registeredSections())
+
+	v := reflect.New(t).Interface()
+	err := base.Load(v)
+
+	var errs fieldErrors
+	switch {
+	case err == nil, errors.As(err, &errs):
+	case errors.Is(err, ErrFileNotFound):
+		_ = base.processCfg(v)
+	default:
+		return nil, err
+	}
+	return v, nil
+}
+
+// lookupEncoder returns an encoder of values for the file extension ext.
+func lookupEncoder(ext string) (func(v interface{}) ([]byte, error), error) {
+	switch ext {
+	case ".yaml", ".yml":
+		return yaml.Marshal, nil
+	case ".json":
+		return func(v interface{}) ([]byte, error) { return json.MarshalIndent(v, "", "  ") }, nil
+	case ".toml":
+		return func(v interface{}) ([]byte, error) {
+			tree, err := toml.TreeFromMap(v.(map[string]interface{}))
+			if err != nil {
+				return nil, err
+			}
+			return []byte(tree.String()), nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported file extension %q", ext)
+	}
+}
+
+// isSecretField reports whether field or any of its ancestors is tagged
+// with `secret:"true"`.
+func isSecretField(field *field) bool {
+	for f := field; f != nil; f = f.parent {
+		if f.secret {
+			return true
+		}
+	}
+	return false
+}
+
+// plainValue converts v to a value that encodes the same way as it is
+// decoded, i.e. structs to maps keyed by the names of their fields and
+// text marshalers, times, durations and regexps to strings. The fields of
+// structs tagged `secret:"true"` are left out.
+func (f *cfg) plainValue(v reflect.Value) interface{} {
+	return f.plain(v, false)
+}
+
+// plain is plainValue, with the fields tagged `secret:"true"` included if
+// secrets is set.
+func (f *cfg) plain(v reflect.Value, secrets bool) interface{} {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.CanInterface() {
+		return nil
+	}
+
+	switch x := v.Interface().(type) {
+	case time.Time:
+		return x.Format(f.timeLayout)
+	case time.Duration:
+		return x.String()
+	case regexp.Regexp:
+		return x.String()
+	case encoding.TextMarshaler:
+		if text, err := x.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		m := make(map[string]interface{})
+		for i := 0; i < v.NumField(); i++ {
+			sf := v.Type().Field(i)
+			if sf.PkgPath != "" {
+				continue
+			}
+			st := parseTag(sf.Tag, f.tag)
+			if st.secret && !secrets {
+				continue
+			}
+			name := st.altName
+			if name == "" {
+				name = sf.Name
+			}
+			m[name] = f.plain(v.Field(i), secrets)
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		s := make([]interface{}, v.Len())
+		for i := range s {
+			s[i] = f.plain(v.Index(i), secrets)
+		}
+		return s
+	case reflect.Map:
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(iter.Key().Interface())] = f.plain(iter.Value(), secrets)
+		}
+		return m
+	default:
+		return v.Interface()
+	}
+}
+
+// writeFileAtomic writes data to file through a temporary file in the same
+// dir so that readers never observe a partly written file. The dir of file
+// is created if needed.
+func writeFileAtomic(file string, data []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
@@ -0,0 +1,151 @@
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func Test_SaveOverrides(t *testing.T) {
+	type Server struct {
+		Host string `cfg:"host"`
+		Port int    `cfg:"port"`
+	}
+	type Config struct {
+		Server   Server        `cfg:"server"`
+		Timeout  time.Duration `cfg:"timeout" default:"5s"`
+		Replicas []Server      `cfg:"replicas"`
+		Password string        `cfg:"password" secret:"true"`
+		Level    string        `cfg:"level" default:"info"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "server:\n  host: localhost\n  port: 80\nreplicas:\n  - host: a\n")
+
+	setenv(t, "SERVER_PORT", "8080")
+	setenv(t, "PASSWORD", "hunter2")
+	options := []Option{
+		Dirs(dir),
+		UseEnv(""),
+		Override(map[string]interface{}{"timeout": "1m", "replicas": `[{"host":"a"},{"host":"b","port":1}]`}),
+	}
+
+	var cfg Config
+	if err := Load(&cfg, options...); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	overridesDir := t.TempDir()
+	file := filepath.Join(overridesDir, "config.yaml")
+	if err := SaveOverrides(&cfg, file, options...); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	got := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	want := map[string]interface{}{
+		"server":  map[string]interface{}{"port": 8080},
+		"timeout": "1m0s",
+		"replicas": []interface{}{
+			map[string]interface{}{"host": "a", "port": 0},
+			map[string]interface{}{"host": "b", "port": 1},
+		},
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	t.Run("saved overrides load back", func(t *testing.T) {
+		var saved Config
+		if err := Load(&saved, Dirs(dir, overridesDir)); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		cfg.Password = ""
+		if !reflect.DeepEqual(cfg, saved) {
+			t.Errorf("want %+v, got %+v", cfg, saved)
+		}
+	})
+
+	t.Run("comments of existing file are kept", func(t *testing.T) {
+		writeFile(t, file, "# set by ops\ntimeout: 30s # during the migration\n")
+		if err := SaveOverrides(&cfg, file, options...); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if want := "# set by ops\ntimeout: 1m0s # during the migration\n"; !strings.HasPrefix(string(data), want) {
+			t.Errorf("want file starting with %q, got %s", want, data)
+		}
+	})
+
+	t.Run("unsupported extension", func(t *testing.T) {
+		if err := SaveOverrides(&cfg, filepath.Join(overridesDir, "config.ini"), options...); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+}
+
+func Test_SaveOverrides_NoFile(t *testing.T) {
+	type Config struct {
+		Addr  string `cfg:"addr" default:":8080"`
+		Debug bool   `cfg:"debug"`
+	}
+
+	dir := t.TempDir()
+	cfg := Config{Addr: ":8080", Debug: true}
+
+	file := filepath.Join(dir, "overrides.json")
+	if err := SaveOverrides(&cfg, file, Dirs(dir)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if want := "{\n  \"debug\": true\n}"; string(data) != want {
+		t.Errorf("want %s, got %s", want, data)
+	}
+}
+
+func Test_SaveOverrides_ConfigFile(t *testing.T) {
+	type Config struct {
+		X int `cfg:"x"`
+		Y int `cfg:"y"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "x: 1\n")
+	writeFile(t, filepath.Join(dir, "override.yaml"), "x: 5\n")
+	setenv(t, "Y", "7")
+	options := []Option{Dirs(dir), Files(Required("config.yaml"), Optional("override.yaml")), UseEnv("")}
+
+	var cfg Config
+	if err := Load(&cfg, options...); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	// the values saved to the file earlier are kept.
+	if err := SaveOverrides(&cfg, filepath.Join(dir, "override.yaml"), options...); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	var saved Config
+	if err := Load(&saved, Dirs(dir), Files(Required("config.yaml"), Optional("override.yaml"))); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if want := (Config{X: 5, Y: 7}); saved != want {
+		t.Errorf("want %+v, got %+v", want, saved)
+	}
+}
@@ -0,0 +1,56 @@
+package cfg
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// ScopesKey is the top-level key of a config file that holds the subtrees
+// of each scope, and the name of the directory next to the config file that
+// holds the files of each scope.
+const ScopesKey = "scopes"
+
+// applyScope overlays the values of the configured scope over the values
+// of file. The scope's values are taken from the scope's subtree in vals
+// and from the scope's file, in that order.
+//
+// The scopes subtree is removed from vals so that it is not decoded,
+// whether or not a scope is configured.
+func (f *cfg) applyScope(vals map[string]interface{}, file string) error {
+	scopes, hasScopes := vals[ScopesKey]
+	delete(vals, ScopesKey)
+	if f.scope == "" {
+		return nil
+	}
+
+	if hasScopes {
+		m, ok := scopes.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s must be a map of scope names to values", ScopesKey)
+		}
+		if subtree, ok := m[f.scope]; ok {
+			overlay, ok := subtree.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s.%s must be a map", ScopesKey, f.scope)
+			}
+			mergeMaps(vals, overlay)
+			f.scopeFound = true
+		}
+	}
+
+	scopeFile := joinFile(dirOf(file), ScopesKey, f.scope+filepath.Ext(file))
+	exists, err := f.fileExists(scopeFile)
+	if err != nil || !exists {
+		return err
+	}
+
+	overlay := make(map[string]interface{})
+	if err := f.decodeFile(overlay, scopeFile); err != nil {
+		return err
+	}
+	mergeMaps(vals, overlay)
+	f.files = append(f.files, scopeFile)
+	f.scopeFound = true
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_cfg_Load_Scope(t *testing.T) {
+	type Config struct {
+		Server struct {
+			Host string `cfg:"host"`
+			Port int    `cfg:"port"`
+		} `cfg:"server"`
+		Name string `cfg:"name"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), `
+name: base
+server:
+  host: 0.0.0.0
+  port: 80
+scopes:
+  tenant-42:
+    server:
+      port: 8042
+  tenant-7:
+    name: seven
+`)
+	if err := os.Mkdir(filepath.Join(dir, ScopesKey), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, ScopesKey, "tenant-42.yaml"), "server:\n  host: 10.0.0.42\n")
+
+	t.Run("subtree and file", func(t *testing.T) {
+		var cfg Config
+		res, err := LoadResult(&cfg, Dirs(dir), Scope("tenant-42"))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		var want Config
+		want.Name = "base"
+		want.Server.Host = "10.0.0.42"
+		want.Server.Port = 8042
+		if !reflect.DeepEqual(want, cfg) {
+			t.Errorf("\nwant %+v\ngot  %+v", want, cfg)
+		}
+
+		wantFiles := []string{filepath.Join(dir, "config.yaml"), filepath.Join(dir, ScopesKey, "tenant-42.yaml")}
+		if !reflect.DeepEqual(wantFiles, res.Files) {
+			t.Errorf("want files %v, got %v", wantFiles, res.Files)
+		}
+	})
+
+	t.Run("subtree only", func(t *testing.T) {
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir), Scope("tenant-7")); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Name != "seven" || cfg.Server.Host != "0.0.0.0" || cfg.Server.Port != 80 {
+			t.Errorf("unexpected cfg %+v", cfg)
+		}
+	})
+
+	t.Run("unknown scope uses base", func(t *testing.T) {
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir), Scope("tenant-1"), UseStrict()); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Name != "base" || cfg.Server.Port != 80 {
+			t.Errorf("unexpected cfg %+v", cfg)
+		}
+	})
+
+	t.Run("no scope", func(t *testing.T) {
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir), UseStrict()); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Name != "base" || cfg.Server.Port != 80 {
+			t.Errorf("unexpected cfg %+v", cfg)
+		}
+	})
+
+	t.Run("invalid scopes", func(t *testing.T) {
+		bad := t.TempDir()
+		writeFile(t, filepath.Join(bad, "config.yaml"), "scopes: [a, b]\n")
+
+		var cfg Config
+		if err := Load(&cfg, Dirs(bad), Scope("a")); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+}
+
+func Test_mergeMaps(t *testing.T) {
+	dst := map[string]interface{}{
+		"a": 1,
+		"b": map[string]interface{}{"c": 2, "d": 3},
+		"e": map[string]interface{}{"f": 4},
+	}
+	src := map[string]interface{}{
+		"a": 10,
+		"b": map[string]interface{}{"c": 20},
+		"e": "replaced",
+		"g": 5,
+	}
+	mergeMaps(dst, src)
+
+	want := map[string]interface{}{
+		"a": 10,
+		"b": map[string]interface{}{"c": 20, "d": 3},
+		"e": "replaced",
+		"g": 5,
+	}
+	if !reflect.DeepEqual(want, dst) {
+		t.Errorf("\nwant %v\ngot  %v", want, dst)
+	}
+}
@@ -0,0 +1,195 @@
+package cfg
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+var (
+	sectionsMu sync.RWMutex
+	sections   = map[string]interface{}{}
+)
+
+// RegisterSection registers the config struct of a plugin or extension
+// under the top-level key name. cfg must be a pointer to a struct.
+// Registering a name that is already registered replaces the previous
+// section.
+//
+// Every subsequent Load decodes the section's values from the config
+// files into cfg and processes its fields like those of the config
+// struct given to Load, so that defaults, env vars and validations apply:
+//
+//	var metricsCfg struct {
+//	  Addr string `cfg:"addr" default:":9090"`
+//	}
+//
+//	func init() {
+//	  cfg.RegisterSection("metrics", &metricsCfg)
+//	}
+//
+// Section keys are removed from the config files before they are decoded
+// into the config struct given to Load, so the struct must not have a
+// field with the same name. As sections are shared, configs that share
+// registered sections must not be loaded concurrently.
+func RegisterSection(name string, cfg interface{}) {
+	sectionsMu.Lock()
+	defer sectionsMu.Unlock()
+	sections[name] = cfg
+}
+
+// registeredSections returns a snapshot of the registered sections.
+func registeredSections() map[string]interface{} {
+	sectionsMu.RLock()
+	defer sectionsMu.RUnlock()
+	snapshot := make(map[string]interface{}, len(sections))
+	for name, cfg := range sections {
+		snapshot[name] = cfg
+	}
+	return snapshot
+}
+
+// sectionCopies returns a new struct of the same type as each of sections,
+// so that a config can be loaded without changing the sections themselves.
+// Sections that are not pointers to structs are left as they are.
+func sectionCopies(sections map[string]interface{}) map[string]interface{} {
+	copies := make(map[string]interface{}, len(sections))
+	for name, cfg := range sections {
+		if !isStructPtr(cfg) {
+			copies[name] = cfg
+			continue
+		}
+		copies[name] = reflect.New(reflect.TypeOf(cfg).Elem()).Interface()
+	}
+	return copies
+}
+
+// checkSections returns an error if any of the registered sections is not
+// a pointer to a struct.
+func (f *cfg) checkSections() error {
+	for name, cfg := range f.sections {
+		if !isStructPtr(cfg) {
+			return fmt.Errorf("section %q must be a pointer to a struct", name)
+		}
+	}
+	return nil
+}
+
+// decodeSections decodes the values of each registered section in vals
+// into the section's struct and removes them from vals.
+func (f *cfg) decodeSections(vals map[string]interface{}) error {
+	for name, cfg := range f.sections {
+		sectionVals, ok := vals[name]
+		if !ok {
+			continue
+		}
+		delete(vals, name)
+		if err := f.checkStrictTypes(sectionVals, reflect.TypeOf(cfg), name); err != nil {
+			return fmt.Errorf("section %q: %w", name, err)
+		}
+		var md mapstructure.Metadata
+		if err := f.decodeValue(sectionVals, cfg, &md); err != nil {
+			return fmt.Errorf("section %q: %w", name, err)
+		}
+		for _, key := range md.Unused {
+			f.unused = append(f.unused, name+"."+key)
+		}
+	}
+	return nil
+}
+
+// sectionRoots returns the root field of each registered section, in
+// order of name. The fields of a section are named after the section.
+func (f *cfg) sectionRoots() []*field {
+	names := make([]string, 0, len(f.sections))
+	for name := range f.sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	roots := make([]*field, 0, len(names))
+	for _, name := range names {
+		v := reflect.ValueOf(f.sections[name]).Elem()
+		root := &field{v: v, t: v.Type(), sliceIdx: -1}
+		root.altName = name
+		roots = append(roots, root)
+	}
+	return roots
+}
+
+// SectionDecoder is implemented by the fields of sections whose shape is
+// too dynamic for a static struct, e.g. a set of routes keyed by name.
+// Decode receives the raw subtree of the field from each config file and
+// source that sets it, in the order that they are loaded, and so must merge
+// it over the values decoded so far. An env var or default sets the field
+// from a JSON object.
+//
+//	type Routes struct {
+//	  byName map[string]interface{}
+//	}
+//
+//	func (r *Routes) Decode(vals map[string]interface{}) error {
+//	  if r.byName == nil {
+//	    r.byName = make(map[string]interface{})
+//	  }
+//	  for name, route := range vals {
+//	    r.byName[name] = route
+//	  }
+//	  return nil
+//	}
+//
+// The provenance of each value of the subtree is recorded under its path,
+// e.g. `routes.api.url`.
+type SectionDecoder interface {
+	Decode(vals map[string]interface{}) error
+}
+
+var sectionDecoderType = reflect.TypeOf((*SectionDecoder)(nil)).Elem()
+
+// sectionDecoder returns v as a SectionDecoder if its address implements
+// the interface.
+func sectionDecoder(v reflect.Value) (SectionDecoder, bool) {
+	if !v.CanAddr() {
+		return nil, false
+	}
+	sd, ok := v.Addr().Interface().(SectionDecoder)
+	return sd, ok
+}
+
+// sectionDecoderHookFunc returns a DecodeHookFunc that passes maps to the
+// Decode method of the SectionDecoder they are decoded into. The decoded
+// value is returned in place of the map so that the decoder leaves it as
+// it is.
+func sectionDecoderHookFunc() mapstructure.DecodeHookFuncValue {
+	return func(from, to reflect.Value) (interface{}, error) {
+		m, ok := from.Interface().(map[string]interface{})
+		if !ok {
+			return from.Interface(), nil
+		}
+		sd, ok := sectionDecoder(to)
+		if !ok {
+			return from.Interface(), nil
+		}
+		if err := sd.Decode(m); err != nil {
+			return nil, err
+		}
+		return to.Interface(), nil
+	}
+}
+
+// setSection sets the SectionDecoder sd from val, the JSON object or
+// composite literal of an env var or default.
+func setSection(sd SectionDecoder, val string) error {
+	tree, err := parseEnvComposite(val)
+	if err != nil {
+		return err
+	}
+	m, ok := tree.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected an object, got %s", jsonType(tree))
+	}
+	return sd.Decode(m)
+}
@@ -0,0 +1,182 @@
+package cfg
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type metricsSection struct {
+	Addr    string `cfg:"addr" default:":9090"`
+	Path    string `cfg:"path" validate:"required"`
+	Enabled bool   `cfg:"enabled"`
+}
+
+type limitsSection struct {
+	Min int `cfg:"min"`
+	Max int `cfg:"max"`
+}
+
+func (l *limitsSection) Validate() error {
+	if l.Min > l.Max {
+		return errors.New("min must not exceed max")
+	}
+	return nil
+}
+
+func registerTestSection(t *testing.T, name string, cfg interface{}) {
+	t.Helper()
+	RegisterSection(name, cfg)
+	t.Cleanup(func() {
+		sectionsMu.Lock()
+		defer sectionsMu.Unlock()
+		delete(sections, name)
+	})
+}
+
+func Test_cfg_Load_Sections(t *testing.T) {
+	type Config struct {
+		Host string `cfg:"host"`
+	}
+
+	t.Run("decodes, defaults and validates", func(t *testing.T) {
+		var metrics metricsSection
+		registerTestSection(t, "metrics", &metrics)
+		setenv(t, "APP_METRICS_ENABLED", "true")
+
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "config.yaml"), "host: localhost\nmetrics:\n  path: /metrics\n")
+
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir), UseStrict(), UseEnv("app")); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		if cfg.Host != "localhost" {
+			t.Errorf("want host localhost, got %q", cfg.Host)
+		}
+		want := metricsSection{Addr: ":9090", Path: "/metrics", Enabled: true}
+		if metrics != want {
+			t.Errorf("\nwant %+v\ngot  %+v", want, metrics)
+		}
+	})
+
+	t.Run("field errors are named after the section", func(t *testing.T) {
+		var metrics metricsSection
+		registerTestSection(t, "metrics", &metrics)
+
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "config.yaml"), "host: localhost\n")
+
+		var cfg Config
+		err := Load(&cfg, Dirs(dir))
+		if err == nil || !strings.Contains(err.Error(), "metrics.path: required validation failed") {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+
+	t.Run("section validator", func(t *testing.T) {
+		var limits limitsSection
+		registerTestSection(t, "limits", &limits)
+
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "config.yaml"), "limits:\n  min: 10\n  max: 1\n")
+
+		var cfg Config
+		err := Load(&cfg, Dirs(dir))
+		if err == nil || !strings.Contains(err.Error(), "limits: min must not exceed max") {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+
+	t.Run("invalid section", func(t *testing.T) {
+		registerTestSection(t, "metrics", metricsSection{})
+
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "config.yaml"), "host: localhost\n")
+
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir)); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+}
+
+type routesSection struct {
+	byName map[string]interface{}
+}
+
+func (r *routesSection) Decode(vals map[string]interface{}) error {
+	if r.byName == nil {
+		r.byName = make(map[string]interface{})
+	}
+	for name, route := range vals {
+		if _, ok := route.(map[string]interface{}); !ok {
+			return fmt.Errorf("route %s must be an object", name)
+		}
+		r.byName[name] = route
+	}
+	return nil
+}
+
+func Test_cfg_Load_SectionDecoder(t *testing.T) {
+	type Config struct {
+		Host   string         `cfg:"host"`
+		Routes routesSection  `cfg:"routes"`
+		Extra  *routesSection `cfg:"extra"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "host: localhost\nroutes:\n  api:\n    url: http://api\n  web:\n    url: http://web\n")
+	writeFile(t, filepath.Join(dir, "override.yaml"), "routes:\n  web:\n    url: http://web2\n")
+
+	t.Run("files", func(t *testing.T) {
+		var cfg Config
+		res, err := LoadResult(&cfg, Files(Required("config.yaml"), Required("override.yaml")), Dirs(dir), UseStrict())
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		want := map[string]interface{}{
+			"api": map[string]interface{}{"url": "http://api"},
+			"web": map[string]interface{}{"url": "http://web2"},
+		}
+		if !reflect.DeepEqual(want, cfg.Routes.byName) {
+			t.Errorf("want %v, got %v", want, cfg.Routes.byName)
+		}
+		if cfg.Extra != nil {
+			t.Errorf("want nil extra, got %+v", cfg.Extra)
+		}
+		if got, want := res.Provenance["routes.web.url"], filepath.Join(dir, "override.yaml"); got != want {
+			t.Errorf("want provenance %s, got %s", want, got)
+		}
+		if got, want := res.Provenance["routes.api.url"], filepath.Join(dir, "config.yaml"); got != want {
+			t.Errorf("want provenance %s, got %s", want, got)
+		}
+	})
+
+	t.Run("env", func(t *testing.T) {
+		setenv(t, "EXTRA", `{"admin": {"url": "http://admin"}}`)
+
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir), UseEnv("")); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		want := map[string]interface{}{"admin": map[string]interface{}{"url": "http://admin"}}
+		if cfg.Extra == nil || !reflect.DeepEqual(want, cfg.Extra.byName) {
+			t.Errorf("want %v, got %+v", want, cfg.Extra)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		writeFile(t, filepath.Join(dir, "bad.yaml"), "routes:\n  api: http://api\n")
+
+		var cfg Config
+		err := Load(&cfg, File("bad.yaml"), Dirs(dir))
+		if err == nil || !strings.Contains(err.Error(), "route api must be an object") {
+			t.Fatalf("want decode err, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,239 @@
+package cfg
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// Source provides config values from somewhere other than a config file,
+// such as a database, an API or memory.
+type Source interface {
+	// Read returns the raw values of the source, keyed like the values of
+	// a config file.
+	Read(ctx context.Context) (map[string]interface{}, error)
+}
+
+// WritableSource is a source that can write values back, e.g. for admin
+// tooling that edits live config.
+type WritableSource interface {
+	Source
+	// Put sets the value at the dotted path, failing with an error
+	// wrapping ErrConflict if the value changed since it was read.
+	Put(ctx context.Context, path string, value interface{}) error
+}
+
+// SourceFunc is an adapter to allow the use of ordinary functions as
+// sources.
+type SourceFunc func(ctx context.Context) (map[string]interface{}, error)
+
+// Read calls fn(ctx).
+func (fn SourceFunc) Read(ctx context.Context) (map[string]interface{}, error) {
+	return fn(ctx)
+}
+
+// MapSource is a source of in-memory values.
+type MapSource map[string]interface{}
+
+// Read returns a copy of the values of the map, so that decoding does not
+// alter it.
+func (m MapSource) Read(context.Context) (map[string]interface{}, error) {
+	return deepCopyMap(m), nil
+}
+
+// deepCopyMap returns a copy of m and of the maps and slices nested in it.
+func deepCopyMap(m map[string]interface{}) map[string]interface{} {
+	cp := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		cp[k] = deepCopyValue(v)
+	}
+	return cp
+}
+
+func deepCopyValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return deepCopyMap(v)
+	case []interface{}:
+		cp := make([]interface{}, len(v))
+		for i := range v {
+			cp[i] = deepCopyValue(v[i])
+		}
+		return cp
+	default:
+		return v
+	}
+}
+
+// httpClientKey is the context key of the client of the WithHTTPClient
+// option.
+type httpClientKey struct{}
+
+// contextClient returns the client of the WithHTTPClient option that ctx
+// carries, or http.DefaultClient if there is none.
+func contextClient(ctx context.Context) *http.Client {
+	if client, ok := ctx.Value(httpClientKey{}).(*http.Client); ok {
+		return client
+	}
+	return http.DefaultClient
+}
+
+// endpointClient returns the client of the WithHTTPClient option that ctx
+// carries if custom is set, i.e. if requests are sent to an endpoint that
+// the caller configured, or else http.DefaultClient, so that the client of
+// the option, and any credentials it adds, is not used with the public
+// endpoints of cloud providers.
+func endpointClient(ctx context.Context, custom bool) *http.Client {
+	if custom {
+		return contextClient(ctx)
+	}
+	return http.DefaultClient
+}
+
+// metadataClient returns client, or else a client that doesn't use the
+// proxy of the `HTTP_PROXY` env var, since metadata servers are link-local
+// and must not be requested through a proxy. Requests to metadata servers,
+// which hand out credentials, never use the client of the WithHTTPClient
+// option nor the Proxy of a source.
+func metadataClient(client *http.Client) *http.Client {
+	if client == nil {
+		return directClient
+	}
+	return client
+}
+
+// directClient is a client like http.DefaultClient that uses no proxy.
+var directClient = func() *http.Client {
+	//nolint:forcetypeassert
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	return &http.Client{Transport: transport}
+}()
+
+// sourceClient returns client, or else the client of endpointClient, set
+// to send requests through proxy if it is not empty, in place of the
+// proxy of the `HTTP_PROXY`, `HTTPS_PROXY` and `NO_PROXY` env vars, and
+// with the TLS config tlsConf if it is not nil.
+func sourceClient(ctx context.Context, client *http.Client, custom bool, proxy string, tlsConf *tls.Config) (*http.Client, error) {
+	if client == nil {
+		client = endpointClient(ctx, custom)
+	}
+	if proxy == "" && tlsConf == nil {
+		return client, nil
+	}
+
+	key := sourceClientKey{client: client, proxy: proxy, tls: tlsConf}
+	if c, ok := sourceClients.Load(key); ok {
+		return c.(*http.Client), nil
+	}
+	var proxyURL *url.URL
+	if proxy != "" {
+		u, err := url.Parse(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy: %w", err)
+		}
+		proxyURL = u
+	}
+	transport, err := configureTransport(client.Transport, func(t *http.Transport) {
+		if proxyURL != nil {
+			t.Proxy = http.ProxyURL(proxyURL)
+		}
+		if tlsConf != nil {
+			t.TLSClientConfig = tlsConf
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	c := *client
+	c.Transport = transport
+	actual, _ := sourceClients.LoadOrStore(key, &c)
+	return actual.(*http.Client), nil
+}
+
+// sourceClientKey is the key of the clients of sourceClient.
+type sourceClientKey struct {
+	client *http.Client
+	proxy  string
+	tls    *tls.Config
+}
+
+// sourceClients caches the clients of sourceClient, so that each keeps
+// its idle connections across loads.
+var sourceClients sync.Map
+
+// configureTransport returns a copy of rt, or of http.DefaultTransport if
+// it is nil, with its *http.Transport set by set. The transports built by
+// HTTPClient are copied along with the transport they wrap, while other
+// transports can't be configured.
+func configureTransport(rt http.RoundTripper, set func(*http.Transport)) (http.RoundTripper, error) {
+	switch t := rt.(type) {
+	case nil:
+		return configureTransport(http.DefaultTransport, set)
+	case *http.Transport:
+		t = t.Clone()
+		set(t)
+		return t, nil
+	case *retryTransport:
+		next, err := configureTransport(t.next, set)
+		if err != nil {
+			return nil, err
+		}
+		return &retryTransport{next: next, retries: t.retries, backoff: t.backoff}, nil
+	case *headerTransport:
+		next, err := configureTransport(t.next, set)
+		if err != nil {
+			return nil, err
+		}
+		return &headerTransport{next: next, headers: t.headers}, nil
+	default:
+		return nil, fmt.Errorf("unable to configure transport %T", rt)
+	}
+}
+
+// maxFileSizeKey is the context key of the size of the MaxFileSize
+// option.
+type maxFileSizeKey struct{}
+
+// contextMaxFileSize returns the size of the MaxFileSize option that ctx
+// carries, or 0 if there is none.
+func contextMaxFileSize(ctx context.Context) int64 {
+	size, _ := ctx.Value(maxFileSizeKey{}).(int64)
+	return size
+}
+
+// doRequest sends req with client, or the client of the WithHTTPClient
+// option if it is nil, and decodes the JSON response into out, or copies it if out is a
+// *bytes.Buffer, up to the size of the MaxFileSize option. Responses other
+// than 200 OK are returned as errors.
+func doRequest(client *http.Client, req *http.Request, out interface{}) error {
+	if client == nil {
+		client = contextClient(req.Context())
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	var body io.Reader = resp.Body
+	if max := contextMaxFileSize(req.Context()); max > 0 {
+		body = limitReader(body, max)
+	}
+	if buf, ok := out.(*bytes.Buffer); ok {
+		_, err := io.Copy(buf, body)
+		return err
+	}
+	return json.NewDecoder(body).Decode(out)
+}
@@ -0,0 +1,147 @@
+package cfg
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_cfg_Load_WithSources(t *testing.T) {
+	type Config struct {
+		Host  string `cfg:"host"`
+		Port  int    `cfg:"port" default:"80"`
+		Level string `cfg:"level" validate:"required"`
+		DB    struct {
+			Name string `cfg:"name"`
+			User string `cfg:"user"`
+		} `cfg:"db"`
+	}
+
+	t.Run("sources override files", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "config.yaml"), "host: localhost\nlevel: info\ndb:\n  name: app\n")
+
+		api := SourceFunc(func(context.Context) (map[string]interface{}, error) {
+			return map[string]interface{}{"level": "debug", "db": map[string]interface{}{"user": "admin"}}, nil
+		})
+
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir), WithSources(MapSource{"host": "example.com"}, api)); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		var want Config
+		want.Host = "example.com"
+		want.Port = 80
+		want.Level = "debug"
+		want.DB.Name = "app"
+		want.DB.User = "admin"
+		if !reflect.DeepEqual(want, cfg) {
+			t.Errorf("\nwant %+v\ngot  %+v", want, cfg)
+		}
+	})
+
+	t.Run("sources without file", func(t *testing.T) {
+		var cfg Config
+		err := Load(&cfg, Dirs(t.TempDir()), WithSources(MapSource{"host": "example.com"}))
+		if err == nil || err.Error() != "level: required validation failed" {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Host != "example.com" || cfg.Port != 80 {
+			t.Errorf("unexpected cfg %+v", cfg)
+		}
+	})
+
+	t.Run("source error", func(t *testing.T) {
+		boom := errors.New("boom")
+		src := SourceFunc(func(context.Context) (map[string]interface{}, error) { return nil, boom })
+
+		var cfg Config
+		err := Load(&cfg, IgnoreFile(), WithSources(src))
+		if !errors.Is(err, boom) {
+			t.Fatalf("want boom, got %v", err)
+		}
+	})
+}
+
+func Test_MapSource_Read(t *testing.T) {
+	src := MapSource{"db": map[string]interface{}{"name": "app"}, "hosts": []interface{}{"a"}}
+
+	vals, err := src.Read(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	vals["db"].(map[string]interface{})["name"] = "changed"
+	vals["hosts"].([]interface{})[0] = "b"
+
+	if src["db"].(map[string]interface{})["name"] != "app" || src["hosts"].([]interface{})[0] != "a" {
+		t.Errorf("source was modified: %v", src)
+	}
+}
+
+func Test_metadataClient(t *testing.T) {
+	transport, ok := metadataClient(nil).Transport.(*http.Transport)
+	if !ok || transport.Proxy != nil {
+		t.Errorf("want a client without proxy")
+	}
+}
+
+func Test_sourceClient(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://config.internal/app.yaml", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("no proxy", func(t *testing.T) {
+		client, err := sourceClient(context.Background(), nil, true, "", nil)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if client != http.DefaultClient {
+			t.Errorf("want http.DefaultClient")
+		}
+	})
+
+	t.Run("proxy", func(t *testing.T) {
+		base, err := (&HTTPClient{Retries: 1, Headers: map[string]string{"Authorization": "Bearer token"}}).Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		client, err := sourceClient(context.Background(), base, true, "http://proxy.internal:3128", nil)
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if again, _ := sourceClient(context.Background(), base, true, "http://proxy.internal:3128", nil); again != client {
+			t.Errorf("want the same client for the same proxy")
+		}
+
+		headers, ok := client.Transport.(*headerTransport)
+		if !ok || headers.headers["Authorization"] != "Bearer token" {
+			t.Fatalf("want the headers of the client, got %T", client.Transport)
+		}
+		transport := headers.next.(*retryTransport).next.(*http.Transport)
+		proxy, err := transport.Proxy(req)
+		if err != nil || proxy.String() != "http://proxy.internal:3128" {
+			t.Errorf("want proxy http://proxy.internal:3128, got %v (%v)", proxy, err)
+		}
+		if base.Transport.(*headerTransport).next.(*retryTransport).next.(*http.Transport).Proxy == nil {
+			t.Errorf("client was modified")
+		}
+	})
+
+	t.Run("unknown transport", func(t *testing.T) {
+		base := &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) { return nil, nil })}
+		if _, err := sourceClient(context.Background(), base, true, "http://proxy.internal:3128", nil); err == nil {
+			t.Errorf("want error")
+		}
+	})
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
@@ -0,0 +1,84 @@
+package cfg
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kinds of the origins of values, as named in the `source` struct tag.
+const (
+	originFile     = "file"
+	originEnv      = "env"
+	originFlag     = "flag"
+	originOverride = "override"
+	originSource   = "source"
+)
+
+// originKind returns the kind of origin, which is either a kind itself, the
+// path of a config file or the name of a source. Dotenv files and readers
+// are files.
+func (f *cfg) originKind(origin string) string {
+	switch origin {
+	case originEnv, originFlag, originOverride, "default":
+		return origin
+	case "dotenv", "reader":
+		return originFile
+	}
+	for _, file := range f.files {
+		if origin == file {
+			return originFile
+		}
+	}
+	return originSource
+}
+
+// recordKind records the kind of origin as one of the kinds of the values
+// set at path.
+func (f *cfg) recordKind(path, origin string) {
+	if f.kinds == nil {
+		f.kinds = make(map[string]map[string]bool)
+	}
+	path = strings.ToLower(path)
+	if f.kinds[path] == nil {
+		f.kinds[path] = make(map[string]bool)
+	}
+	f.kinds[path][f.originKind(origin)] = true
+}
+
+// checkSourceTag returns an error if a value of field, or of any of its
+// nested fields, was set by an origin that its `source` tag does not
+// allow. Defaults are always allowed.
+func (f *cfg) checkSourceTag(field *field) error {
+	if len(field.sources) == 0 {
+		return nil
+	}
+
+	allowed := make(map[string]bool, len(field.sources))
+	for _, kind := range field.sources {
+		switch kind {
+		case originFile, originEnv, originFlag, originOverride, originSource:
+			allowed[kind] = true
+		default:
+			return fmt.Errorf("unknown source %q in source tag", kind)
+		}
+	}
+
+	path := strings.ToLower(field.path())
+	var denied []string
+	for p, kinds := range f.kinds {
+		if p != path && !strings.HasPrefix(p, path+".") && !strings.HasPrefix(p, path+"[") {
+			continue
+		}
+		for kind := range kinds {
+			if kind != "default" && !allowed[kind] {
+				denied = append(denied, kind)
+			}
+		}
+	}
+	if len(denied) == 0 {
+		return nil
+	}
+	sort.Strings(denied)
+	return fmt.Errorf("value set by %s, must be set by %s", denied[0], strings.Join(field.sources, " or "))
+}
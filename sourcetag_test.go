@@ -0,0 +1,99 @@
+package cfg
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_cfg_Load_SourceTag(t *testing.T) {
+	type Config struct {
+		Host     string `cfg:"host"`
+		Password string `cfg:"password" source:"env,source"`
+		DB       struct {
+			User  string `cfg:"user"`
+			Token string `cfg:"token"`
+		} `cfg:"db" source:"env"`
+		Level string `cfg:"level" source:"file" default:"info"`
+	}
+
+	secrets := SourceFunc(func(ctx context.Context) (map[string]interface{}, error) {
+		return map[string]interface{}{"password": "hunter2"}, nil
+	})
+
+	t.Run("allowed sources", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "config.yaml"), "host: localhost\n")
+		setenv(t, "DB_TOKEN", "t0k3n")
+
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir), WithSources(secrets), UseEnv("")); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Password != "hunter2" || cfg.DB.Token != "t0k3n" || cfg.Level != "info" {
+			t.Errorf("unexpected cfg %+v", cfg)
+		}
+	})
+
+	for _, tc := range []struct {
+		name    string
+		file    string
+		env     map[string]string
+		wantErr string
+	}{
+		{
+			name:    "field in file",
+			file:    "host: localhost\npassword: hunter2\n",
+			wantErr: "password: value set by file, must be set by env or source",
+		},
+		{
+			name:    "nested field in file",
+			file:    "db:\n  user: admin\n",
+			wantErr: "db: value set by file, must be set by env",
+		},
+		{
+			name:    "field in file overridden by env",
+			file:    "Password: hunter2\n",
+			env:     map[string]string{"PASSWORD": "s3cr3t"},
+			wantErr: "password: value set by file",
+		},
+		{
+			name:    "field in env",
+			file:    "host: localhost\n",
+			env:     map[string]string{"LEVEL": "debug"},
+			wantErr: "level: value set by env, must be set by file",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, "config.yaml"), tc.file)
+			for k, v := range tc.env {
+				setenv(t, k, v)
+			}
+
+			var cfg Config
+			err := Load(&cfg, Dirs(dir), UseEnv(""))
+			var errs fieldErrors
+			if !errors.As(err, &errs) || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("want err containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+
+	t.Run("unknown source", func(t *testing.T) {
+		type Config struct {
+			Host string `cfg:"host" source:"vault"`
+		}
+
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "config.yaml"), "{}")
+
+		var cfg Config
+		err := Load(&cfg, Dirs(dir))
+		if err == nil || !strings.Contains(err.Error(), `unknown source "vault"`) {
+			t.Fatalf("want unknown source err, got %v", err)
+		}
+	})
+}
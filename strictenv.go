@@ -0,0 +1,48 @@
+package cfg
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// checkUnusedEnv returns an error wrapping ErrUnusedEnv if StrictEnv is
+// set and env vars under the prefix were not looked up for any field.
+func (f *cfg) checkUnusedEnv() error {
+	if !f.strictEnv || !f.useEnv || f.envPrefix == "" {
+		return nil
+	}
+
+	sep := f.envSep
+	if sep == "" {
+		sep = "_"
+	}
+	prefix := strings.ToUpper(f.envPrefix) + sep
+	if f.subCommand != "" {
+		prefix += strings.ToUpper(strings.ReplaceAll(f.subCommand, ".", sep)) + sep
+	}
+
+	known := make([]string, 0, len(f.envNames))
+	for name := range f.envNames {
+		known = append(known, name)
+	}
+	sort.Strings(known)
+
+	var unused []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, prefix) || f.envNames[name] || f.envNames[strings.TrimSuffix(name, EnvFileSuffix)] {
+			continue
+		}
+		if near := nearestPath(name, strings.TrimPrefix(name, prefix), known); near != "" {
+			name = fmt.Sprintf("%s (did you mean %s?)", name, near)
+		}
+		unused = append(unused, name)
+	}
+	if len(unused) == 0 {
+		return nil
+	}
+	sort.Strings(unused)
+	return fmt.Errorf("%w: %s", ErrUnusedEnv, strings.Join(unused, ", "))
+}
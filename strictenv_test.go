@@ -0,0 +1,67 @@
+package cfg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_cfg_Load_StrictEnv(t *testing.T) {
+	type Config struct {
+		Server struct {
+			Host string `cfg:"host"`
+			Port int    `cfg:"port"`
+		} `cfg:"server"`
+		Servers []struct {
+			Host string `cfg:"host"`
+		} `cfg:"servers"`
+		Token string `cfg:"token"`
+	}
+
+	setenv(t, "MYAPP_SERVER_HOST", "localhost")
+	setenv(t, "MYAPP_SERVERS_0_HOST", "a")
+	setenv(t, "MYAPP_TOKEN_FILE", "/dev/null")
+	setenv(t, "OTHER_SERVER_PRT", "1")
+
+	t.Run("valid", func(t *testing.T) {
+		var cfg Config
+		if err := Load(&cfg, IgnoreFile(), UseEnv("myapp"), StrictEnv()); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+
+	t.Run("unused", func(t *testing.T) {
+		setenv(t, "MYAPP_SERVER_PRT", "8080")
+		setenv(t, "MYAPP_SERVERS_2_HOST", "c")
+
+		var cfg Config
+		err := Load(&cfg, IgnoreFile(), UseEnv("myapp"), StrictEnv())
+		if !errors.Is(err, ErrUnusedEnv) {
+			t.Fatalf("want ErrUnusedEnv, got %v", err)
+		}
+		want := "unused env vars: MYAPP_SERVERS_2_HOST (did you mean MYAPP_SERVERS_0_HOST?), MYAPP_SERVER_PRT (did you mean MYAPP_SERVER_PORT?)"
+		if err.Error() != want {
+			t.Errorf("want %q, got %q", want, err)
+		}
+
+		if err := Load(&cfg, IgnoreFile(), UseEnv("myapp")); err != nil {
+			t.Fatalf("want unused env vars ignored without StrictEnv, got %v", err)
+		}
+	})
+
+	t.Run("sub-command", func(t *testing.T) {
+		setenv(t, "MYAPP_MIGRATE_DIR", "migrations")
+		setenv(t, "MYAPP_SERVE_TOKEN", "abc")
+
+		var cfg Config
+		if err := Load(&cfg, IgnoreFile(), UseEnv("myapp"), SubCommand("serve"), StrictEnv()); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+
+		setenv(t, "MYAPP_SERVE_TOKN", "abc")
+		err := Load(&cfg, IgnoreFile(), UseEnv("myapp"), SubCommand("serve"), StrictEnv())
+		if err == nil || !strings.Contains(err.Error(), "MYAPP_SERVE_TOKN") {
+			t.Fatalf("want err naming MYAPP_SERVE_TOKN, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package cfg
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// checkStrictTypes checks the values of vals that are decoded into fields
+// of t tagged with `strictType:"true"`. Such fields are not subject to the
+// weak typing of the decoder: a number field rejects quoted numbers, a
+// bool field rejects `1` or `"true"` and a string field rejects numbers
+// and bools. path is the path of vals in the config struct. The returned
+// error, if any, is a fieldErrors.
+func (f *cfg) checkStrictTypes(vals interface{}, t reflect.Type, path string) error {
+	errs := make(fieldErrors)
+	f.strictTypeErrors(vals, t, path, errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (f *cfg) strictTypeErrors(val interface{}, t reflect.Type, path string, errs fieldErrors) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := val.(map[string]interface{})
+		if !ok || isScalarStruct(t) {
+			return
+		}
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if sf.PkgPath != "" && !sf.Anonymous {
+				continue
+			}
+			tag := sf.Tag.Get(f.tag)
+			if strings.Contains(tag, ",squash") {
+				f.strictTypeErrors(val, sf.Type, path, errs)
+				continue
+			}
+
+			name := parseTag(sf.Tag, f.tag).altName
+			if name == "" {
+				name = sf.Name
+			}
+			child, ok := lookupKey(m, name)
+			if !ok {
+				continue
+			}
+			childPath := joinPath(path, name)
+			if sf.Tag.Get("strictType") == "true" {
+				if err := checkStrictType(child, sf.Type); err != nil {
+					errs[childPath] = err
+				}
+				continue
+			}
+			f.strictTypeErrors(child, sf.Type, childPath, errs)
+		}
+
+	case reflect.Slice, reflect.Array:
+		s, ok := val.([]interface{})
+		if !ok {
+			return
+		}
+		for i, elem := range s {
+			f.strictTypeErrors(elem, t.Elem(), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+
+	case reflect.Map:
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for key, elem := range m {
+			f.strictTypeErrors(elem, t.Elem(), joinPath(path, key), errs)
+		}
+	}
+}
+
+// checkStrictType returns an error if val can only be decoded into a value
+// of type t by weak typing. Types that are decoded from strings by hooks,
+// such as time.Duration, and types other than numbers, bools and strings
+// are not checked.
+func checkStrictType(val interface{}, t reflect.Type) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if val == nil || t == reflect.TypeOf(time.Duration(0)) || reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return nil
+	}
+
+	var ok bool
+	switch kind := reflect.TypeOf(val).Kind(); t.Kind() {
+	case reflect.Bool:
+		ok = kind == reflect.Bool
+	case reflect.String:
+		ok = kind == reflect.String
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		switch kind {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			ok = true
+		}
+	default:
+		return nil
+	}
+
+	if !ok {
+		return fmt.Errorf("strict type: expected %v, got %T %v", t, val, val)
+	}
+	return nil
+}
+
+// lookupKey returns the value of m at key, matching keys that differ only
+// in case the same way the decoder does.
+func lookupKey(m map[string]interface{}, key string) (interface{}, bool) {
+	if v, ok := m[key]; ok {
+		return v, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// joinPath joins the path of a field and the name of one of its children.
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
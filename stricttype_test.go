@@ -0,0 +1,80 @@
+package cfg
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_cfg_Load_StrictType(t *testing.T) {
+	type Server struct {
+		Port    int           `cfg:"port" strictType:"true"`
+		Workers int           `cfg:"workers"`
+		TLS     bool          `cfg:"tls" strictType:"true"`
+		ID      string        `cfg:"id" strictType:"true"`
+		Timeout time.Duration `cfg:"timeout" strictType:"true"`
+	}
+	type Config struct {
+		Servers []Server `cfg:"servers"`
+		Ratio   *float64 `cfg:"ratio" strictType:"true"`
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		var cfg Config
+		data := `{"servers": [{"port": 80, "workers": "4", "tls": false, "id": "a", "timeout": "1s"}], "ratio": 0.5}`
+		if err := LoadBytes(&cfg, []byte(data), ".json"); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Servers[0].Port != 80 || cfg.Servers[0].Workers != 4 || *cfg.Ratio != 0.5 {
+			t.Errorf("unexpected cfg %+v", cfg)
+		}
+	})
+
+	t.Run("weakly typed", func(t *testing.T) {
+		var cfg Config
+		data := "servers:\n  - port: '80'\n    tls: 1\n    id: 42\nratio: '0.5'\n"
+		err := LoadBytes(&cfg, []byte(data), ".yaml")
+
+		var fe fieldErrors
+		if !errors.As(err, &fe) {
+			t.Fatalf("want fieldErrors, got %v", err)
+		}
+		for _, path := range []string{"servers[0].port", "servers[0].tls", "servers[0].id", "ratio"} {
+			if _, ok := fe[path]; !ok {
+				t.Errorf("want err for %s, got %v", path, fe)
+			}
+		}
+		if len(fe) != 4 {
+			t.Errorf("want 4 errs, got %v", fe)
+		}
+	})
+}
+
+func Test_checkStrictType(t *testing.T) {
+	for _, tc := range []struct {
+		val   interface{}
+		typ   interface{}
+		valid bool
+	}{
+		{val: 1, typ: int64(0), valid: true},
+		{val: 1.5, typ: float32(0), valid: true},
+		{val: uint64(1), typ: uint8(0), valid: true},
+		{val: "1", typ: 0, valid: false},
+		{val: true, typ: 0, valid: false},
+		{val: true, typ: false, valid: true},
+		{val: "true", typ: false, valid: false},
+		{val: 0, typ: false, valid: false},
+		{val: "a", typ: "", valid: true},
+		{val: 1, typ: "", valid: false},
+		{val: nil, typ: 0, valid: true},
+		{val: "1s", typ: time.Second, valid: true},
+		{val: "50%", typ: Percent(0), valid: true},
+		{val: []interface{}{1}, typ: []int{}, valid: true},
+	} {
+		err := checkStrictType(tc.val, reflect.TypeOf(tc.typ))
+		if (err == nil) != tc.valid {
+			t.Errorf("%#v into %T: want valid %v, got err %v", tc.val, tc.typ, tc.valid, err)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package cfg
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// invalidKeysRe matches the errors of the decoder about unknown keys in
+// strict mode, e.g. `'server' has invalid keys: hots, prot`.
+var invalidKeysRe = regexp.MustCompile(`^'([^']*)' has invalid keys: (.+)$`)
+
+// suggestKeys rewrites the errors of err about unknown keys in strict mode
+// so that each unknown key that is close to the path of a field of cfg
+// suggests that path, e.g. "unknown key 'log_lvl', did you mean
+// 'log_level'?". Unknown keys without a close field are reported as they
+// were. err is returned as is if it is not a *mapstructure.Error.
+func (f *cfg) suggestKeys(err error, cfg interface{}) error {
+	var mErr *mapstructure.Error
+	if !errors.As(err, &mErr) || !isStructPtr(cfg) {
+		return err
+	}
+
+	var paths []string
+	for _, field := range flattenCfg(cfg, f.tag) {
+		paths = append(paths, field.path())
+	}
+
+	var msgs []string
+	for _, msg := range mErr.Errors {
+		m := invalidKeysRe.FindStringSubmatch(msg)
+		if m == nil {
+			msgs = append(msgs, msg)
+			continue
+		}
+		var unmatched []string
+		for _, key := range strings.Split(m[2], ", ") {
+			path := joinPath(m[1], key)
+			if s := nearestPath(path, key, paths); s != "" {
+				msgs = append(msgs, fmt.Sprintf("unknown key '%s', did you mean '%s'?", path, s))
+			} else {
+				unmatched = append(unmatched, key)
+			}
+		}
+		if len(unmatched) > 0 {
+			msgs = append(msgs, fmt.Sprintf("'%s' has invalid keys: %s", m[1], strings.Join(unmatched, ", ")))
+		}
+	}
+	mErr.Errors = msgs
+	return err
+}
+
+// nearestPath returns the path of paths with the smallest edit distance to
+// path, or "" if none is within a third of the length of key, the last
+// element of path. Paths are compared case-insensitively as keys are
+// matched to fields.
+func nearestPath(path, key string, paths []string) string {
+	maxDist := len([]rune(key)) / 3
+	if maxDist < 1 {
+		maxDist = 1
+	}
+
+	nearest, nearestDist := "", maxDist+1
+	for _, p := range paths {
+		if d := editDistance(strings.ToLower(path), strings.ToLower(p)); d > 0 && d < nearestDist {
+			nearest, nearestDist = p, d
+		}
+	}
+	return nearest
+}
+
+// editDistance returns the edit distance between a and b, counting
+// insertions, deletions, substitutions and transpositions of adjacent
+// runes, the usual typos, as one edit each.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+func minInt(x int, ys ...int) int {
+	for _, y := range ys {
+		if y < x {
+			x = y
+		}
+	}
+	return x
+}
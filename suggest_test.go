@@ -0,0 +1,70 @@
+package cfg
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_cfg_Load_UseStrict_Suggestions(t *testing.T) {
+	type Config struct {
+		LogLevel string `cfg:"log_level"`
+		Server   struct {
+			Host string `cfg:"host"`
+			Port int    `cfg:"port"`
+		} `cfg:"server"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "log_lvl: debug\nserver:\n  hots: localhost\n  port: 80\n  timeout: 5s\n")
+
+	var cfg Config
+	err := Load(&cfg, UseStrict(), Dirs(dir))
+	if err == nil {
+		t.Fatal("expected err")
+	}
+	for _, want := range []string{
+		"unknown key 'log_lvl', did you mean 'log_level'?",
+		"unknown key 'server.hots', did you mean 'server.host'?",
+		"'server' has invalid keys: timeout",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("want %q in err, got %v", want, err)
+		}
+	}
+}
+
+func Test_nearestPath(t *testing.T) {
+	paths := []string{"log_level", "server", "server.host", "server.port"}
+	for _, tc := range []struct {
+		path, key, want string
+	}{
+		{"log_lvl", "log_lvl", "log_level"},
+		{"server.prot", "prot", "server.port"},
+		{"Server.Host2", "Host2", "server.host"},
+		{"timeout", "timeout", ""},
+		{"server.port", "port", ""},
+	} {
+		if got := nearestPath(tc.path, tc.key, paths); got != tc.want {
+			t.Errorf("nearestPath(%q) = %q, want %q", tc.path, got, tc.want)
+		}
+	}
+}
+
+func Test_editDistance(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"log_lvl", "log_level", 2},
+		{"héllo", "hello", 1},
+		{"hots", "host", 1},
+	} {
+		if got := editDistance(tc.a, tc.b); got != tc.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
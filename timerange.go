@@ -0,0 +1,237 @@
+package cfg
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+// TimeRange is a range of time within a day, such as `09:00-17:00`.
+//
+// Times are formatted as `15:04` or `15:04:05`. A range whose end is
+// before its start wraps around midnight (e.g. `22:00-06:00`) and a range
+// whose start equals its end spans the entire day.
+type TimeRange struct {
+	Start time.Duration // offset of the start of the range from midnight.
+	End   time.Duration // offset of the end of the range from midnight.
+}
+
+// UnmarshalText parses a time range in the form `start-end`.
+func (tr *TimeRange) UnmarshalText(text []byte) error {
+	start, end, ok := strings.Cut(strings.TrimSpace(string(text)), "-")
+	if !ok {
+		return fmt.Errorf("invalid time range %q: missing '-'", text)
+	}
+
+	s, err := parseClock(start)
+	if err != nil {
+		return fmt.Errorf("invalid time range %q: %w", text, err)
+	}
+	e, err := parseClock(end)
+	if err != nil {
+		return fmt.Errorf("invalid time range %q: %w", text, err)
+	}
+
+	tr.Start, tr.End = s, e
+	return nil
+}
+
+// MarshalText formats the time range in the form `start-end`.
+func (tr TimeRange) MarshalText() ([]byte, error) {
+	return []byte(tr.String()), nil
+}
+
+// String formats the time range in the form `start-end`.
+func (tr TimeRange) String() string {
+	return formatClock(tr.Start) + "-" + formatClock(tr.End)
+}
+
+// Contains reports whether the time of day of t falls within the range.
+// The start of the range is inclusive and its end exclusive.
+func (tr TimeRange) Contains(t time.Time) bool {
+	d := clockOffset(t)
+	switch {
+	case tr.Start == tr.End:
+		return true
+	case tr.Start < tr.End:
+		return d >= tr.Start && d < tr.End
+	default:
+		return d >= tr.Start || d < tr.End
+	}
+}
+
+// wrapped reports whether the time of day of t falls within the part of the
+// range after midnight, if the range wraps around it.
+func (tr TimeRange) wrapped(t time.Time) bool {
+	return tr.Start > tr.End && clockOffset(t) < tr.End
+}
+
+// clockOffset returns the offset of the time of day of t from midnight.
+func clockOffset(t time.Time) time.Duration {
+	h, m, s := t.Clock()
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second
+}
+
+// parseClock parses a time of day formatted as `15:04` or `15:04:05` into
+// its offset from midnight. `24:00` is accepted as the end of the day.
+func parseClock(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "24:00" || s == "24:00:00" {
+		return day, nil
+	}
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return time.Duration(t.Hour())*time.Hour +
+				time.Duration(t.Minute())*time.Minute +
+				time.Duration(t.Second())*time.Second, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid time of day %q", s)
+}
+
+// formatClock formats an offset from midnight as `15:04`, or `15:04:05`
+// if it has a seconds component.
+func formatClock(d time.Duration) string {
+	h := d / time.Hour
+	m := (d % time.Hour) / time.Minute
+	s := (d % time.Minute) / time.Second
+	if s != 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%02d:%02d", h, m)
+}
+
+// Window is a recurring weekly window of time, such as `Mon-Fri` or
+// `Sat,Sun 02:00-04:00`.
+//
+// Days are given as a comma separated list of three letter day names or
+// day ranges (e.g. `Fri-Mon`), optionally followed by a TimeRange. If the
+// time range is omitted the window spans the entirety of each day. A time
+// range that wraps around midnight belongs to the day it starts on, e.g.
+// `Mon-Fri 22:00-06:00` spans from Monday 22:00 to Saturday 06:00.
+type Window struct {
+	Days  []time.Weekday // days the window applies to, in week order.
+	Hours TimeRange      // time of day the window applies to.
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// UnmarshalText parses a window in the form `days [start-end]`.
+func (w *Window) UnmarshalText(text []byte) error {
+	fields := strings.Fields(string(text))
+	if len(fields) == 0 || len(fields) > 2 {
+		return fmt.Errorf("invalid window %q", text)
+	}
+
+	var set [7]bool
+	for _, spec := range strings.Split(fields[0], ",") {
+		from, to, isRange := strings.Cut(spec, "-")
+		first, err := parseWeekday(from)
+		if err != nil {
+			return fmt.Errorf("invalid window %q: %w", text, err)
+		}
+		last := first
+		if isRange {
+			if last, err = parseWeekday(to); err != nil {
+				return fmt.Errorf("invalid window %q: %w", text, err)
+			}
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			set[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+
+	var hours TimeRange
+	if len(fields) == 2 {
+		if err := hours.UnmarshalText([]byte(fields[1])); err != nil {
+			return fmt.Errorf("invalid window %q: %w", text, err)
+		}
+	}
+
+	var days []time.Weekday
+	for d, ok := range set {
+		if ok {
+			days = append(days, time.Weekday(d))
+		}
+	}
+	w.Days, w.Hours = days, hours
+	return nil
+}
+
+// MarshalText formats the window in the form `days [start-end]`.
+func (w Window) MarshalText() ([]byte, error) {
+	return []byte(w.String()), nil
+}
+
+// String formats the window in the form `days [start-end]`.
+func (w Window) String() string {
+	days := make([]string, 0, len(w.Days))
+	for _, d := range w.Days {
+		days = append(days, d.String()[:3])
+	}
+	s := strings.Join(days, ",")
+	if w.Hours != (TimeRange{}) {
+		s += " " + w.Hours.String()
+	}
+	return s
+}
+
+// Contains reports whether t falls within the window.
+func (w Window) Contains(t time.Time) bool {
+	if !w.Hours.Contains(t) {
+		return false
+	}
+	day := t.Weekday()
+	if w.Hours.wrapped(t) {
+		day = (day + 6) % 7
+	}
+	for _, d := range w.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// nextInWindows returns the earliest time from t on that falls within one
+// of windows, and false if there is none within a week.
+func nextInWindows(windows []Window, t time.Time) (time.Time, bool) {
+	var next time.Time
+	found := false
+	for _, w := range windows {
+		if w.Contains(t) {
+			return t, true
+		}
+		// windows open at the start of their hours on one of the days.
+		y, m, d := t.Date()
+		for i := 0; i <= 7; i++ {
+			start := time.Date(y, m, d+i, 0, 0, 0, 0, t.Location()).Add(w.Hours.Start)
+			if start.After(t) && w.Contains(start) && (!found || start.Before(next)) {
+				next, found = start, true
+			}
+		}
+	}
+	return next, found
+}
+
+// parseWeekday parses a three letter day name, ignoring case.
+func parseWeekday(s string) (time.Weekday, error) {
+	d, ok := weekdays[strings.ToLower(strings.TrimSpace(s))]
+	if !ok {
+		return 0, fmt.Errorf("invalid day %q", s)
+	}
+	return d, nil
+}
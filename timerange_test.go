@@ -0,0 +1,185 @@
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_cfg_Load_TimeRange(t *testing.T) {
+	type Config struct {
+		Business    TimeRange `cfg:"business"`
+		Quiet       TimeRange `cfg:"quiet" default:"22:00-06:00"`
+		Maintenance Window    `cfg:"maintenance" validate:"required"`
+		Backup      *Window   `cfg:"backup"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), `
+business: "09:00-17:30"
+maintenance: "Sat,Sun 02:00-04:00"
+`)
+
+	os.Clearenv()
+	setenv(t, "APP_BACKUP", "Fri-Mon")
+
+	var cfg Config
+	if err := Load(&cfg, Dirs(dir), UseEnv("app")); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := Config{
+		Business: TimeRange{Start: 9 * time.Hour, End: 17*time.Hour + 30*time.Minute},
+		Quiet:    TimeRange{Start: 22 * time.Hour, End: 6 * time.Hour},
+		Maintenance: Window{
+			Days:  []time.Weekday{time.Sunday, time.Saturday},
+			Hours: TimeRange{Start: 2 * time.Hour, End: 4 * time.Hour},
+		},
+		Backup: &Window{Days: []time.Weekday{time.Sunday, time.Monday, time.Friday, time.Saturday}},
+	}
+	if !reflect.DeepEqual(want, cfg) {
+		t.Errorf("\nwant %+v\ngot %+v", want, cfg)
+	}
+
+	t.Run("invalid range", func(t *testing.T) {
+		writeFile(t, filepath.Join(dir, "config.yaml"), `business: "9am-5pm"`)
+
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir), UseEnv("app")); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+}
+
+func TestTimeRange(t *testing.T) {
+	at := func(h, m int) time.Time {
+		return time.Date(2020, 1, 6, h, m, 0, 0, time.UTC) // a Monday
+	}
+
+	for _, tc := range []struct {
+		In      string
+		String  string
+		Inside  []time.Time
+		Outside []time.Time
+	}{
+		{
+			In:      "09:00-17:00",
+			String:  "09:00-17:00",
+			Inside:  []time.Time{at(9, 0), at(16, 59)},
+			Outside: []time.Time{at(8, 59), at(17, 0)},
+		},
+		{
+			In:      " 22:00 - 06:00:30 ",
+			String:  "22:00-06:00:30",
+			Inside:  []time.Time{at(23, 0), at(0, 0), at(6, 0)},
+			Outside: []time.Time{at(12, 0), at(21, 59)},
+		},
+		{
+			In:     "00:00-24:00",
+			String: "00:00-24:00",
+			Inside: []time.Time{at(0, 0), at(23, 59)},
+		},
+		{
+			In:     "12:00-12:00",
+			String: "12:00-12:00",
+			Inside: []time.Time{at(0, 0), at(12, 0), at(23, 59)},
+		},
+	} {
+		t.Run(tc.In, func(t *testing.T) {
+			var tr TimeRange
+			if err := tr.UnmarshalText([]byte(tc.In)); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if tr.String() != tc.String {
+				t.Errorf("want %s, got %s", tc.String, tr.String())
+			}
+			for _, in := range tc.Inside {
+				if !tr.Contains(in) {
+					t.Errorf("want %s to contain %s", tr, in.Format("15:04"))
+				}
+			}
+			for _, out := range tc.Outside {
+				if tr.Contains(out) {
+					t.Errorf("want %s to not contain %s", tr, out.Format("15:04"))
+				}
+			}
+		})
+	}
+
+	for _, in := range []string{"", "09:00", "09:00-", "25:00-26:00", "9-5"} {
+		t.Run("invalid "+in, func(t *testing.T) {
+			var tr TimeRange
+			if err := tr.UnmarshalText([]byte(in)); err == nil {
+				t.Fatal("expected err")
+			}
+		})
+	}
+}
+
+func TestWindow(t *testing.T) {
+	monday := time.Date(2020, 1, 6, 3, 0, 0, 0, time.UTC)
+	saturday := monday.AddDate(0, 0, 5)
+
+	for _, tc := range []struct {
+		In      string
+		String  string
+		Inside  []time.Time
+		Outside []time.Time
+	}{
+		{
+			In:      "Mon-Fri",
+			String:  "Mon,Tue,Wed,Thu,Fri",
+			Inside:  []time.Time{monday},
+			Outside: []time.Time{saturday},
+		},
+		{
+			In:      "sat,MON 02:00-04:00",
+			String:  "Mon,Sat 02:00-04:00",
+			Inside:  []time.Time{monday, saturday},
+			Outside: []time.Time{monday.Add(time.Hour)},
+		},
+		{
+			// the hours after midnight belong to the day before.
+			In:      "Mon-Fri 22:00-06:00",
+			String:  "Mon,Tue,Wed,Thu,Fri 22:00-06:00",
+			Inside:  []time.Time{monday.Add(20 * time.Hour), saturday.Add(-2 * time.Hour), monday.AddDate(0, 0, 1).Add(-2 * time.Hour)},
+			Outside: []time.Time{monday.Add(-2 * time.Hour), monday.Add(-4 * time.Hour), saturday.Add(20 * time.Hour)},
+		},
+		{
+			In:     "Fri-Mon",
+			String: "Sun,Mon,Fri,Sat",
+			Inside: []time.Time{monday, saturday},
+		},
+	} {
+		t.Run(tc.In, func(t *testing.T) {
+			var w Window
+			if err := w.UnmarshalText([]byte(tc.In)); err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if w.String() != tc.String {
+				t.Errorf("want %s, got %s", tc.String, w.String())
+			}
+			for _, in := range tc.Inside {
+				if !w.Contains(in) {
+					t.Errorf("want %s to contain %s", w, in)
+				}
+			}
+			for _, out := range tc.Outside {
+				if w.Contains(out) {
+					t.Errorf("want %s to not contain %s", w, out)
+				}
+			}
+		})
+	}
+
+	for _, in := range []string{"", "Monday", "Mon-Funday", "Mon 09:00", "Mon 09:00-10:00 extra"} {
+		t.Run("invalid "+in, func(t *testing.T) {
+			var w Window
+			if err := w.UnmarshalText([]byte(in)); err == nil {
+				t.Fatal("expected err")
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package cfg
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TLS is a reusable config section for TLS settings. Certificates, keys and
+// CAs can be given either as paths to PEM files or inline as PEM data.
+//
+//	type Config struct {
+//	  Server struct {
+//	    TLS cfg.TLS `cfg:"tls"`
+//	  } `cfg:"server"`
+//	}
+//
+// The section is validated when the config is loaded. Use Build to create a
+// *tls.Config from it.
+type TLS struct {
+	CertFile string `cfg:"cert_file" path:"true"`
+	KeyFile  string `cfg:"key_file" path:"true"`
+	CAFile   string `cfg:"ca_file" path:"true"`
+
+	Cert string `cfg:"cert" secret:"true"` // inline PEM encoded certificate.
+	Key  string `cfg:"key" secret:"true"`  // inline PEM encoded private key.
+	CA   string `cfg:"ca"`                 // inline PEM encoded CA certificates.
+
+	// ServerName is used to verify the hostname of the server.
+	ServerName string `cfg:"server_name"`
+	// MinVersion is the minimum TLS version, one of 1.0, 1.1, 1.2 or 1.3.
+	MinVersion string `cfg:"min_version" default:"1.2"`
+	// CipherSuites are the names of the enabled cipher suites, as
+	// listed by tls.CipherSuites. If empty a safe default list is used.
+	CipherSuites []string `cfg:"cipher_suites"`
+	// ClientAuth is the policy of a server for client certificates, one
+	// of none, request, require, verify_if_given or require_and_verify.
+	ClientAuth string `cfg:"client_auth"`
+	// InsecureSkipVerify disables verification of the server's certificate.
+	InsecureSkipVerify bool `cfg:"insecure_skip_verify"`
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+var tlsClientAuths = map[string]tls.ClientAuthType{
+	"":                   tls.NoClientCert,
+	"none":               tls.NoClientCert,
+	"request":            tls.RequestClientCert,
+	"require":            tls.RequireAnyClientCert,
+	"verify_if_given":    tls.VerifyClientCertIfGiven,
+	"require_and_verify": tls.RequireAndVerifyClientCert,
+}
+
+// Validate checks that the settings are consistent.
+func (t *TLS) Validate() error {
+	if t.CertFile != "" && t.Cert != "" {
+		return fmt.Errorf("cert_file and cert are mutually exclusive")
+	}
+	if t.KeyFile != "" && t.Key != "" {
+		return fmt.Errorf("key_file and key are mutually exclusive")
+	}
+	if t.CAFile != "" && t.CA != "" {
+		return fmt.Errorf("ca_file and ca are mutually exclusive")
+	}
+	if hasCert, hasKey := t.CertFile != "" || t.Cert != "", t.KeyFile != "" || t.Key != ""; hasCert != hasKey {
+		return fmt.Errorf("a certificate and a key must be set together")
+	}
+	if _, err := t.minVersion(); err != nil {
+		return err
+	}
+	if _, err := t.cipherSuites(); err != nil {
+		return err
+	}
+	if _, ok := tlsClientAuths[t.ClientAuth]; !ok {
+		return fmt.Errorf("unknown client_auth %q", t.ClientAuth)
+	}
+	return nil
+}
+
+// Build validates the settings and creates a *tls.Config from them, reading
+// any certificate, key and CA files. The CAs are used both to verify servers
+// and to verify client certificates.
+func (t *TLS) Build() (*tls.Config, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+
+	minVersion, _ := t.minVersion()
+	cipherSuites, _ := t.cipherSuites()
+
+	conf := &tls.Config{
+		ServerName:         t.ServerName,
+		MinVersion:         minVersion,
+		CipherSuites:       cipherSuites,
+		ClientAuth:         tlsClientAuths[t.ClientAuth],
+		InsecureSkipVerify: t.InsecureSkipVerify, //nolint:gosec
+	}
+
+	certPEM, err := readPEM(t.Cert, t.CertFile)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := readPEM(t.Key, t.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if certPEM != nil {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, err
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+
+	caPEM, err := readPEM(t.CA, t.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	if caPEM != nil {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in ca")
+		}
+		conf.RootCAs = pool
+		conf.ClientCAs = pool
+	}
+
+	return conf, nil
+}
+
+// minVersion returns the tls version constant of MinVersion, defaulting to
+// TLS 1.2.
+func (t *TLS) minVersion() (uint16, error) {
+	if t.MinVersion == "" {
+		return tls.VersionTLS12, nil
+	}
+	v, ok := tlsVersions[t.MinVersion]
+	if !ok {
+		return 0, fmt.Errorf("unknown min_version %q", t.MinVersion)
+	}
+	return v, nil
+}
+
+// cipherSuites returns the ids of the named CipherSuites.
+func (t *TLS) cipherSuites() ([]uint16, error) {
+	if len(t.CipherSuites) == 0 {
+		return nil, nil
+	}
+
+	known := make(map[string]uint16)
+	for _, cs := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+		known[cs.Name] = cs.ID
+	}
+
+	ids := make([]uint16, 0, len(t.CipherSuites))
+	for _, name := range t.CipherSuites {
+		id, ok := known[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// readPEM returns inline if set, else the contents of file if set, else nil.
+func readPEM(inline, file string) ([]byte, error) {
+	switch {
+	case inline != "":
+		return []byte(inline), nil
+	case file != "":
+		return os.ReadFile(file)
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,183 @@
+package cfg
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_cfg_Load_TLS(t *testing.T) {
+	type Config struct {
+		Server struct {
+			TLS TLS `cfg:"tls"`
+		} `cfg:"server"`
+		Client *TLS `cfg:"client"`
+	}
+
+	certPEM, keyPEM := testCertificate(t)
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "cert.pem"), certPEM)
+	writeFile(t, filepath.Join(dir, "key.pem"), keyPEM)
+	writeFile(t, filepath.Join(dir, "config.yaml"), `
+server:
+  tls:
+    cert_file: cert.pem
+    key_file: key.pem
+    ca_file: cert.pem
+    client_auth: require_and_verify
+`)
+
+	var cfg Config
+	if err := Load(&cfg, Dirs(dir)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if want := filepath.Join(dir, "cert.pem"); cfg.Server.TLS.CertFile != want {
+		t.Errorf("CertFile: want %s, got %s", want, cfg.Server.TLS.CertFile)
+	}
+	if cfg.Server.TLS.MinVersion != "1.2" {
+		t.Errorf("MinVersion: want 1.2, got %s", cfg.Server.TLS.MinVersion)
+	}
+
+	conf, err := cfg.Server.TLS.Build()
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(conf.Certificates) != 1 {
+		t.Errorf("want 1 certificate, got %d", len(conf.Certificates))
+	}
+	if conf.ClientCAs == nil || conf.RootCAs == nil {
+		t.Error("want CA pools to be set")
+	}
+	if conf.ClientAuth != tls.RequireAndVerifyClientCert {
+		t.Errorf("want ClientAuth %v, got %v", tls.RequireAndVerifyClientCert, conf.ClientAuth)
+	}
+	if conf.MinVersion != tls.VersionTLS12 {
+		t.Errorf("want MinVersion %d, got %d", tls.VersionTLS12, conf.MinVersion)
+	}
+
+	t.Run("validation error", func(t *testing.T) {
+		writeFile(t, filepath.Join(dir, "config.yaml"), `
+client:
+  cert_file: cert.pem
+  min_version: "1.4"
+`)
+
+		var cfg Config
+		err := Load(&cfg, Dirs(dir))
+		if err == nil || !strings.Contains(err.Error(), "client: a certificate and a key must be set together") {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+}
+
+func TestTLS_Validate(t *testing.T) {
+	for _, tc := range []struct {
+		Name string
+		TLS  TLS
+		Err  string
+	}{
+		{Name: "empty", TLS: TLS{}},
+		{Name: "cert and cert_file", TLS: TLS{Cert: "x", CertFile: "x", Key: "x"}, Err: "mutually exclusive"},
+		{Name: "key and key_file", TLS: TLS{Key: "x", KeyFile: "x", Cert: "x"}, Err: "mutually exclusive"},
+		{Name: "ca and ca_file", TLS: TLS{CA: "x", CAFile: "x"}, Err: "mutually exclusive"},
+		{Name: "key without cert", TLS: TLS{KeyFile: "x"}, Err: "set together"},
+		{Name: "min version", TLS: TLS{MinVersion: "1.3"}},
+		{Name: "unknown min version", TLS: TLS{MinVersion: "2"}, Err: "unknown min_version"},
+		{Name: "cipher suites", TLS: TLS{CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"}}},
+		{Name: "unknown cipher suite", TLS: TLS{CipherSuites: []string{"TLS_NOPE"}}, Err: "unknown cipher suite"},
+		{Name: "unknown client auth", TLS: TLS{ClientAuth: "maybe"}, Err: "unknown client_auth"},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := tc.TLS.Validate()
+			if tc.Err == "" && err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if tc.Err != "" && (err == nil || !strings.Contains(err.Error(), tc.Err)) {
+				t.Fatalf("want err containing %q, got %v", tc.Err, err)
+			}
+		})
+	}
+}
+
+func TestTLS_Build(t *testing.T) {
+	certPEM, keyPEM := testCertificate(t)
+
+	t.Run("inline", func(t *testing.T) {
+		tlsConf := TLS{Cert: certPEM, Key: keyPEM, ServerName: "example.com", MinVersion: "1.3"}
+		conf, err := tlsConf.Build()
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if len(conf.Certificates) != 1 || conf.ServerName != "example.com" || conf.MinVersion != tls.VersionTLS13 {
+			t.Errorf("unexpected config: %+v", conf)
+		}
+	})
+
+	t.Run("invalid ca", func(t *testing.T) {
+		tlsConf := TLS{CA: "not a pem"}
+		if _, err := tlsConf.Build(); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		tlsConf := TLS{CertFile: "nope.pem", Key: keyPEM}
+		if _, err := tlsConf.Build(); !os.IsNotExist(err) {
+			t.Fatalf("want not exist err, got %v", err)
+		}
+	})
+
+	t.Run("mismatched key", func(t *testing.T) {
+		_, otherKey := testCertificate(t)
+		tlsConf := TLS{Cert: certPEM, Key: otherKey}
+		if _, err := tlsConf.Build(); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+}
+
+// testCertificate returns a PEM encoded self-signed certificate and its key.
+func testCertificate(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "example.com"},
+		DNSNames:              []string{"example.com"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return string(certPEM), string(keyPEM)
+}
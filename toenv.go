@@ -0,0 +1,92 @@
+package cfg
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ToEnv renders the values of cfg, a pointer to a loaded config struct, as
+// the env vars that UseEnv(prefix) would read them from, e.g. to configure
+// a child process that is configured by env vars only:
+//
+//	cmd := exec.Command("worker")
+//	for k, v := range cfg.ToEnv(&conf, "worker") {
+//	  cmd.Env = append(cmd.Env, k+"="+v)
+//	}
+//
+// Names follow the tag, separator and key func of options and the env
+// tags of fields, and fields tagged `env:"-"` are left out. Maps and slices
+// of structs are rendered as JSON, and other slices as lists separated by
+// commas, or by the sep tag of their field. Nil pointers and empty slices
+// and maps are left out, and so are fields tagged `secret:"true"` and their
+// nested fields: pass secrets to the child process by other means, such as
+// the files of env vars suffixed with `_FILE`. ToEnv returns nil if cfg is
+// not a pointer to a struct.
+func ToEnv(cfg interface{}, prefix string, options ...Option) map[string]string {
+	conf := defaultCfg()
+	for _, opt := range options {
+		opt(conf)
+	}
+	conf.envPrefix = prefix
+	if !isStructPtr(cfg) {
+		return nil
+	}
+
+	v := reflect.ValueOf(cfg).Elem()
+	env := make(map[string]string)
+	conf.envPairs(&field{v: v, t: v.Type(), sliceIdx: -1}, env)
+	return env
+}
+
+// envPairs adds the env vars of field and of its nested fields to env.
+func (f *cfg) envPairs(fd *field, env map[string]string) {
+	if fd.noEnv || fd.secret {
+		return
+	}
+
+	v := fd.v
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	fd.v, fd.t = v, v.Type()
+
+	switch {
+	case v.Kind() == reflect.Struct && !isScalarStruct(v.Type()):
+		for i := 0; i < fd.t.NumField(); i++ {
+			if fd.t.Field(i).PkgPath != "" && !fd.t.Field(i).Anonymous {
+				continue
+			}
+			f.envPairs(newStructField(fd, i, f.tag), env)
+		}
+
+	case isComposite(v.Type()):
+		if v.Len() == 0 {
+			return
+		}
+		if b, err := json.Marshal(f.plainValue(v)); err == nil {
+			env[f.envKey(fd)] = string(b)
+		}
+
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		if v.Len() == 0 || v.Type().Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		elems := make([]string, v.Len())
+		for i := range elems {
+			elems[i] = fmt.Sprint(f.plainValue(v.Index(i)))
+		}
+		sep := fd.sep
+		if sep == "" {
+			sep = ","
+		}
+		env[f.envKey(fd)] = strings.Join(elems, sep)
+
+	default:
+		env[f.envKey(fd)] = fmt.Sprint(f.plainValue(v))
+	}
+}
@@ -0,0 +1,77 @@
+package cfg
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_ToEnv(t *testing.T) {
+	type Server struct {
+		Host string `cfg:"host"`
+		Port int    `cfg:"port"`
+	}
+	type Config struct {
+		Server   Server            `cfg:"server"`
+		Timeout  time.Duration     `cfg:"timeout"`
+		Start    time.Time         `cfg:"start"`
+		Tags     []string          `cfg:"tags"`
+		Replicas []Server          `cfg:"replicas"`
+		Labels   map[string]string `cfg:"labels"`
+		Token    string            `cfg:"token" env:"API_TOKEN"`
+		Limit    *int              `cfg:"limit"`
+		Debug    bool              `cfg:"debug"`
+	}
+
+	cfg := Config{
+		Server:   Server{Host: "localhost", Port: 80},
+		Timeout:  5 * time.Second,
+		Start:    time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Tags:     []string{"a", "b"},
+		Replicas: []Server{{Host: "r1", Port: 1}},
+		Labels:   map[string]string{"team": "core"},
+		Token:    "abc",
+	}
+
+	got := ToEnv(&cfg, "app")
+	want := map[string]string{
+		"APP_SERVER_HOST": "localhost",
+		"APP_SERVER_PORT": "80",
+		"APP_TIMEOUT":     "5s",
+		"APP_START":       "2020-01-02T03:04:05Z",
+		"APP_TAGS":        "a,b",
+		"APP_REPLICAS":    `[{"host":"r1","port":1}]`,
+		"APP_LABELS":      `{"team":"core"}`,
+		"API_TOKEN":       "abc",
+		"APP_DEBUG":       "false",
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	t.Run("loads back", func(t *testing.T) {
+		for k, v := range got {
+			setenv(t, k, v)
+		}
+		var loaded Config
+		if err := Load(&loaded, UseEnv("app"), IgnoreFile()); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if !reflect.DeepEqual(cfg, loaded) {
+			t.Errorf("want %+v, got %+v", cfg, loaded)
+		}
+	})
+
+	t.Run("separator", func(t *testing.T) {
+		env := ToEnv(&cfg, "app", EnvSeparator("__"))
+		if got := env["APP__SERVER__PORT"]; got != "80" {
+			t.Errorf("want 80, got %q", got)
+		}
+	})
+
+	t.Run("not a struct pointer", func(t *testing.T) {
+		if env := ToEnv(cfg, "app"); env != nil {
+			t.Errorf("want nil, got %v", env)
+		}
+	})
+}
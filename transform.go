@@ -0,0 +1,93 @@
+package cfg
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Transformer normalizes the value of a string field. Transformers are
+// referenced by name in the `transform` struct tag of a field.
+type Transformer func(s string) (string, error)
+
+var (
+	transformersMu sync.RWMutex
+	transformers   = map[string]Transformer{
+		"trimspace": func(s string) (string, error) { return strings.TrimSpace(s), nil },
+		"lower":     func(s string) (string, error) { return strings.ToLower(s), nil },
+		"upper":     func(s string) (string, error) { return strings.ToUpper(s), nil },
+	}
+)
+
+// RegisterTransformer registers a transformer under name so that it can be
+// referenced in the `transform` struct tag of fields. Registering a name
+// that is already registered replaces the previous transformer.
+//
+//	cfg.RegisterTransformer("nodashes", func(s string) (string, error) {
+//	  return strings.ReplaceAll(s, "-", ""), nil
+//	})
+//
+// The transformers `trimspace`, `lower` and `upper` are registered by default.
+func RegisterTransformer(name string, fn Transformer) {
+	transformersMu.Lock()
+	defer transformersMu.Unlock()
+	transformers[name] = fn
+}
+
+// lookupTransformer returns the transformer registered under name.
+func lookupTransformer(name string) (Transformer, error) {
+	transformersMu.RLock()
+	defer transformersMu.RUnlock()
+	fn, ok := transformers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown transformer %q", name)
+	}
+	return fn, nil
+}
+
+// transformValue applies the named transformers in order to fv. fv must
+// be a string or a pointer, slice or array of strings.
+// fv must be settable else this panics.
+func transformValue(fv reflect.Value, names []string) error {
+	return transformStrings(fv, func(s string) (string, error) {
+		for _, name := range names {
+			fn, err := lookupTransformer(name)
+			if err != nil {
+				return "", err
+			}
+			if s, err = fn(s); err != nil {
+				return "", fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		return s, nil
+	})
+}
+
+// transformStrings replaces every string contained in fv with the result
+// of fn. fv must be a string or a pointer, slice or array of strings.
+// fv must be settable else this panics.
+func transformStrings(fv reflect.Value, fn Transformer) error {
+	switch fv.Kind() {
+	case reflect.Ptr:
+		if fv.IsNil() {
+			return nil
+		}
+		return transformStrings(fv.Elem(), fn)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < fv.Len(); i++ {
+			if err := transformStrings(fv.Index(i), fn); err != nil {
+				return err
+			}
+		}
+	case reflect.String:
+		s, err := fn(fv.String())
+		if err != nil {
+			return err
+		}
+		fv.SetString(s)
+	default:
+		return fmt.Errorf("unsupported type %v", fv.Kind())
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package cfg
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_cfg_Load_Transform(t *testing.T) {
+	RegisterTransformer("nodashes", func(s string) (string, error) {
+		return strings.ReplaceAll(s, "-", ""), nil
+	})
+
+	type Config struct {
+		Level string   `transform:"trimspace,lower" validate:"required"`
+		Tags  []string `transform:"upper"`
+		ID    *string  `transform:"nodashes"`
+	}
+
+	os.Clearenv()
+	setenv(t, "APP_LEVEL", "  DEBUG ")
+	setenv(t, "APP_TAGS", "[a,b]")
+	setenv(t, "APP_ID", "ab-cd-ef")
+
+	var cfg Config
+	if err := Load(&cfg, IgnoreFile(), UseEnv("app")); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	id := "abcdef"
+	want := Config{Level: "debug", Tags: []string{"A", "B"}, ID: &id}
+	if !reflect.DeepEqual(want, cfg) {
+		t.Errorf("\nwant %+v\ngot %+v", want, cfg)
+	}
+
+	t.Run("transform runs before validation", func(t *testing.T) {
+		setenv(t, "APP_LEVEL", "   ")
+
+		var cfg Config
+		err := Load(&cfg, IgnoreFile(), UseEnv("app"))
+		if err == nil || !strings.Contains(err.Error(), "Level: required validation failed") {
+			t.Fatalf("want required validation err, got %v", err)
+		}
+	})
+}
+
+func Test_transformValue(t *testing.T) {
+	RegisterTransformer("fail", func(string) (string, error) {
+		return "", fmt.Errorf("boom")
+	})
+
+	t.Run("unknown transformer", func(t *testing.T) {
+		s := "x"
+		err := transformValue(reflect.ValueOf(&s).Elem(), []string{"nope"})
+		if err == nil || !strings.Contains(err.Error(), `unknown transformer "nope"`) {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+
+	t.Run("transformer error", func(t *testing.T) {
+		s := "x"
+		err := transformValue(reflect.ValueOf(&s).Elem(), []string{"fail"})
+		if err == nil || !strings.Contains(err.Error(), "fail: boom") {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		i := 5
+		err := transformValue(reflect.ValueOf(&i).Elem(), []string{"lower"})
+		if err == nil {
+			t.Fatal("expected err")
+		}
+	})
+
+	t.Run("nil pointer", func(t *testing.T) {
+		var s *string
+		if err := transformValue(reflect.ValueOf(&s).Elem(), []string{"lower"}); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+}
@@ -1,6 +1,8 @@
 package cfg
 
 import (
+	"encoding"
+	"fmt"
 	"os"
 	"reflect"
 	"strings"
@@ -10,13 +12,16 @@ import (
 // stringSlice converts a Go slice represented as a string
 // into an actual slice. The enclosing square brackets
 // are not necessary.
-// fields should be separated by a comma.
+// fields should be separated by sep, or by a comma if sep is empty.
 //
 //	"[1,2,3]"     --->   []string{"1", "2", "3"}
 //	" foo , bar"  --->   []string{" foo ", " bar"}
-func stringSlice(s string) []string {
+func stringSlice(s, sep string) []string {
+	if sep == "" {
+		sep = ","
+	}
 	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
-	return strings.Split(s, ",")
+	return strings.Split(s, sep)
 }
 
 // fileExists returns true if the file exists and is not a
@@ -35,10 +40,21 @@ func isStructPtr(i interface{}) bool {
 	return v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Struct
 }
 
-// isZero reports whether v is its zero value for its type.
+// isZero reports whether v is its zero value for its type. Non-nil
+// pointers are dereferenced, except for pointers to bools which are
+// never zero as they are the only way to tell an explicit false apart
+// from an unset field.
 func isZero(v reflect.Value) bool {
 	switch v.Kind() {
-	case reflect.Ptr, reflect.Interface:
+	case reflect.Ptr:
+		if v.IsNil() {
+			return true
+		}
+		if v.Elem().Kind() == reflect.Bool {
+			return false
+		}
+		return isZero(v.Elem())
+	case reflect.Interface:
 		return v.IsNil()
 	case reflect.Slice, reflect.Array:
 		return v.Len() == 0
@@ -46,6 +62,9 @@ func isZero(v reflect.Value) bool {
 		if t, ok := v.Interface().(time.Time); ok {
 			return t.IsZero()
 		}
+		if _, ok := textUnmarshaler(v); ok {
+			return v.IsZero()
+		}
 		return false
 	case reflect.Invalid:
 		return true
@@ -53,3 +72,55 @@ func isZero(v reflect.Value) bool {
 		return v.IsZero()
 	}
 }
+
+// textUnmarshaler returns v as an encoding.TextUnmarshaler if its address
+// implements the interface. time.Time is excluded as it is parsed using the
+// configured time layout.
+func textUnmarshaler(v reflect.Value) (encoding.TextUnmarshaler, bool) {
+	if !v.CanAddr() || v.Type() == reflect.TypeOf(time.Time{}) {
+		return nil, false
+	}
+	tu, ok := v.Addr().Interface().(encoding.TextUnmarshaler)
+	return tu, ok
+}
+
+// setPath sets val in the nested map m at the path formed by keys,
+// creating intermediate maps as needed. An error is returned if a key
+// along the path already holds a value that is not a map.
+func setPath(m map[string]interface{}, keys []string, val interface{}) error {
+	for i, key := range keys[:len(keys)-1] {
+		next, ok := m[key]
+		if !ok {
+			child := make(map[string]interface{})
+			m[key] = child
+			m = child
+			continue
+		}
+		child, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: cannot set nested key, value is not a map", strings.Join(keys[:i+1], "."))
+		}
+		m = child
+	}
+
+	last := keys[len(keys)-1]
+	if _, ok := m[last].(map[string]interface{}); ok {
+		return fmt.Errorf("%s: cannot overwrite nested keys with a value", strings.Join(keys, "."))
+	}
+	m[last] = val
+	return nil
+}
+
+// mergeMaps deep merges src into dst. Nested maps present in both are
+// merged recursively, any other value in src replaces the one in dst.
+func mergeMaps(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, srcOK := v.(map[string]interface{})
+		dstMap, dstOK := dst[k].(map[string]interface{})
+		if srcOK && dstOK {
+			mergeMaps(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+}
@@ -30,7 +30,7 @@ func Test_stringSlice(t *testing.T) {
 		},
 	} {
 		t.Run(tc.In, func(t *testing.T) {
-			got := stringSlice(tc.In)
+			got := stringSlice(tc.In, "")
 			if !reflect.DeepEqual(tc.Want, got) {
 				t.Fatalf("want %+v, got %+v", tc.Want, got)
 			}
@@ -91,6 +91,22 @@ func Test_isZero(t *testing.T) {
 		}
 	})
 
+	t.Run("pointer to zero value is zero", func(t *testing.T) {
+		d := time.Duration(0)
+
+		if isZero(reflect.ValueOf(&d)) == false {
+			t.Fatalf("isZero == false")
+		}
+	})
+
+	t.Run("pointer to false is not zero", func(t *testing.T) {
+		b := false
+
+		if isZero(reflect.ValueOf(&b)) == true {
+			t.Fatalf("isZero == true")
+		}
+	})
+
 	t.Run("struct is not zero", func(t *testing.T) {
 		a := struct {
 			B string
@@ -101,6 +117,22 @@ func Test_isZero(t *testing.T) {
 		}
 	})
 
+	t.Run("zero text unmarshaler struct is zero", func(t *testing.T) {
+		var tr TimeRange
+
+		if isZero(reflect.ValueOf(&tr).Elem()) == false {
+			t.Fatalf("isZero == false")
+		}
+	})
+
+	t.Run("non-zero text unmarshaler struct is not zero", func(t *testing.T) {
+		tr := TimeRange{Start: time.Hour}
+
+		if isZero(reflect.ValueOf(&tr).Elem()) == true {
+			t.Fatalf("isZero == true")
+		}
+	})
+
 	t.Run("zero time is zero", func(t *testing.T) {
 		td := time.Time{}
 
@@ -157,3 +189,32 @@ func Test_isZero(t *testing.T) {
 		}
 	})
 }
+
+func Test_setPath(t *testing.T) {
+	m := map[string]interface{}{"a": 1}
+
+	if err := setPath(m, []string{"b", "c", "d"}, "x"); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if err := setPath(m, []string{"b", "e"}, "y"); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"a": 1,
+		"b": map[string]interface{}{
+			"c": map[string]interface{}{"d": "x"},
+			"e": "y",
+		},
+	}
+	if !reflect.DeepEqual(want, m) {
+		t.Fatalf("want %+v, got %+v", want, m)
+	}
+
+	if err := setPath(m, []string{"a", "b"}, "z"); err == nil {
+		t.Error("expected err when nesting under a value")
+	}
+	if err := setPath(m, []string{"b"}, "z"); err == nil {
+		t.Error("expected err when overwriting a map")
+	}
+}
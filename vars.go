@@ -0,0 +1,138 @@
+package cfg
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"text/template"
+)
+
+// varRef matches a `${name}` reference to a variable.
+var varRef = regexp.MustCompile(`\$\{([A-Za-z0-9_.-]+)\}`)
+
+// runtimeVars returns the variables that describe the running process,
+// which are defined by the RuntimeVars option.
+func runtimeVars() map[string]string {
+	vars := map[string]string{
+		"runtime.pid":       strconv.Itoa(os.Getpid()),
+		"runtime.goVersion": runtime.Version(),
+		"runtime.os":        runtime.GOOS,
+		"runtime.arch":      runtime.GOARCH,
+	}
+	// the hostname is left undefined if it is unknown so that referencing
+	// it is an error.
+	if hostname, err := os.Hostname(); err == nil {
+		vars["runtime.hostname"] = hostname
+	}
+	return vars
+}
+
+// addRuntimeVars adds the runtime variables to the configured variables,
+// which take precedence.
+func (f *cfg) addRuntimeVars() {
+	vars := runtimeVars()
+	for name, val := range f.vars {
+		vars[name] = val
+	}
+	f.vars = vars
+}
+
+// renderTemplate executes the contents of r as a text/template with the
+// configured variables as its data. Referencing an undefined variable is
+// an error.
+func (f *cfg) renderTemplate(r io.Reader, file string) (io.Reader, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New(filepath.Base(file)).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, f.vars); err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
+
+// expandVars replaces each `${name}` reference in s with the value of the
+// named variable. s is returned as is if no variables are configured.
+func (f *cfg) expandVars(s string) (string, error) {
+	if f.vars == nil {
+		return s, nil
+	}
+
+	var err error
+	s = varRef.ReplaceAllStringFunc(s, func(ref string) string {
+		name := varRef.FindStringSubmatch(ref)[1]
+		val, ok := f.vars[name]
+		if !ok && err == nil {
+			err = fmt.Errorf("undefined variable %q", name)
+		}
+		return val
+	})
+	return s, err
+}
+
+// expandEnvRefs replaces each `$NAME` or `${NAME}` reference in s with the
+// value of the variable NAME, if configured, or else of the env var NAME,
+// which expands to nothing if it is unset. `$$` is a literal `$`.
+func (f *cfg) expandEnvRefs(s string) string {
+	return os.Expand(s, func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+		if val, ok := f.vars[name]; ok {
+			return val
+		}
+		return os.Getenv(name)
+	})
+}
+
+// expandMap expands the variable references in the string values of m,
+// and the env var references if enabled, descending into nested maps
+// and slices.
+func (f *cfg) expandMap(m map[string]interface{}) error {
+	if f.vars == nil && !f.expandEnv {
+		return nil
+	}
+	for k, v := range m {
+		expanded, err := f.expandAny(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", k, err)
+		}
+		m[k] = expanded
+	}
+	return nil
+}
+
+func (f *cfg) expandAny(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case string:
+		if f.expandEnv {
+			return f.expandEnvRefs(v), nil
+		}
+		return f.expandVars(v)
+	case map[string]interface{}:
+		return v, f.expandMap(v)
+	case []interface{}:
+		for i := range v {
+			expanded, err := f.expandAny(v[i])
+			if err != nil {
+				return nil, err
+			}
+			v[i] = expanded
+		}
+		return v, nil
+	default:
+		return v, nil
+	}
+}
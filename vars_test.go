@@ -0,0 +1,192 @@
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func Test_cfg_Load_Vars(t *testing.T) {
+	type Config struct {
+		Bucket   string   `cfg:"bucket"`
+		Endpoint string   `cfg:"endpoint"`
+		Port     int      `cfg:"port"`
+		Zones    []string `cfg:"zones"`
+		Replicas int      `cfg:"replicas"`
+		Owner    string   `cfg:"owner" default:"team-${region}"`
+		Token    string   `cfg:"token"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), `
+bucket: logs-{{ .region }}
+endpoint: https://${region}.example.com
+port: ${port}
+zones:
+  - ${region}a
+  - ${region}b
+{{ if eq .region "eu-1" }}replicas: 3{{ end }}
+`)
+	vars := map[string]string{"region": "eu-1", "port": "8080"}
+
+	setenv(t, "APP_TOKEN", "secret-${region}")
+
+	var cfg Config
+	if err := Load(&cfg, Dirs(dir), Vars(vars), UseEnv("app")); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := Config{
+		Bucket:   "logs-eu-1",
+		Endpoint: "https://eu-1.example.com",
+		Port:     8080,
+		Zones:    []string{"eu-1a", "eu-1b"},
+		Replicas: 3,
+		Owner:    "team-eu-1",
+		Token:    "secret-eu-1",
+	}
+	if !reflect.DeepEqual(want, cfg) {
+		t.Errorf("\nwant %+v\ngot  %+v", want, cfg)
+	}
+}
+
+func Test_cfg_Load_Vars_Undefined(t *testing.T) {
+	type Config struct {
+		Host string `cfg:"host"`
+	}
+
+	for _, data := range []string{"host: {{ .missing }}\n", "host: ${missing}\n"} {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "config.yaml"), data)
+
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir), Vars(map[string]string{})); err == nil {
+			t.Errorf("expected err for %q", data)
+		}
+	}
+}
+
+func Test_cfg_expandVars(t *testing.T) {
+	t.Run("without vars", func(t *testing.T) {
+		conf := defaultCfg()
+		s, err := conf.expandVars("${region}")
+		if err != nil || s != "${region}" {
+			t.Errorf("want ${region} unchanged, got %q (err %v)", s, err)
+		}
+	})
+
+	t.Run("with vars", func(t *testing.T) {
+		conf := defaultCfg()
+		conf.vars = map[string]string{"a": "1", "b.c": "2"}
+		s, err := conf.expandVars("${a}-${b.c}-$a")
+		if err != nil || s != "1-2-$a" {
+			t.Errorf("want 1-2-$a, got %q (err %v)", s, err)
+		}
+	})
+}
+
+func Test_cfg_Load_ExpandEnv(t *testing.T) {
+	type Config struct {
+		Path   string   `cfg:"path"`
+		URL    string   `cfg:"url"`
+		Price  string   `cfg:"price"`
+		Port   int      `cfg:"port"`
+		Hosts  []string `cfg:"hosts"`
+		Region string   `cfg:"region"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), `
+path: ${DATA_HOME}/data
+url: $BASE_URL/api
+price: $$5 and $UNSET_VAR
+port: ${PORT}
+hosts:
+  - ${HOST_A}
+region: ${region}
+`)
+	setenv(t, "DATA_HOME", "/srv")
+	setenv(t, "BASE_URL", "https://example.com")
+	setenv(t, "PORT", "8080")
+	setenv(t, "HOST_A", "a.example.com")
+
+	var cfg Config
+	if err := Load(&cfg, Dirs(dir), ExpandEnv(), Vars(map[string]string{"region": "eu-1"})); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := Config{
+		Path:   "/srv/data",
+		URL:    "https://example.com/api",
+		Price:  "$5 and ",
+		Port:   8080,
+		Hosts:  []string{"a.example.com"},
+		Region: "eu-1",
+	}
+	if !reflect.DeepEqual(want, cfg) {
+		t.Errorf("\nwant %+v\ngot  %+v", want, cfg)
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "config.yaml"), "url: $BASE_URL/api\n")
+
+		var cfg Config
+		if err := Load(&cfg, Dirs(dir)); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.URL != "$BASE_URL/api" {
+			t.Errorf("want url unexpanded, got %q", cfg.URL)
+		}
+	})
+}
+
+func Test_cfg_Load_RuntimeVars(t *testing.T) {
+	type Config struct {
+		Instance string `cfg:"instance" default:"${runtime.hostname}-${runtime.pid}"`
+		Go       string `cfg:"go"`
+		Region   string `cfg:"region"`
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Skipf("hostname unknown: %v", err)
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), `
+go: ${runtime.goVersion}
+region: {{ .region }}
+`)
+
+	var cfg Config
+	if err := Load(&cfg, Dirs(dir), Vars(map[string]string{"region": "eu-1"}), RuntimeVars()); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := Config{
+		Instance: hostname + "-" + strconv.Itoa(os.Getpid()),
+		Go:       runtime.Version(),
+		Region:   "eu-1",
+	}
+	if !reflect.DeepEqual(want, cfg) {
+		t.Errorf("\nwant %+v\ngot  %+v", want, cfg)
+	}
+
+	t.Run("vars take precedence", func(t *testing.T) {
+		var cfg Config
+		vars := map[string]string{"region": "eu-1", "runtime.hostname": "web"}
+		if err := Load(&cfg, Dirs(dir), RuntimeVars(), Vars(vars)); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if want := "web-" + strconv.Itoa(os.Getpid()); cfg.Instance != want {
+			t.Errorf("want instance %q, got %q", want, cfg.Instance)
+		}
+		if len(vars) != 2 {
+			t.Errorf("vars of the option were modified: %v", vars)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package cfg
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+const (
+	waitInitialBackoff = 100 * time.Millisecond
+	waitMaxBackoff     = 5 * time.Second
+)
+
+// WaitForValid calls `Load` until it succeeds, retrying with an increasing
+// backoff, and blocks until the config is loaded, ctx is done or timeout
+// elapses. A timeout of 0 means that only ctx limits the wait.
+//
+// This is useful at startup when the config is provided by another process
+// that may not have finished writing it yet:
+//
+//	err := cfg.WaitForValid(ctx, time.Minute, &conf, cfg.Dirs("/etc/myapp"))
+//
+// Every attempt starts from the value that cfg pointed to when WaitForValid
+// was called. If no attempt succeeds the error of the last attempt is
+// returned.
+func WaitForValid(ctx context.Context, timeout time.Duration, cfg interface{}, options ...Option) error {
+	if !isStructPtr(cfg) {
+		return fmt.Errorf("cfg must be a pointer to a struct")
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	v := reflect.ValueOf(cfg).Elem()
+	initial := reflect.New(v.Type()).Elem()
+	initial.Set(v)
+
+	backoff := waitInitialBackoff
+	for {
+		err := Load(cfg, options...)
+		if err == nil {
+			return nil
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("no valid config before %v: %w", ctx.Err(), err)
+		case <-timer.C:
+		}
+
+		v.Set(initial)
+		if backoff *= 2; backoff > waitMaxBackoff {
+			backoff = waitMaxBackoff
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package cfg
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWaitForValid(t *testing.T) {
+	type Config struct {
+		Host string `cfg:"host" validate:"required"`
+		Port int    `cfg:"port" default:"80"`
+	}
+
+	t.Run("waits for config", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "config.yaml"), "port: 8080\n")
+
+		go func() {
+			time.Sleep(150 * time.Millisecond)
+			// write atomically so that no attempt observes a partial file.
+			tmp := filepath.Join(t.TempDir(), "config.yaml")
+			writeFile(t, tmp, "host: localhost\n")
+			if err := os.Rename(tmp, filepath.Join(dir, "config.yaml")); err != nil {
+				t.Error(err)
+			}
+		}()
+
+		var cfg Config
+		err := WaitForValid(context.Background(), 5*time.Second, &cfg, Dirs(dir))
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if cfg.Host != "localhost" || cfg.Port != 80 {
+			t.Errorf("unexpected cfg: %+v", cfg)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		var cfg Config
+		err := WaitForValid(context.Background(), 50*time.Millisecond, &cfg, Dirs(t.TempDir()))
+		if !errors.Is(err, ErrFileNotFound) {
+			t.Fatalf("want ErrFileNotFound, got %v", err)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var cfg Config
+		err := WaitForValid(ctx, 0, &cfg, Dirs(t.TempDir()))
+		if err == nil {
+			t.Fatal("expected err")
+		}
+	})
+
+	t.Run("non struct pointer", func(t *testing.T) {
+		var i int
+		if err := WaitForValid(context.Background(), 0, &i); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+}
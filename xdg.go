@@ -0,0 +1,37 @@
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// xdgDirs returns the config dirs of app according to the XDG base
+// directory spec, in order of increasing precedence as files of later dirs
+// override those of earlier ones: the dirs of `XDG_CONFIG_DIRS` (by
+// default `/etc/xdg`) from the least important, then `XDG_CONFIG_HOME` (by
+// default `~/.config`). Relative dirs are ignored as required by the spec.
+func xdgDirs(app string) []string {
+	system := os.Getenv("XDG_CONFIG_DIRS")
+	if system == "" {
+		system = "/etc/xdg"
+	}
+	parts := filepath.SplitList(system)
+
+	var dirs []string
+	for i := len(parts) - 1; i >= 0; i-- {
+		if filepath.IsAbs(parts[i]) {
+			dirs = append(dirs, filepath.Join(parts[i], app))
+		}
+	}
+
+	home := os.Getenv("XDG_CONFIG_HOME")
+	if home == "" {
+		if userHome, err := os.UserHomeDir(); err == nil {
+			home = filepath.Join(userHome, ".config")
+		}
+	}
+	if filepath.IsAbs(home) {
+		dirs = append(dirs, filepath.Join(home, app))
+	}
+	return dirs
+}
@@ -0,0 +1,60 @@
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_xdgDirs(t *testing.T) {
+	t.Run("env", func(t *testing.T) {
+		setenv(t, "XDG_CONFIG_HOME", "/home/user/conf")
+		setenv(t, "XDG_CONFIG_DIRS", "/etc/xdg"+string(os.PathListSeparator)+"relative"+string(os.PathListSeparator)+"/opt/xdg")
+
+		want := []string{"/opt/xdg/myapp", "/etc/xdg/myapp", "/home/user/conf/myapp"}
+		if got := xdgDirs("myapp"); !reflect.DeepEqual(want, got) {
+			t.Errorf("want %v, got %v", want, got)
+		}
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		setenv(t, "XDG_CONFIG_HOME", "")
+		setenv(t, "XDG_CONFIG_DIRS", "")
+		setenv(t, "HOME", "/home/user")
+
+		want := []string{"/etc/xdg/myapp", "/home/user/.config/myapp"}
+		if got := xdgDirs("myapp"); !reflect.DeepEqual(want, got) {
+			t.Errorf("want %v, got %v", want, got)
+		}
+	})
+}
+
+func Test_cfg_Load_UseXDG(t *testing.T) {
+	type Config struct {
+		Host  string `cfg:"host"`
+		Port  int    `cfg:"port"`
+		Debug bool   `cfg:"debug"`
+	}
+
+	home, system, local := t.TempDir(), t.TempDir(), t.TempDir()
+	setenv(t, "XDG_CONFIG_HOME", home)
+	setenv(t, "XDG_CONFIG_DIRS", system)
+	for _, dir := range []string{home, system} {
+		if err := os.Mkdir(filepath.Join(dir, "myapp"), 0o700); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	}
+	writeFile(t, filepath.Join(system, "myapp", "config.yaml"), "host: system\nport: 80\ndebug: true\n")
+	writeFile(t, filepath.Join(home, "myapp", "config.yaml"), "host: user\nport: 8080\n")
+	writeFile(t, filepath.Join(local, "config.yaml"), "port: 9090\n")
+
+	var cfg Config
+	if err := Load(&cfg, UseXDG("myapp"), Dirs(local)); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	want := Config{Host: "user", Port: 9090, Debug: true}
+	if cfg != want {
+		t.Errorf("want %+v, got %+v", want, cfg)
+	}
+}
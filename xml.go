@@ -0,0 +1,102 @@
+package cfg
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// xmlTextKey is the key under which the text of an element that also has
+// attributes or child elements is stored.
+const xmlTextKey = "#text"
+
+// decodeXML decodes an XML document into vals. The children and attributes
+// of the root element become the top-level keys of vals. Elements that only
+// contain text decode to strings, other elements decode to maps of their
+// attributes and children, and repeated elements decode to slices.
+func decodeXML(r io.Reader, vals map[string]interface{}) error {
+	dec := xml.NewDecoder(r)
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return fmt.Errorf("no root element")
+		}
+		if err != nil {
+			return err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		root, err := decodeXMLElement(dec, start)
+		if err != nil {
+			return err
+		}
+		m, ok := root.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("root element <%s> must contain elements or attributes", start.Name.Local)
+		}
+		for k, v := range m {
+			vals[k] = v
+		}
+		return nil
+	}
+}
+
+// decodeXMLElement decodes the element that starts with start, consuming
+// tokens up to and including its end element.
+func decodeXMLElement(dec *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	m := make(map[string]interface{})
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		addXMLValue(m, attr.Name.Local, attr.Value)
+	}
+
+	var text strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			child, err := decodeXMLElement(dec, t)
+			if err != nil {
+				return nil, err
+			}
+			addXMLValue(m, t.Name.Local, child)
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			s := strings.TrimSpace(text.String())
+			if len(m) == 0 {
+				return s, nil
+			}
+			if s != "" {
+				m[xmlTextKey] = s
+			}
+			return m, nil
+		}
+	}
+}
+
+// addXMLValue adds val under key in m, turning the value into a slice if
+// key is already present.
+func addXMLValue(m map[string]interface{}, key string, val interface{}) {
+	existing, ok := m[key]
+	if !ok {
+		m[key] = val
+		return
+	}
+	if list, ok := existing.([]interface{}); ok {
+		m[key] = append(list, val)
+		return
+	}
+	m[key] = []interface{}{existing, val}
+}
@@ -0,0 +1,44 @@
+package cfg
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_decodeXML(t *testing.T) {
+	in := `<?xml version="1.0"?>
+<!-- app config -->
+<config xmlns="urn:app" xmlns:x="urn:x" version="2">
+  <name><![CDATA[my <app>]]></name>
+  <tags>a</tags>
+  <tags>b</tags>
+  <tags>c</tags>
+  <server port="80">localhost</server>
+  <empty/>
+</config>`
+
+	vals := make(map[string]interface{})
+	if err := decodeXML(strings.NewReader(in), vals); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"version": "2",
+		"name":    "my <app>",
+		"tags":    []interface{}{"a", "b", "c"},
+		"server":  map[string]interface{}{"port": "80", "#text": "localhost"},
+		"empty":   "",
+	}
+	if !reflect.DeepEqual(want, vals) {
+		t.Errorf("\nwant %+v\ngot %+v", want, vals)
+	}
+
+	for _, in := range []string{"", "<!-- nothing -->", "<config>text only</config>", "<config><a></config>"} {
+		t.Run(in, func(t *testing.T) {
+			if err := decodeXML(strings.NewReader(in), make(map[string]interface{})); err == nil {
+				t.Fatal("expected err")
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package cfg
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+// marshalYAMLOver encodes v as YAML over the YAML document old, keeping
+// the comments and the order of the keys of old wherever v has the same
+// keys, so that rewriting a file does not lose the annotations of its
+// operators. Keys of old that are not in v are removed and those of v that
+// are not in old are appended. v is encoded as is if old is not a YAML
+// document.
+func marshalYAMLOver(old []byte, v interface{}) ([]byte, error) {
+	var next yaml.Node
+	if err := next.Encode(v); err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(old, &doc); err != nil || doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return yaml.Marshal(&next)
+	}
+	doc.Content[0] = mergeYAMLNode(doc.Content[0], &next)
+	return yaml.Marshal(&doc)
+}
+
+// mergeYAMLNode returns next with the comments of prev, and the quoting of
+// prev if both are scalars of the same type, merging the entries of
+// mappings and the elements of sequences recursively.
+func mergeYAMLNode(prev, next *yaml.Node) *yaml.Node {
+	switch {
+	case prev.Kind == yaml.MappingNode && next.Kind == yaml.MappingNode:
+		nextVals := make(map[string]*yaml.Node, len(next.Content)/2)
+		var order []string
+		for i := 0; i+1 < len(next.Content); i += 2 {
+			key := next.Content[i].Value
+			nextVals[key] = next.Content[i+1]
+			order = append(order, key)
+		}
+
+		merged := *prev
+		merged.Content = nil
+		seen := make(map[string]bool, len(nextVals))
+		for i := 0; i+1 < len(prev.Content); i += 2 {
+			key := prev.Content[i].Value
+			val, ok := nextVals[key]
+			if !ok || seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged.Content = append(merged.Content, prev.Content[i], mergeYAMLNode(prev.Content[i+1], val))
+		}
+		for i, key := range order {
+			if !seen[key] {
+				merged.Content = append(merged.Content, next.Content[2*i], nextVals[key])
+			}
+		}
+		return &merged
+
+	case prev.Kind == yaml.SequenceNode && next.Kind == yaml.SequenceNode:
+		merged := *prev
+		merged.Content = make([]*yaml.Node, len(next.Content))
+		for i, elem := range next.Content {
+			if i < len(prev.Content) {
+				elem = mergeYAMLNode(prev.Content[i], elem)
+			}
+			merged.Content[i] = elem
+		}
+		return &merged
+
+	default:
+		merged := *next
+		if prev.Kind == yaml.ScalarNode && next.Kind == yaml.ScalarNode && prev.Tag == next.Tag {
+			merged.Style = prev.Style
+		}
+		merged.HeadComment = prev.HeadComment
+		merged.LineComment = prev.LineComment
+		merged.FootComment = prev.FootComment
+		return &merged
+	}
+}
@@ -0,0 +1,56 @@
+package cfg
+
+import (
+	"testing"
+)
+
+func Test_marshalYAMLOver(t *testing.T) {
+	old := `# overrides of the ops team
+server:
+  # the public port
+  port: 8080 # see ticket OPS-1
+  host: "old"
+timeout: 1m
+replicas:
+  - host: a # primary
+  - host: b
+stale: true
+`
+	vals := map[string]interface{}{
+		"timeout": "2m",
+		"server":  map[string]interface{}{"host": "new", "port": 9090, "tls": true},
+		"replicas": []interface{}{
+			map[string]interface{}{"host": "a"},
+		},
+		"level": "debug",
+	}
+
+	got, err := marshalYAMLOver([]byte(old), vals)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	want := `# overrides of the ops team
+server:
+    # the public port
+    port: 9090 # see ticket OPS-1
+    host: "new"
+    tls: true
+timeout: 2m
+replicas:
+    - host: a # primary
+level: debug
+`
+	if string(got) != want {
+		t.Errorf("want\n%s\ngot\n%s", want, got)
+	}
+
+	t.Run("not a yaml document", func(t *testing.T) {
+		got, err := marshalYAMLOver([]byte("{not: [yaml"), map[string]interface{}{"a": 1})
+		if err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		if string(got) != "a: 1\n" {
+			t.Errorf("want a: 1, got %s", got)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package cfg
+
+import (
+	"fmt"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLTagHandler resolves the value of a YAML scalar annotated with a
+// custom tag, e.g. the path of `!vault secret/db`, into the value to
+// decode in its place.
+type YAMLTagHandler func(value string) (interface{}, error)
+
+var (
+	yamlTagsMu sync.RWMutex
+	yamlTags   = map[string]YAMLTagHandler{}
+)
+
+// RegisterYAMLTag registers the handler of the custom YAML tag, e.g.
+// `!vault`. Registering a tag that is already registered replaces the
+// previous handler.
+//
+//	cfg.RegisterYAMLTag("!vault", func(path string) (interface{}, error) {
+//	  return vault.Read(path)
+//	})
+//
+// Handlers may return any value a YAML node could be decoded into, such
+// as a string or a map for a whole section. Standard tags such as
+// `!!binary` are handled by the decoder: `!!binary` values can be decoded
+// into `[]byte` fields.
+func RegisterYAMLTag(tag string, fn YAMLTagHandler) {
+	yamlTagsMu.Lock()
+	defer yamlTagsMu.Unlock()
+	yamlTags[tag] = fn
+}
+
+// lookupYAMLTag returns the handler registered for tag, if any.
+func lookupYAMLTag(tag string) (YAMLTagHandler, bool) {
+	yamlTagsMu.RLock()
+	defer yamlTagsMu.RUnlock()
+	fn, ok := yamlTags[tag]
+	return fn, ok
+}
+
+// resolveYAMLTags replaces the nodes of the tree rooted at n that have a
+// registered custom tag with the values returned by their handlers.
+// Aliases are not followed, their anchors are resolved in place.
+func resolveYAMLTags(n *yaml.Node) error {
+	if n.Kind == yaml.AliasNode {
+		return nil
+	}
+
+	if fn, ok := lookupYAMLTag(n.Tag); ok {
+		if n.Kind != yaml.ScalarNode {
+			return fmt.Errorf("line %d: tag %s must be applied to a scalar", n.Line, n.Tag)
+		}
+		val, err := fn(n.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %s: %w", n.Line, n.Tag, err)
+		}
+
+		var resolved yaml.Node
+		if err := resolved.Encode(val); err != nil {
+			return fmt.Errorf("line %d: %s: %w", n.Line, n.Tag, err)
+		}
+		resolved.Anchor, resolved.Line, resolved.Column = n.Anchor, n.Line, n.Column
+		*n = resolved
+		return nil
+	}
+
+	for _, child := range n.Content {
+		if err := resolveYAMLTags(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package cfg
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func registerTestYAMLTag(t *testing.T, tag string, fn YAMLTagHandler) {
+	t.Helper()
+	RegisterYAMLTag(tag, fn)
+	t.Cleanup(func() {
+		yamlTagsMu.Lock()
+		defer yamlTagsMu.Unlock()
+		delete(yamlTags, tag)
+	})
+}
+
+func Test_cfg_Load_YAMLTags(t *testing.T) {
+	secrets := map[string]interface{}{
+		"secret/password": "hunter2",
+		"secret/db":       map[string]interface{}{"user": "admin", "port": 5432},
+	}
+	registerTestYAMLTag(t, "!vault", func(path string) (interface{}, error) {
+		v, ok := secrets[path]
+		if !ok {
+			return nil, errors.New("not found")
+		}
+		return v, nil
+	})
+
+	type Config struct {
+		Key      []byte `cfg:"key"`
+		Password string `cfg:"password"`
+		Copy     string `cfg:"copy"`
+		DB       struct {
+			User string `cfg:"user"`
+			Port int    `cfg:"port"`
+		} `cfg:"db"`
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), `
+key: !!binary /wD+aGk=
+password: &pw !vault secret/password
+copy: *pw
+db: !vault secret/db
+`)
+
+	var cfg Config
+	if err := Load(&cfg, Dirs(dir), UseStrict()); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	var want Config
+	want.Key = []byte{0xff, 0x00, 0xfe, 'h', 'i'}
+	want.Password = "hunter2"
+	want.Copy = "hunter2"
+	want.DB.User = "admin"
+	want.DB.Port = 5432
+	if !reflect.DeepEqual(want, cfg) {
+		t.Errorf("\nwant %+v\ngot  %+v", want, cfg)
+	}
+
+	t.Run("handler error", func(t *testing.T) {
+		vals := make(map[string]interface{})
+		err := decodeYAML(strings.NewReader("a: !vault secret/missing\n"), vals)
+		if err == nil || err.Error() != "line 1: !vault: not found" {
+			t.Fatalf("unexpected err: %v", err)
+		}
+	})
+
+	t.Run("non scalar", func(t *testing.T) {
+		vals := make(map[string]interface{})
+		if err := decodeYAML(strings.NewReader("a: !vault {k: v}\n"), vals); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+}